@@ -2,6 +2,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -87,6 +88,280 @@ type ElasticsearchSpec struct {
 	// +nullable
 	// +optional
 	IndexManagement *IndexManagementSpec `json:"indexManagement"`
+
+	// The strategy used to roll out changes to the Elasticsearch nodes
+	//
+	// +nullable
+	// +optional
+	RolloutStrategy *ElasticsearchRolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// How the certificates securing the cluster are provided
+	//
+	// +nullable
+	// +optional
+	Certificates *ElasticsearchCertificatesSpec `json:"certificates,omitempty"`
+
+	// The network policy restricting ingress traffic to the Elasticsearch nodes
+	//
+	// +nullable
+	// +optional
+	NetworkPolicy *ElasticsearchNetworkPolicySpec `json:"networkPolicy,omitempty"`
+
+	// Configuration of the proxy in front of the Elasticsearch nodes
+	//
+	// +nullable
+	// +optional
+	Proxy *ElasticsearchProxySpec `json:"proxy,omitempty"`
+
+	// Audit logging of the security plugin
+	//
+	// +nullable
+	// +optional
+	AuditLog *ElasticsearchAuditLogSpec `json:"auditLog,omitempty"`
+
+	// Expose the proxy in front of the Elasticsearch nodes outside of the cluster,
+	// the cluster is only reachable from within the cluster when unset
+	//
+	// +nullable
+	// +optional
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
+
+	// Storage settings shared by all nodes
+	//
+	// +nullable
+	// +optional
+	Storage *ElasticsearchClusterStorageSpec `json:"storage,omitempty"`
+
+	// Take crash-consistent snapshots of the claims of the data nodes with the CSI
+	// snapshot API of the platform
+	//
+	// +nullable
+	// +optional
+	VolumeSnapshots *ElasticsearchVolumeSnapshotPolicy `json:"volumeSnapshots,omitempty"`
+
+	// Other clusters the nodes connect to for searching across clusters
+	//
+	// +optional
+	RemoteClusters []ElasticsearchRemoteCluster `json:"remoteClusters,omitempty"`
+}
+
+// ElasticsearchRemoteCluster is a cluster whose indices are searched as <name>:<index>
+type ElasticsearchRemoteCluster struct {
+	// The alias of the remote cluster in cross cluster requests
+	//
+	// +kubebuilder:validation:Pattern:="^[a-z0-9]([-a-z0-9_]*[a-z0-9])?$"
+	Name string `json:"name"`
+
+	// Another Elasticsearch cluster managed by the operator, its transport service is used
+	// as seed and its certificate authority is trusted
+	//
+	// +nullable
+	// +optional
+	ElasticsearchRef *ElasticsearchRemoteClusterReference `json:"elasticsearchRef,omitempty"`
+
+	// The transport endpoints, host:port, of a cluster not managed by the operator
+	//
+	// +optional
+	Seeds []string `json:"seeds,omitempty"`
+
+	// The secret in the namespace of the cluster whose ca.crt key holds the certificate
+	// authority of the transport certificates of the seeds
+	//
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+
+	// Leave the remote cluster out of cross cluster searches while it is unavailable
+	// instead of failing them
+	//
+	// +optional
+	SkipUnavailable bool `json:"skipUnavailable,omitempty"`
+}
+
+// ElasticsearchRemoteClusterReference names an Elasticsearch cluster
+type ElasticsearchRemoteClusterReference struct {
+	// The name of the Elasticsearch cluster
+	Name string `json:"name"`
+
+	// The namespace of the Elasticsearch cluster, the namespace of the referencing
+	// cluster is used when unset
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ElasticsearchVolumeSnapshotPolicy defines how often the claims of the data nodes are snapshotted
+type ElasticsearchVolumeSnapshotPolicy struct {
+	// How often a set of snapshots is taken, e.g. 24h
+	Interval metav1.Duration `json:"interval"`
+
+	// The VolumeSnapshotClass of the snapshots, the default class of the CSI driver
+	// is used when unset
+	//
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// The number of completed snapshot sets kept, the snapshots of older sets are
+	// deleted. Every set is kept when unset.
+	//
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	Keep *int32 `json:"keep,omitempty"`
+}
+
+// ElasticsearchClusterStorageSpec defines what happens to the persistent volume claims of the cluster
+type ElasticsearchClusterStorageSpec struct {
+	// What happens to the claims of node groups removed from the spec and of the deleted
+	// cluster, the claims are retained by default
+	//
+	// +nullable
+	// +optional
+	RetentionPolicy *ElasticsearchStorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// StorageRetentionPolicyType is what happens to claims no node runs on anymore
+//
+// +kubebuilder:validation:Enum:=Retain;Delete;DeleteAfter
+type StorageRetentionPolicyType string
+
+const (
+	// StorageRetain keeps the claims until they are deleted by hand
+	StorageRetain StorageRetentionPolicyType = "Retain"
+	// StorageDelete deletes the claims of removed node groups and lets the claims be
+	// garbage collected with the cluster
+	StorageDelete StorageRetentionPolicyType = "Delete"
+	// StorageDeleteAfter deletes the claims once they were orphaned for the given duration
+	StorageDeleteAfter StorageRetentionPolicyType = "DeleteAfter"
+)
+
+// ElasticsearchStorageRetentionPolicy defines how long claims no node runs on are kept
+type ElasticsearchStorageRetentionPolicy struct {
+	Type StorageRetentionPolicyType `json:"type"`
+
+	// How long claims are kept after they were orphaned, e.g. 168h. Required by the
+	// DeleteAfter policy.
+	//
+	// +optional
+	After *metav1.Duration `json:"after,omitempty"`
+}
+
+// AuditLogTarget is where the audit events are written to
+//
+// +kubebuilder:validation:Enum:=Index;Log4j
+type AuditLogTarget string
+
+const (
+	// AuditLogTargetIndex writes the audit events to an index of the cluster
+	AuditLogTargetIndex AuditLogTarget = "Index"
+	// AuditLogTargetLog4j writes the audit events to the log of the nodes
+	AuditLogTargetLog4j AuditLogTarget = "Log4j"
+)
+
+// AuditCategory is a category of the events recorded by the audit log
+//
+// +kubebuilder:validation:Enum:=AUTHENTICATED;BAD_HEADERS;FAILED_LOGIN;GRANTED_PRIVILEGES;MISSING_PRIVILEGES;OPENDISTRO_SECURITY_INDEX_ATTEMPT;SSL_EXCEPTION
+type AuditCategory string
+
+// ElasticsearchAuditLogSpec defines which requests are recorded by the audit log of the security plugin
+type ElasticsearchAuditLogSpec struct {
+	// Enable the audit log
+	//
+	// +optional
+	Enabled bool `json:"enabled"`
+
+	// Where the audit events are written to, defaults to Index. The audit index is managed
+	// like the other index management mappings.
+	//
+	// +optional
+	Target AuditLogTarget `json:"target,omitempty"`
+
+	// Categories to record, all categories not excluded are recorded when empty
+	//
+	// +optional
+	IncludeCategories []AuditCategory `json:"includeCategories,omitempty"`
+
+	// Categories not to record. Defaults to AUTHENTICATED and GRANTED_PRIVILEGES when
+	// no categories are included or excluded.
+	//
+	// +optional
+	ExcludeCategories []AuditCategory `json:"excludeCategories,omitempty"`
+
+	// Users whose requests are not recorded
+	//
+	// +optional
+	IgnoreUsers []string `json:"ignoreUsers,omitempty"`
+
+	// Index management policy applied to the audit index, e.g. to delete old audit events.
+	// Only used with the Index target.
+	//
+	// +optional
+	PolicyRef string `json:"policyRef,omitempty"`
+}
+
+// ElasticsearchProxySpec defines the configuration of the proxy in front of the Elasticsearch nodes
+type ElasticsearchProxySpec struct {
+	// Additional backend roles granted by the proxy, merged with the roles defined by the operator
+	//
+	// +optional
+	AuthRoles []ElasticsearchProxyAuthRole `json:"authRoles,omitempty"`
+}
+
+// ElasticsearchProxyAuthRole grants a backend role to the users allowed to perform the
+// described SubjectAccessReview
+type ElasticsearchProxyAuthRole struct {
+	// Name of the backend role
+	//
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Name string `json:"name"`
+	// Namespace of the SubjectAccessReview, "*" for all namespaces and empty for cluster scoped resources
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Verb of the SubjectAccessReview
+	//
+	// +kubebuilder:validation:MinLength=1
+	Verb string `json:"verb"`
+	// Resource of the SubjectAccessReview, either a resource or a non resource URL starting with "/"
+	//
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+	// API group of the resource
+	//
+	// +optional
+	ResourceAPIGroup string `json:"resourceAPIGroup,omitempty"`
+}
+
+// ElasticsearchNetworkPolicySpec defines which clients may connect to the Elasticsearch nodes
+type ElasticsearchNetworkPolicySpec struct {
+	// Enabled restricts ingress traffic to the nodes to the operator, the other nodes
+	// of the cluster, the monitoring stack and the peers listed below
+	//
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Clients allowed to connect to the REST API and the metrics of the cluster
+	// through the proxy, e.g. collectors, Kibana, Jaeger or Prometheus
+	//
+	// +optional
+	Peers []networkingv1.NetworkPolicyPeer `json:"peers,omitempty"`
+}
+
+// ElasticsearchCertificatesSpec defines how the certificates of the cluster are provided
+type ElasticsearchCertificatesSpec struct {
+	// Generate makes the operator create and store the certificate authority and the
+	// certificates of the cluster instead of expecting them in a secret named after the cluster
+	//
+	// +optional
+	Generate bool `json:"generate,omitempty"`
+}
+
+// ElasticsearchRolloutStrategy defines how changes are rolled out to the Elasticsearch nodes
+type ElasticsearchRolloutStrategy struct {
+	// Surge brings up a temporary replacement data node and relocates the shards of
+	// each data node onto the rest of the cluster before the node is restarted, so that
+	// clusters with a single replica stay green during upgrades
+	//
+	// +optional
+	Surge bool `json:"surge,omitempty"`
 }
 
 // ElasticsearchStatus defines the observed state of Elasticsearch
@@ -106,6 +381,292 @@ type ElasticsearchStatus struct {
 	Conditions ClusterConditions `json:"conditions,omitempty"`
 	// +optional
 	IndexManagementStatus *IndexManagementStatus `json:"indexManagement,omitempty"`
+	// The certificates found in the secret of the cluster
+	//
+	// +optional
+	Certificates []ElasticsearchCertificateStatus `json:"certificates,omitempty"`
+	// The way the nodes are restarted to pick up changes to the secret of the cluster
+	//
+	// +optional
+	CertificateRedeploy *ElasticsearchCertRedeployStatus `json:"certificateRedeploy,omitempty"`
+	// The claims of the cluster no node runs on
+	//
+	// +optional
+	OrphanedClaims []ElasticsearchOrphanedClaim `json:"orphanedClaims,omitempty"`
+	// The sets of volume snapshots taken of the data nodes, oldest first
+	//
+	// +optional
+	VolumeSnapshots []ElasticsearchVolumeSnapshotSet `json:"volumeSnapshots,omitempty"`
+	// The node groups moving onto claims of the storage class of the spec
+	//
+	// +optional
+	StorageMigrations []ElasticsearchStorageMigration `json:"storageMigrations,omitempty"`
+	// The connections to the remote clusters as reported by the nodes
+	//
+	// +optional
+	RemoteClusters []ElasticsearchRemoteClusterStatus `json:"remoteClusters,omitempty"`
+	// The migrations of the data and settings of the cluster in the order they run
+	//
+	// +optional
+	Migrations []ElasticsearchMigrationStatus `json:"migrations,omitempty"`
+	// The upgrade of the nodes to the version of Elasticsearch the operator packages
+	//
+	// +optional
+	Upgrade *ElasticsearchUpgradeStatus `json:"upgrade,omitempty"`
+}
+
+// UpgradeStrategy is the way the nodes are restarted onto a new version of Elasticsearch
+type UpgradeStrategy string
+
+const (
+	// UpgradeRollingRestart restarts one node at a time while the cluster stays available
+	UpgradeRollingRestart UpgradeStrategy = "RollingUpgrade"
+	// UpgradeFullClusterRestart stops every node before any node starts on the new version
+	UpgradeFullClusterRestart UpgradeStrategy = "FullClusterRestart"
+)
+
+// UpgradePhase is the progress of an upgrade
+type UpgradePhase string
+
+const (
+	// UpgradeBlocked is an upgrade held back by a critical issue, the nodes keep their version
+	UpgradeBlocked UpgradePhase = "Blocked"
+	// UpgradeInProgress is an upgrade restarting the nodes
+	UpgradeInProgress UpgradePhase = "Upgrading"
+	// UpgradeCompleted is an upgrade after which every node runs the target version
+	UpgradeCompleted UpgradePhase = "Completed"
+)
+
+// UpgradeIssueLevel is how much an issue found before an upgrade matters
+type UpgradeIssueLevel string
+
+const (
+	// UpgradeIssueCritical blocks the upgrade until it is resolved
+	UpgradeIssueCritical UpgradeIssueLevel = "Critical"
+	// UpgradeIssueWarning is reported but doesn't block the upgrade
+	UpgradeIssueWarning UpgradeIssueLevel = "Warning"
+)
+
+// ElasticsearchUpgradeIssue is a problem found checking the cluster before an upgrade
+type ElasticsearchUpgradeIssue struct {
+	Level   UpgradeIssueLevel `json:"level"`
+	Message string            `json:"message"`
+}
+
+// ElasticsearchUpgradeStatus is the upgrade of the nodes from the lowest version they run to
+// the version of the packaged image
+type ElasticsearchUpgradeStatus struct {
+	// The lowest version of Elasticsearch the nodes ran when the upgrade was planned
+	CurrentVersion string `json:"currentVersion"`
+	// The version of Elasticsearch of the packaged image
+	TargetVersion string `json:"targetVersion"`
+
+	// +optional
+	Strategy UpgradeStrategy `json:"strategy,omitempty"`
+	Phase    UpgradePhase    `json:"phase"`
+
+	// The issues found checking the cluster, critical ones block the upgrade
+	//
+	// +optional
+	Issues []ElasticsearchUpgradeIssue `json:"issues,omitempty"`
+
+	// Details on the phase
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// MigrationPhase is the state of a migration of the data or settings of a cluster
+type MigrationPhase string
+
+const (
+	// MigrationPending is a migration that didn't run yet, e.g. during a dry run
+	MigrationPending MigrationPhase = "Pending"
+	// MigrationBlocked is a migration waiting for its preconditions, e.g. the version of the nodes
+	MigrationBlocked MigrationPhase = "Blocked"
+	// MigrationFailed is a migration whose last run failed, it runs again
+	MigrationFailed MigrationPhase = "Failed"
+	// MigrationSkipped is a migration with nothing to migrate
+	MigrationSkipped MigrationPhase = "Skipped"
+	// MigrationCompleted is a migration whose completion is recorded in the cluster
+	MigrationCompleted MigrationPhase = "Completed"
+)
+
+// ElasticsearchMigrationStatus is the state of a migration of the data or settings of a cluster
+type ElasticsearchMigrationStatus struct {
+	// The ID of the migration and of the document recording its completion in the
+	// .operator-migrations index
+	ID string `json:"id"`
+
+	// What the migration changes
+	//
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	Phase MigrationPhase `json:"phase"`
+
+	// Details on the phase, e.g. the precondition the migration waits for
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ElasticsearchRemoteClusterStatus is the connectivity of the cluster to a remote cluster
+type ElasticsearchRemoteClusterStatus struct {
+	// The alias of the remote cluster
+	Name string `json:"name"`
+
+	// The seeds the nodes connect to
+	//
+	// +optional
+	Seeds []string `json:"seeds,omitempty"`
+
+	// Connected is true while the nodes are connected to nodes of the remote cluster
+	Connected bool `json:"connected"`
+
+	// The number of nodes of the remote cluster the nodes are connected to
+	//
+	// +optional
+	NumNodesConnected int32 `json:"numNodesConnected,omitempty"`
+}
+
+// ElasticsearchStorageMigration replaces the nodes of a node group, one at a time, with nodes
+// running on claims of another storage class
+type ElasticsearchStorageMigration struct {
+	// The UUID of the node group being migrated
+	UUID string `json:"uuid"`
+	// The UUID of the replacement nodes, the node group takes it over once every node was replaced
+	NewUUID string `json:"newUUID"`
+	// The storage class the claims of the replacement nodes are provisioned with
+	StorageClassName string `json:"storageClassName"`
+	// The progress per node
+	Nodes []ElasticsearchNodeMigration `json:"nodes"`
+}
+
+// NodeMigrationPhase is the progress of replacing a node
+type NodeMigrationPhase string
+
+const (
+	// NodeMigrationPending is a node whose replacement wasn't started yet
+	NodeMigrationPending NodeMigrationPhase = "Pending"
+	// NodeMigrationRelocating is a node whose shards are relocating to its replacement
+	NodeMigrationRelocating NodeMigrationPhase = "Relocating"
+	// NodeMigrationRetired is a node that was removed together with its claim
+	NodeMigrationRetired NodeMigrationPhase = "Retired"
+)
+
+// ElasticsearchNodeMigration is a node replaced by a storage migration
+type ElasticsearchNodeMigration struct {
+	// The name of the node being replaced
+	Name string `json:"name"`
+	// The name of the node replacing it
+	Replacement string             `json:"replacement"`
+	Phase       NodeMigrationPhase `json:"phase"`
+}
+
+// VolumeSnapshotSetPhase is the progress of a set of volume snapshots
+type VolumeSnapshotSetPhase string
+
+const (
+	// VolumeSnapshotSetInProgress is a set whose snapshots aren't ready to use yet, shard
+	// allocation is limited to primaries until every snapshot was taken
+	VolumeSnapshotSetInProgress VolumeSnapshotSetPhase = "InProgress"
+	// VolumeSnapshotSetCompleted is a set whose snapshots are all ready to use
+	VolumeSnapshotSetCompleted VolumeSnapshotSetPhase = "Completed"
+	// VolumeSnapshotSetFailed is a set of which a snapshot failed, its snapshots are deleted
+	VolumeSnapshotSetFailed VolumeSnapshotSetPhase = "Failed"
+)
+
+// ElasticsearchVolumeSnapshotSet are the snapshots of the data claims taken together
+type ElasticsearchVolumeSnapshotSet struct {
+	// The name of the set, the snapshots carry it in their labels
+	Name  string                 `json:"name"`
+	Phase VolumeSnapshotSetPhase `json:"phase"`
+	// The time the nodes were flushed and the snapshots requested
+	Started metav1.Time `json:"started"`
+	// +optional
+	Snapshots []ElasticsearchVolumeSnapshot `json:"snapshots,omitempty"`
+	// Why the set failed
+	//
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ElasticsearchVolumeSnapshot is the VolumeSnapshot of a data claim
+type ElasticsearchVolumeSnapshot struct {
+	// The name of the VolumeSnapshot
+	Name string `json:"name"`
+	// The name of the snapshotted claim
+	ClaimName string `json:"claimName"`
+	// The time the storage system took the snapshot
+	//
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// +optional
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+}
+
+// ElasticsearchOrphanedClaim is a persistent volume claim of the cluster no node runs on
+type ElasticsearchOrphanedClaim struct {
+	// The name of the claim
+	Name string `json:"name"`
+	// The UUID of the node the claim belonged to
+	//
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+	// The capacity of the volume bound to the claim
+	//
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// The time the claim was created
+	Created metav1.Time `json:"created"`
+	// The time the claim is deleted by the retention policy
+	//
+	// +optional
+	DeleteAfter *metav1.Time `json:"deleteAfter,omitempty"`
+}
+
+// CertRedeployStrategy is the way nodes are restarted after the secret of the cluster changed
+type CertRedeployStrategy string
+
+const (
+	// CertRedeployRolling restarts the nodes one at a time, possible as long as the
+	// certificate authority is unchanged and old and new certificates trust each other
+	CertRedeployRolling CertRedeployStrategy = "Rolling"
+	// CertRedeployFullCluster stops all nodes before starting them with the new certificates
+	CertRedeployFullCluster CertRedeployStrategy = "FullCluster"
+)
+
+// ElasticsearchCertRedeployStatus explains how the latest change to the secret of the cluster is rolled out
+type ElasticsearchCertRedeployStatus struct {
+	// The strategy chosen to restart the nodes
+	Strategy CertRedeployStrategy `json:"strategy"`
+	// Human-readable explanation why the strategy was chosen
+	//
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// The keys of the secret that changed
+	//
+	// +optional
+	ChangedKeys []string `json:"changedKeys,omitempty"`
+	// The time the redeploy was scheduled
+	StartTime metav1.Time `json:"startTime"`
+	// The time every node was restarted with the new secret
+	//
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// ElasticsearchCertificateStatus reports a certificate of the cluster and when it expires
+type ElasticsearchCertificateStatus struct {
+	// The key of the certificate in the secret of the cluster
+	Key string `json:"key"`
+	// The subject of the certificate
+	//
+	// +optional
+	Subject string `json:"subject,omitempty"`
+	// The time after which the certificate is no longer valid
+	NotAfter metav1.Time `json:"notAfter"`
 }
 
 type ClusterHealth struct {
@@ -172,6 +733,9 @@ type ElasticsearchNode struct {
 
 	// The resource requirements for the Elasticsearch proxy
 	ProxyResources corev1.ResourceRequirements `json:"proxyResources,omitempty"`
+
+	// Pod settings of the node, overriding the settings common to all nodes
+	ElasticsearchPodSpec `json:",inline"`
 }
 
 // ElasticsearchNodeSpec represents configuration of an individual Elasticsearch node
@@ -200,6 +764,50 @@ type ElasticsearchNodeSpec struct {
 	// +nullable
 	// +optional
 	ProxyResources corev1.ResourceRequirements `json:"proxyResources,omitempty"`
+
+	// Pod settings common to all nodes
+	ElasticsearchPodSpec `json:",inline"`
+}
+
+// ElasticsearchPodSpec defines the identity, security and scheduling settings of the pods of the nodes
+type ElasticsearchPodSpec struct {
+	// The service account the pods run as, defaults to the service account the operator
+	// creates for the cluster
+	//
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// The security settings of the pods
+	//
+	// +nullable
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// The security settings of the elasticsearch and proxy containers
+	//
+	// +nullable
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// The secrets used to pull the images of the pods
+	//
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// The priority class of the pods
+	//
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Annotations added to the pods
+	//
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// Labels added to the pods, the labels set by the operator take precedence
+	//
+	// +optional
+	PodLabels map[string]string `json:"podLabels,omitempty"`
 }
 
 type ElasticsearchStorageSpec struct {
@@ -210,6 +818,14 @@ type ElasticsearchStorageSpec struct {
 
 	// The max storage capacity for the node to provision.
 	Size *resource.Quantity `json:"size,omitempty"`
+
+	// The source the claims of the node are provisioned from, e.g. a VolumeSnapshot
+	// of the status. It only applies to claims created after it was set and to node
+	// groups of a single node.
+	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes/#volume-snapshot-and-restore-volume-from-snapshot-support
+	//
+	// +optional
+	DataSource *corev1.TypedLocalObjectReference `json:"dataSource,omitempty"`
 }
 
 // ElasticsearchNodeStatus represents the status of individual Elasticsearch node
@@ -234,6 +850,8 @@ type ElasticsearchNodeUpgradeStatus struct {
 	ScheduledForCertRedeploy corev1.ConditionStatus    `json:"scheduledCertRedeploy,omitempty"`
 	UnderUpgrade             corev1.ConditionStatus    `json:"underUpgrade,omitempty"`
 	UpgradePhase             ElasticsearchUpgradePhase `json:"upgradePhase,omitempty"`
+	// The node is excluded from shard allocation until it rejoined the cluster after its update
+	AllocationExcluded corev1.ConditionStatus `json:"allocationExcluded,omitempty"`
 }
 
 type ClusterCondition struct {
@@ -328,6 +946,9 @@ const (
 	InvalidData              ClusterConditionType = "InvalidData"
 	InvalidRedundancy        ClusterConditionType = "InvalidRedundancy"
 	InvalidUUID              ClusterConditionType = "InvalidUUID"
+	InvalidProxyAuthRoles    ClusterConditionType = "InvalidProxyAuthRoles"
+	InvalidAuditLog          ClusterConditionType = "InvalidAuditLog"
+	InvalidStorage           ClusterConditionType = "InvalidStorage"
 	ESContainerWaiting       ClusterConditionType = "ElasticsearchContainerWaiting"
 	ESContainerTerminated    ClusterConditionType = "ElasticsearchContainerTerminated"
 	ProxyContainerWaiting    ClusterConditionType = "ProxyContainerWaiting"
@@ -336,4 +957,10 @@ const (
 	NodeStorage              ClusterConditionType = "NodeStorage"
 	CustomImage              ClusterConditionType = "CustomImageIgnored"
 	DegradedState            ClusterConditionType = "Degraded"
+	CertificatesExpiringSoon ClusterConditionType = "CertificatesExpiringSoon"
+	IntegrationsDisabled     ClusterConditionType = "IntegrationsDisabled"
+	StorageResizeRejected    ClusterConditionType = "StorageResizeRejected"
+	// StorageChangeRequiresConfirmation lists the nodes held back from switching between
+	// ephemeral and persistent storage until the change was acknowledged
+	StorageChangeRequiresConfirmation ClusterConditionType = "StorageChangeRequiresConfirmation"
 )
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticsearchDataRecoverySpec names the orphaned persistent volume claims whose indices are
+// reindexed into a running cluster
+//
+// +k8s:openapi-gen=true
+type ElasticsearchDataRecoverySpec struct {
+	// Name of the Elasticsearch cluster in the same namespace the indices are recovered into
+	//
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Elasticsearch Cluster Name"
+	ClusterName string `json:"clusterName"`
+
+	// Name of the cluster the claims belonged to, defaults to the cluster name
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Source Cluster Name"
+	SourceClusterName string `json:"sourceClusterName,omitempty"`
+
+	// UUIDs of the nodes whose claims hold the data, e.g. xm9nl5cb for the claim
+	// elasticsearch-elasticsearch-cdm-xm9nl5cb-1. They must not be used by the cluster.
+	//
+	// +kubebuilder:validation:MinItems:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node UUIDs"
+	UUIDs []string `json:"uuids"`
+
+	// Index patterns to recover, defaults to the log indices: app-*, infra-*, audit-*,
+	// project.* and .operations.*
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Index Patterns"
+	Indices []string `json:"indices,omitempty"`
+
+	// Resources of the nodes of the temporary recovery cluster
+	//
+	// +nullable
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Recovery Node Resources",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ElasticsearchDataRecoveryPhase is the step the recovery is at
+type ElasticsearchDataRecoveryPhase string
+
+const (
+	// DataRecoveryPending waits for the cluster to recover into
+	DataRecoveryPending ElasticsearchDataRecoveryPhase = "Pending"
+	// DataRecoveryProvisioning waits for the recovery cluster to start on the claims
+	DataRecoveryProvisioning ElasticsearchDataRecoveryPhase = "Provisioning"
+	// DataRecoveryReindexing copies the indices into the cluster
+	DataRecoveryReindexing ElasticsearchDataRecoveryPhase = "Reindexing"
+	// DataRecoveryCompleted means every index was recovered and the recovery cluster removed
+	DataRecoveryCompleted ElasticsearchDataRecoveryPhase = "Completed"
+	// DataRecoveryFailed means the recovery can't proceed, the message tells why
+	DataRecoveryFailed ElasticsearchDataRecoveryPhase = "Failed"
+)
+
+// ElasticsearchRecoveredIndexPhase is the state of the reindex of an index
+type ElasticsearchRecoveredIndexPhase string
+
+const (
+	RecoveredIndexPending   ElasticsearchRecoveredIndexPhase = "Pending"
+	RecoveredIndexRunning   ElasticsearchRecoveredIndexPhase = "Running"
+	RecoveredIndexCompleted ElasticsearchRecoveredIndexPhase = "Completed"
+	RecoveredIndexFailed    ElasticsearchRecoveredIndexPhase = "Failed"
+)
+
+// ElasticsearchDataRecoveryStatus reports the progress of the recovery
+//
+// +k8s:openapi-gen=true
+type ElasticsearchDataRecoveryStatus struct {
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Phase"
+	Phase ElasticsearchDataRecoveryPhase `json:"phase,omitempty"`
+
+	// Details on the phase, e.g. why the recovery failed
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Message"
+	Message string `json:"message,omitempty"`
+
+	// Name of the temporary cluster running on the claims
+	//
+	// +optional
+	RecoveryClusterName string `json:"recoveryClusterName,omitempty"`
+
+	// Claims the recovery cluster runs on
+	//
+	// +optional
+	Claims []string `json:"claims,omitempty"`
+
+	// Indices found on the claims and the progress of their reindex
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Indices"
+	Indices []ElasticsearchRecoveredIndex `json:"indices,omitempty"`
+}
+
+// ElasticsearchRecoveredIndex is the reindex of an index of the recovery cluster
+type ElasticsearchRecoveredIndex struct {
+	// Name of the index on the claims
+	Source string `json:"source"`
+
+	// Name of the index in the cluster, the source name with "recovered" inserted
+	Destination string `json:"destination"`
+
+	Phase ElasticsearchRecoveredIndexPhase `json:"phase"`
+
+	// ID of the reindex task running in the cluster
+	//
+	// +optional
+	Task string `json:"task,omitempty"`
+
+	// Number of documents reindexed so far
+	//
+	// +optional
+	Created int64 `json:"created,omitempty"`
+
+	// Number of documents to reindex
+	//
+	// +optional
+	Total int64 `json:"total,omitempty"`
+
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=elasticsearchdatarecoveries,categories=logging,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",JSONPath=".spec.clusterName",type=string
+// +kubebuilder:printcolumn:name="Phase",JSONPath=".status.phase",type=string
+// ElasticsearchDataRecovery reindexes the data of orphaned persistent volume claims into a cluster
+// +operator-sdk:csv:customresourcedefinitions:displayName="Elasticsearch Data Recovery"
+type ElasticsearchDataRecovery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchDataRecoverySpec   `json:"spec,omitempty"`
+	Status ElasticsearchDataRecoveryStatus `json:"status,omitempty"`
+}
+
+// AddOwnerRefTo appends the ElasticsearchDataRecovery object as an OwnerReference to the passed object
+func (r *ElasticsearchDataRecovery) AddOwnerRefTo(o metav1.Object) {
+	trueVar := true
+	ref := metav1.OwnerReference{
+		APIVersion: GroupVersion.String(),
+		Kind:       "ElasticsearchDataRecovery",
+		Name:       r.Name,
+		UID:        r.UID,
+		Controller: &trueVar,
+	}
+	o.SetOwnerReferences(append(o.GetOwnerReferences(), ref))
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchDataRecoveryList contains a list of ElasticsearchDataRecovery
+type ElasticsearchDataRecoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchDataRecovery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchDataRecovery{}, &ElasticsearchDataRecoveryList{})
+}
@@ -0,0 +1,164 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticsearchReindexSpec copies indices of a cluster into a new index, e.g. to fix their
+// mappings or change their number of shards
+//
+// +k8s:openapi-gen=true
+type ElasticsearchReindexSpec struct {
+	// Name of the Elasticsearch cluster in the same namespace holding the indices
+	//
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Elasticsearch Cluster Name"
+	ClusterName string `json:"clusterName"`
+
+	// Names or patterns of the indices to copy, e.g. app-000001 or app-*
+	//
+	// +kubebuilder:validation:MinItems:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Source Indices"
+	Source []string `json:"source"`
+
+	// Name of the index the documents are copied into. Create it beforehand to set its
+	// mappings and number of shards, otherwise it is created from the index templates.
+	//
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Destination Index"
+	Destination string `json:"destination"`
+
+	// Script run on every document before it is copied
+	//
+	// +nullable
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Script"
+	Script *ElasticsearchReindexScript `json:"script,omitempty"`
+
+	// Number of slices the reindex is split into to run in parallel, defaults to one
+	//
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Slices",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	Slices int32 `json:"slices,omitempty"`
+
+	// Throttles the reindex to this number of documents per second, unthrottled by default.
+	// Changing it applies to the running reindex.
+	//
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Requests Per Second",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:number"}
+	RequestsPerSecond int32 `json:"requestsPerSecond,omitempty"`
+
+	// Alias moved from the indices holding it to the destination index once every document
+	// was copied
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Alias"
+	Alias string `json:"alias,omitempty"`
+
+	// Cancels the reindex, the documents copied so far are kept
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Cancel",xDescriptors={"urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+// ElasticsearchReindexScript is a script modifying the documents as they are copied
+type ElasticsearchReindexScript struct {
+	// Source of the script, e.g. ctx._source.remove("kubernetes.labels")
+	Source string `json:"source"`
+
+	// Language of the script, defaults to painless
+	//
+	// +optional
+	Lang string `json:"lang,omitempty"`
+}
+
+// ElasticsearchReindexPhase is the step the reindex is at
+type ElasticsearchReindexPhase string
+
+const (
+	// ReindexPending waits for the cluster to be reachable
+	ReindexPending ElasticsearchReindexPhase = "Pending"
+	// ReindexRunning copies the documents
+	ReindexRunning ElasticsearchReindexPhase = "Running"
+	// ReindexCompleted means every document was copied and the alias moved
+	ReindexCompleted ElasticsearchReindexPhase = "Completed"
+	// ReindexFailed means the reindex can't proceed, the message tells why
+	ReindexFailed ElasticsearchReindexPhase = "Failed"
+	// ReindexCancelled means the reindex was cancelled before it completed
+	ReindexCancelled ElasticsearchReindexPhase = "Cancelled"
+)
+
+// ElasticsearchReindexStatus reports the progress of the reindex
+//
+// +k8s:openapi-gen=true
+type ElasticsearchReindexStatus struct {
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Phase"
+	Phase ElasticsearchReindexPhase `json:"phase,omitempty"`
+
+	// Details on the phase, e.g. why the reindex failed
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Message"
+	Message string `json:"message,omitempty"`
+
+	// ID of the reindex task running in the cluster
+	//
+	// +optional
+	Task string `json:"task,omitempty"`
+
+	// Requests per second the running task is throttled to
+	//
+	// +optional
+	RequestsPerSecond int32 `json:"requestsPerSecond,omitempty"`
+
+	// Number of documents to copy
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Total"
+	Total int64 `json:"total,omitempty"`
+
+	// Number of documents created in the destination index so far
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Created"
+	Created int64 `json:"created,omitempty"`
+
+	// Number of documents of the destination index overwritten so far
+	//
+	// +optional
+	Updated int64 `json:"updated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=elasticsearchreindexes,categories=logging,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",JSONPath=".spec.clusterName",type=string
+// +kubebuilder:printcolumn:name="Destination",JSONPath=".spec.destination",type=string
+// +kubebuilder:printcolumn:name="Phase",JSONPath=".status.phase",type=string
+// +kubebuilder:printcolumn:name="Created",JSONPath=".status.created",type=integer
+// +kubebuilder:printcolumn:name="Total",JSONPath=".status.total",type=integer
+// ElasticsearchReindex copies indices of a cluster into a new index and moves an alias to it
+// +operator-sdk:csv:customresourcedefinitions:displayName="Elasticsearch Reindex"
+type ElasticsearchReindex struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchReindexSpec   `json:"spec,omitempty"`
+	Status ElasticsearchReindexStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchReindexList contains a list of ElasticsearchReindex
+type ElasticsearchReindexList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchReindex `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchReindex{}, &ElasticsearchReindexList{})
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticsearchRoleMappingSpec defines the roles granted to Kubernetes subjects
+// on an Elasticsearch cluster
+//
+// +k8s:openapi-gen=true
+type ElasticsearchRoleMappingSpec struct {
+	// Name of the Elasticsearch cluster in the same namespace the roles are applied to
+	//
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Elasticsearch Cluster Name"
+	ClusterName string `json:"clusterName"`
+
+	// Roles to create in the security configuration of the cluster
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Roles"
+	Roles []ElasticsearchRole `json:"roles,omitempty"`
+}
+
+// ElasticsearchRole grants index privileges to a set of Kubernetes subjects. It is applied to
+// the cluster as the role <metadata.name>-<name>-<hash>, the hash of both names keeps the
+// roles of role mappings whose names share a prefix apart.
+type ElasticsearchRole struct {
+	// Name of the role, unique within the role mapping
+	//
+	// +kubebuilder:validation:Pattern:="^[a-zA-Z0-9_.-]+$"
+	Name string `json:"name"`
+
+	// Privileges on index patterns or aliases
+	//
+	// +kubebuilder:validation:MinItems:=1
+	Indices []ElasticsearchIndexPermission `json:"indices"`
+
+	// Names of the users granted the role
+	//
+	// +optional
+	Users []string `json:"users,omitempty"`
+
+	// Backend roles granted the role. The proxy only passes the user name along, not the
+	// Kubernetes groups of the user, so a group is granted the role through a backend role
+	// its members get by passing a SubjectAccessReview, see spec.proxy.authRoles of the
+	// Elasticsearch.
+	//
+	// +optional
+	BackendRoles []string `json:"backendRoles,omitempty"`
+
+	// Service accounts granted the role
+	//
+	// +optional
+	ServiceAccounts []ElasticsearchServiceAccountSubject `json:"serviceAccounts,omitempty"`
+}
+
+// ElasticsearchIndexPermission lists the privileges on a set of index patterns
+type ElasticsearchIndexPermission struct {
+	// Index patterns or aliases the privileges apply to, e.g. app-team-a-*
+	//
+	// +kubebuilder:validation:MinItems:=1
+	IndexPatterns []string `json:"indexPatterns"`
+
+	// Privileges granted on the indices, either action groups (e.g. READ, SEARCH,
+	// INDICES_MONITOR) or actions starting with indices:
+	//
+	// +kubebuilder:validation:MinItems:=1
+	Privileges []string `json:"privileges"`
+}
+
+// ElasticsearchServiceAccountSubject references a service account
+type ElasticsearchServiceAccountSubject struct {
+	// Namespace of the service account, defaults to the namespace of the role mapping
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	Name string `json:"name"`
+}
+
+// ElasticsearchRoleMappingStatus shows which roles were applied to the cluster
+//
+// +k8s:openapi-gen=true
+type ElasticsearchRoleMappingStatus struct {
+	// Name of the Elasticsearch cluster the roles were applied to
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Elasticsearch Cluster Name"
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// Names of the roles applied to the cluster
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Applied Roles"
+	Applied []string `json:"applied,omitempty"`
+
+	// Roles of the spec that were not applied and why
+	//
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Rejected Roles"
+	Rejected []ElasticsearchRoleRejection `json:"rejected,omitempty"`
+
+	// The generation of the spec the status reflects
+	//
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ElasticsearchRoleRejection explains why a role was not applied
+type ElasticsearchRoleRejection struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=elasticsearchrolemappings,categories=logging,scope=Namespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",JSONPath=".spec.clusterName",type=string
+// ElasticsearchRoleMapping binds Kubernetes subjects to roles on an Elasticsearch cluster
+// +operator-sdk:csv:customresourcedefinitions:displayName="Elasticsearch Role Mapping"
+type ElasticsearchRoleMapping struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticsearchRoleMappingSpec   `json:"spec,omitempty"`
+	Status ElasticsearchRoleMappingStatus `json:"status,omitempty"`
+}
+
+// AppliedClusterName returns the cluster the applied roles are in. Role mappings applied before
+// the status recorded it applied them to the cluster of the spec.
+func (m *ElasticsearchRoleMapping) AppliedClusterName() string {
+	if m.Status.ClusterName != "" {
+		return m.Status.ClusterName
+	}
+	return m.Spec.ClusterName
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticsearchRoleMappingList contains a list of ElasticsearchRoleMapping
+type ElasticsearchRoleMappingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticsearchRoleMapping `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticsearchRoleMapping{}, &ElasticsearchRoleMappingList{})
+}
@@ -0,0 +1,67 @@
+package v1
+
+// ExposureType is the kind of resource exposing a component outside of the cluster
+//
+// +kubebuilder:validation:Enum:=Route;Ingress;LoadBalancer
+type ExposureType string
+
+const (
+	// ExposureRoute exposes the component with an OpenShift Route
+	ExposureRoute ExposureType = "Route"
+	// ExposureIngress exposes the component with a networking.k8s.io/v1beta1 Ingress, which
+	// Kubernetes removes in 1.22
+	ExposureIngress ExposureType = "Ingress"
+	// ExposureLoadBalancer exposes the component with a Service of type LoadBalancer
+	ExposureLoadBalancer ExposureType = "LoadBalancer"
+)
+
+// ExposureTermination is how TLS is terminated in front of the component
+//
+// +kubebuilder:validation:Enum:=Reencrypt;Passthrough
+type ExposureTermination string
+
+const (
+	// ExposureReencrypt terminates TLS at the router or ingress controller, which
+	// connects to the component trusting the managed CA
+	ExposureReencrypt ExposureTermination = "Reencrypt"
+	// ExposurePassthrough lets clients connect to the component presenting the
+	// certificate signed by the managed CA
+	ExposurePassthrough ExposureTermination = "Passthrough"
+)
+
+// ExposureSpec defines how a component is reachable from outside of the cluster
+type ExposureSpec struct {
+	// The kind of resource exposing the component
+	//
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Exposure Type"
+	Type ExposureType `json:"type"`
+
+	// How TLS is terminated, defaults to Reencrypt. LoadBalancer services always pass
+	// TLS through.
+	//
+	// +optional
+	Termination ExposureTermination `json:"termination,omitempty"`
+
+	// The host name of the Route or Ingress. Required for Ingress, Routes default to the
+	// host name generated by the router.
+	//
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// The class of the ingress controller serving the Ingress
+	//
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Name of the secret holding the certificate (tls.crt, tls.key) the ingress controller
+	// presents to clients with Reencrypt, defaults to the certificate of the controller
+	//
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations added to the Route, Ingress or Service, e.g. to configure the ingress
+	// controller or the cloud load balancer
+	//
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
@@ -35,6 +35,12 @@ type KibanaSpec struct {
 	//
 	// +optional
 	ProxySpec `json:"proxy,omitempty"`
+
+	// How Kibana is reachable from outside of the cluster, defaults to a reencrypt Route
+	//
+	// +nullable
+	// +optional
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
 }
 
 type ProxySpec struct {
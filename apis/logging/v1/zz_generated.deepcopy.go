@@ -6,6 +6,8 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -88,6 +90,247 @@ func (in *Elasticsearch) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchAuditLogSpec) DeepCopyInto(out *ElasticsearchAuditLogSpec) {
+	*out = *in
+	if in.IncludeCategories != nil {
+		in, out := &in.IncludeCategories, &out.IncludeCategories
+		*out = make([]AuditCategory, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeCategories != nil {
+		in, out := &in.ExcludeCategories, &out.ExcludeCategories
+		*out = make([]AuditCategory, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreUsers != nil {
+		in, out := &in.IgnoreUsers, &out.IgnoreUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchAuditLogSpec.
+func (in *ElasticsearchAuditLogSpec) DeepCopy() *ElasticsearchAuditLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchAuditLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchCertRedeployStatus) DeepCopyInto(out *ElasticsearchCertRedeployStatus) {
+	*out = *in
+	if in.ChangedKeys != nil {
+		in, out := &in.ChangedKeys, &out.ChangedKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchCertRedeployStatus.
+func (in *ElasticsearchCertRedeployStatus) DeepCopy() *ElasticsearchCertRedeployStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchCertRedeployStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchCertificateStatus) DeepCopyInto(out *ElasticsearchCertificateStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchCertificateStatus.
+func (in *ElasticsearchCertificateStatus) DeepCopy() *ElasticsearchCertificateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchCertificateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchCertificatesSpec) DeepCopyInto(out *ElasticsearchCertificatesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchCertificatesSpec.
+func (in *ElasticsearchCertificatesSpec) DeepCopy() *ElasticsearchCertificatesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchCertificatesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchClusterStorageSpec) DeepCopyInto(out *ElasticsearchClusterStorageSpec) {
+	*out = *in
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(ElasticsearchStorageRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchClusterStorageSpec.
+func (in *ElasticsearchClusterStorageSpec) DeepCopy() *ElasticsearchClusterStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchClusterStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchDataRecovery) DeepCopyInto(out *ElasticsearchDataRecovery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchDataRecovery.
+func (in *ElasticsearchDataRecovery) DeepCopy() *ElasticsearchDataRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchDataRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchDataRecovery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchDataRecoveryList) DeepCopyInto(out *ElasticsearchDataRecoveryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchDataRecovery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchDataRecoveryList.
+func (in *ElasticsearchDataRecoveryList) DeepCopy() *ElasticsearchDataRecoveryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchDataRecoveryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchDataRecoveryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchDataRecoverySpec) DeepCopyInto(out *ElasticsearchDataRecoverySpec) {
+	*out = *in
+	if in.UUIDs != nil {
+		in, out := &in.UUIDs, &out.UUIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchDataRecoverySpec.
+func (in *ElasticsearchDataRecoverySpec) DeepCopy() *ElasticsearchDataRecoverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchDataRecoverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchDataRecoveryStatus) DeepCopyInto(out *ElasticsearchDataRecoveryStatus) {
+	*out = *in
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]ElasticsearchRecoveredIndex, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchDataRecoveryStatus.
+func (in *ElasticsearchDataRecoveryStatus) DeepCopy() *ElasticsearchDataRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchDataRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchIndexPermission) DeepCopyInto(out *ElasticsearchIndexPermission) {
+	*out = *in
+	if in.IndexPatterns != nil {
+		in, out := &in.IndexPatterns, &out.IndexPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchIndexPermission.
+func (in *ElasticsearchIndexPermission) DeepCopy() *ElasticsearchIndexPermission {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchIndexPermission)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchList) DeepCopyInto(out *ElasticsearchList) {
 	*out = *in
@@ -95,25 +338,584 @@ func (in *ElasticsearchList) DeepCopyInto(out *ElasticsearchList) {
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Elasticsearch, len(*in))
+		*out = make([]Elasticsearch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchList.
+func (in *ElasticsearchList) DeepCopy() *ElasticsearchList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchMigrationStatus) DeepCopyInto(out *ElasticsearchMigrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchMigrationStatus.
+func (in *ElasticsearchMigrationStatus) DeepCopy() *ElasticsearchMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNetworkPolicySpec) DeepCopyInto(out *ElasticsearchNetworkPolicySpec) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNetworkPolicySpec.
+func (in *ElasticsearchNetworkPolicySpec) DeepCopy() *ElasticsearchNetworkPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNetworkPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNode) DeepCopyInto(out *ElasticsearchNode) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]ElasticsearchNodeRole, len(*in))
+		copy(*out, *in)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Storage.DeepCopyInto(&out.Storage)
+	if in.GenUUID != nil {
+		in, out := &in.GenUUID, &out.GenUUID
+		*out = new(string)
+		**out = **in
+	}
+	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
+	in.ElasticsearchPodSpec.DeepCopyInto(&out.ElasticsearchPodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNode.
+func (in *ElasticsearchNode) DeepCopy() *ElasticsearchNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeMigration) DeepCopyInto(out *ElasticsearchNodeMigration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeMigration.
+func (in *ElasticsearchNodeMigration) DeepCopy() *ElasticsearchNodeMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeSpec) DeepCopyInto(out *ElasticsearchNodeSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
+	in.ElasticsearchPodSpec.DeepCopyInto(&out.ElasticsearchPodSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeSpec.
+func (in *ElasticsearchNodeSpec) DeepCopy() *ElasticsearchNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeStatus) DeepCopyInto(out *ElasticsearchNodeStatus) {
+	*out = *in
+	out.UpgradeStatus = in.UpgradeStatus
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]ElasticsearchNodeRole, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(ClusterConditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeStatus.
+func (in *ElasticsearchNodeStatus) DeepCopy() *ElasticsearchNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchNodeUpgradeStatus) DeepCopyInto(out *ElasticsearchNodeUpgradeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeUpgradeStatus.
+func (in *ElasticsearchNodeUpgradeStatus) DeepCopy() *ElasticsearchNodeUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchNodeUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchOrphanedClaim) DeepCopyInto(out *ElasticsearchOrphanedClaim) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	in.Created.DeepCopyInto(&out.Created)
+	if in.DeleteAfter != nil {
+		in, out := &in.DeleteAfter, &out.DeleteAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchOrphanedClaim.
+func (in *ElasticsearchOrphanedClaim) DeepCopy() *ElasticsearchOrphanedClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchOrphanedClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchPodSpec) DeepCopyInto(out *ElasticsearchPodSpec) {
+	*out = *in
+	if in.PodSecurityContext != nil {
+		in, out := &in.PodSecurityContext, &out.PodSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchPodSpec.
+func (in *ElasticsearchPodSpec) DeepCopy() *ElasticsearchPodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchPodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchProxyAuthRole) DeepCopyInto(out *ElasticsearchProxyAuthRole) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchProxyAuthRole.
+func (in *ElasticsearchProxyAuthRole) DeepCopy() *ElasticsearchProxyAuthRole {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchProxyAuthRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchProxySpec) DeepCopyInto(out *ElasticsearchProxySpec) {
+	*out = *in
+	if in.AuthRoles != nil {
+		in, out := &in.AuthRoles, &out.AuthRoles
+		*out = make([]ElasticsearchProxyAuthRole, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchProxySpec.
+func (in *ElasticsearchProxySpec) DeepCopy() *ElasticsearchProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRecoveredIndex) DeepCopyInto(out *ElasticsearchRecoveredIndex) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRecoveredIndex.
+func (in *ElasticsearchRecoveredIndex) DeepCopy() *ElasticsearchRecoveredIndex {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRecoveredIndex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchReindex) DeepCopyInto(out *ElasticsearchReindex) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchReindex.
+func (in *ElasticsearchReindex) DeepCopy() *ElasticsearchReindex {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchReindex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchReindex) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchReindexList) DeepCopyInto(out *ElasticsearchReindexList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchReindex, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchReindexList.
+func (in *ElasticsearchReindexList) DeepCopy() *ElasticsearchReindexList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchReindexList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchReindexList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchReindexScript) DeepCopyInto(out *ElasticsearchReindexScript) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchReindexScript.
+func (in *ElasticsearchReindexScript) DeepCopy() *ElasticsearchReindexScript {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchReindexScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchReindexSpec) DeepCopyInto(out *ElasticsearchReindexSpec) {
+	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(ElasticsearchReindexScript)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchReindexSpec.
+func (in *ElasticsearchReindexSpec) DeepCopy() *ElasticsearchReindexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchReindexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchReindexStatus) DeepCopyInto(out *ElasticsearchReindexStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchReindexStatus.
+func (in *ElasticsearchReindexStatus) DeepCopy() *ElasticsearchReindexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchReindexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRemoteCluster) DeepCopyInto(out *ElasticsearchRemoteCluster) {
+	*out = *in
+	if in.ElasticsearchRef != nil {
+		in, out := &in.ElasticsearchRef, &out.ElasticsearchRef
+		*out = new(ElasticsearchRemoteClusterReference)
+		**out = **in
+	}
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRemoteCluster.
+func (in *ElasticsearchRemoteCluster) DeepCopy() *ElasticsearchRemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRemoteClusterReference) DeepCopyInto(out *ElasticsearchRemoteClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRemoteClusterReference.
+func (in *ElasticsearchRemoteClusterReference) DeepCopy() *ElasticsearchRemoteClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRemoteClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRemoteClusterStatus) DeepCopyInto(out *ElasticsearchRemoteClusterStatus) {
+	*out = *in
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRemoteClusterStatus.
+func (in *ElasticsearchRemoteClusterStatus) DeepCopy() *ElasticsearchRemoteClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRemoteClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRole) DeepCopyInto(out *ElasticsearchRole) {
+	*out = *in
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]ElasticsearchIndexPermission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackendRoles != nil {
+		in, out := &in.BackendRoles, &out.BackendRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]ElasticsearchServiceAccountSubject, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRole.
+func (in *ElasticsearchRole) DeepCopy() *ElasticsearchRole {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRoleMapping) DeepCopyInto(out *ElasticsearchRoleMapping) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleMapping.
+func (in *ElasticsearchRoleMapping) DeepCopy() *ElasticsearchRoleMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchRoleMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticsearchRoleMapping) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRoleMappingList) DeepCopyInto(out *ElasticsearchRoleMappingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticsearchRoleMapping, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchList.
-func (in *ElasticsearchList) DeepCopy() *ElasticsearchList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleMappingList.
+func (in *ElasticsearchRoleMappingList) DeepCopy() *ElasticsearchRoleMappingList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchList)
+	out := new(ElasticsearchRoleMappingList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticsearchList) DeepCopyObject() runtime.Object {
+func (in *ElasticsearchRoleMappingList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -121,117 +923,93 @@ func (in *ElasticsearchList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchNode) DeepCopyInto(out *ElasticsearchNode) {
+func (in *ElasticsearchRoleMappingSpec) DeepCopyInto(out *ElasticsearchRoleMappingSpec) {
 	*out = *in
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
-		*out = make([]ElasticsearchNodeRole, len(*in))
-		copy(*out, *in)
-	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
+		*out = make([]ElasticsearchRole, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Storage.DeepCopyInto(&out.Storage)
-	if in.GenUUID != nil {
-		in, out := &in.GenUUID, &out.GenUUID
-		*out = new(string)
-		**out = **in
-	}
-	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNode.
-func (in *ElasticsearchNode) DeepCopy() *ElasticsearchNode {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleMappingSpec.
+func (in *ElasticsearchRoleMappingSpec) DeepCopy() *ElasticsearchRoleMappingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchNode)
+	out := new(ElasticsearchRoleMappingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchNodeSpec) DeepCopyInto(out *ElasticsearchNodeSpec) {
+func (in *ElasticsearchRoleMappingStatus) DeepCopyInto(out *ElasticsearchRoleMappingStatus) {
 	*out = *in
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Rejected != nil {
+		in, out := &in.Rejected, &out.Rejected
+		*out = make([]ElasticsearchRoleRejection, len(*in))
+		copy(*out, *in)
 	}
-	in.ProxyResources.DeepCopyInto(&out.ProxyResources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeSpec.
-func (in *ElasticsearchNodeSpec) DeepCopy() *ElasticsearchNodeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleMappingStatus.
+func (in *ElasticsearchRoleMappingStatus) DeepCopy() *ElasticsearchRoleMappingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchNodeSpec)
+	out := new(ElasticsearchRoleMappingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchNodeStatus) DeepCopyInto(out *ElasticsearchNodeStatus) {
+func (in *ElasticsearchRoleRejection) DeepCopyInto(out *ElasticsearchRoleRejection) {
 	*out = *in
-	out.UpgradeStatus = in.UpgradeStatus
-	if in.Roles != nil {
-		in, out := &in.Roles, &out.Roles
-		*out = make([]ElasticsearchNodeRole, len(*in))
-		copy(*out, *in)
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(ClusterConditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRoleRejection.
+func (in *ElasticsearchRoleRejection) DeepCopy() *ElasticsearchRoleRejection {
+	if in == nil {
+		return nil
 	}
+	out := new(ElasticsearchRoleRejection)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeStatus.
-func (in *ElasticsearchNodeStatus) DeepCopy() *ElasticsearchNodeStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchRolloutStrategy) DeepCopyInto(out *ElasticsearchRolloutStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchRolloutStrategy.
+func (in *ElasticsearchRolloutStrategy) DeepCopy() *ElasticsearchRolloutStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchNodeStatus)
+	out := new(ElasticsearchRolloutStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticsearchNodeUpgradeStatus) DeepCopyInto(out *ElasticsearchNodeUpgradeStatus) {
+func (in *ElasticsearchServiceAccountSubject) DeepCopyInto(out *ElasticsearchServiceAccountSubject) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchNodeUpgradeStatus.
-func (in *ElasticsearchNodeUpgradeStatus) DeepCopy() *ElasticsearchNodeUpgradeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchServiceAccountSubject.
+func (in *ElasticsearchServiceAccountSubject) DeepCopy() *ElasticsearchServiceAccountSubject {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticsearchNodeUpgradeStatus)
+	out := new(ElasticsearchServiceAccountSubject)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -252,6 +1030,53 @@ func (in *ElasticsearchSpec) DeepCopyInto(out *ElasticsearchSpec) {
 		*out = new(IndexManagementSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(ElasticsearchRolloutStrategy)
+		**out = **in
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(ElasticsearchCertificatesSpec)
+		**out = **in
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(ElasticsearchNetworkPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ElasticsearchProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(ElasticsearchAuditLogSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(ElasticsearchClusterStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = new(ElasticsearchVolumeSnapshotPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]ElasticsearchRemoteCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSpec.
@@ -312,6 +1137,56 @@ func (in *ElasticsearchStatus) DeepCopyInto(out *ElasticsearchStatus) {
 		*out = new(IndexManagementStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = make([]ElasticsearchCertificateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CertificateRedeploy != nil {
+		in, out := &in.CertificateRedeploy, &out.CertificateRedeploy
+		*out = new(ElasticsearchCertRedeployStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanedClaims != nil {
+		in, out := &in.OrphanedClaims, &out.OrphanedClaims
+		*out = make([]ElasticsearchOrphanedClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]ElasticsearchVolumeSnapshotSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageMigrations != nil {
+		in, out := &in.StorageMigrations, &out.StorageMigrations
+		*out = make([]ElasticsearchStorageMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]ElasticsearchRemoteClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]ElasticsearchMigrationStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(ElasticsearchUpgradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStatus.
@@ -324,6 +1199,46 @@ func (in *ElasticsearchStatus) DeepCopy() *ElasticsearchStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchStorageMigration) DeepCopyInto(out *ElasticsearchStorageMigration) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ElasticsearchNodeMigration, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStorageMigration.
+func (in *ElasticsearchStorageMigration) DeepCopy() *ElasticsearchStorageMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchStorageMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchStorageRetentionPolicy) DeepCopyInto(out *ElasticsearchStorageRetentionPolicy) {
+	*out = *in
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStorageRetentionPolicy.
+func (in *ElasticsearchStorageRetentionPolicy) DeepCopy() *ElasticsearchStorageRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchStorageRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticsearchStorageSpec) DeepCopyInto(out *ElasticsearchStorageSpec) {
 	*out = *in
@@ -337,6 +1252,11 @@ func (in *ElasticsearchStorageSpec) DeepCopyInto(out *ElasticsearchStorageSpec)
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.DataSource != nil {
+		in, out := &in.DataSource, &out.DataSource
+		*out = new(corev1.TypedLocalObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchStorageSpec.
@@ -349,6 +1269,136 @@ func (in *ElasticsearchStorageSpec) DeepCopy() *ElasticsearchStorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUpgradeIssue) DeepCopyInto(out *ElasticsearchUpgradeIssue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUpgradeIssue.
+func (in *ElasticsearchUpgradeIssue) DeepCopy() *ElasticsearchUpgradeIssue {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUpgradeIssue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchUpgradeStatus) DeepCopyInto(out *ElasticsearchUpgradeStatus) {
+	*out = *in
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]ElasticsearchUpgradeIssue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchUpgradeStatus.
+func (in *ElasticsearchUpgradeStatus) DeepCopy() *ElasticsearchUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchVolumeSnapshot) DeepCopyInto(out *ElasticsearchVolumeSnapshot) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchVolumeSnapshot.
+func (in *ElasticsearchVolumeSnapshot) DeepCopy() *ElasticsearchVolumeSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchVolumeSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchVolumeSnapshotPolicy) DeepCopyInto(out *ElasticsearchVolumeSnapshotPolicy) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Keep != nil {
+		in, out := &in.Keep, &out.Keep
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchVolumeSnapshotPolicy.
+func (in *ElasticsearchVolumeSnapshotPolicy) DeepCopy() *ElasticsearchVolumeSnapshotPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchVolumeSnapshotPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchVolumeSnapshotSet) DeepCopyInto(out *ElasticsearchVolumeSnapshotSet) {
+	*out = *in
+	in.Started.DeepCopyInto(&out.Started)
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]ElasticsearchVolumeSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchVolumeSnapshotSet.
+func (in *ElasticsearchVolumeSnapshotSet) DeepCopy() *ElasticsearchVolumeSnapshotSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchVolumeSnapshotSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposureSpec) DeepCopyInto(out *ExposureSpec) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposureSpec.
+func (in *ExposureSpec) DeepCopy() *ExposureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExposureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexManagementActionSpec) DeepCopyInto(out *IndexManagementActionSpec) {
 	*out = *in
@@ -695,6 +1745,11 @@ func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
 		}
 	}
 	in.ProxySpec.DeepCopyInto(&out.ProxySpec)
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ExposureSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpec.
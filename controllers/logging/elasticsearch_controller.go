@@ -2,21 +2,35 @@ package controllers
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/openshift/elasticsearch-operator/internal/metrics"
 
 	"github.com/ViaQ/logerr/log"
 	"github.com/go-logr/logr"
-	v1 "k8s.io/api/core/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/k8shandler"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 )
 
 // ElasticsearchReconciler reconciles a Elasticsearch object
@@ -24,6 +38,15 @@ type ElasticsearchReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// APIReader reads from the API server directly, bypassing the cache of the manager
+	APIReader client.Reader
+
+	// MaxConcurrentReconciles is the number of clusters reconciled in parallel. Defaults to 1.
+	MaxConcurrentReconciles int
+
+	// Capabilities are the optional APIs served by the platform
+	Capabilities platform.Capabilities
 }
 
 // Reconcile reads that state of the cluster for a Elasticsearch object and makes changes based on the state read
@@ -32,6 +55,16 @@ var (
 	reconcilePeriod = 30 * time.Second
 	// reconcileResult = reconcile.Result{RequeueAfter: reconcilePeriod}
 	reconcileResult = ctrl.Result{RequeueAfter: reconcilePeriod}
+
+	// restarts don't block the reconciler while waiting for the cluster,
+	// check back sooner to continue them
+	restartPeriod = 5 * time.Second
+	restartResult = ctrl.Result{RequeueAfter: restartPeriod}
+
+	// changes to the owned resources trigger a reconcile through the watches,
+	// a healthy cluster only needs to be polled for its elasticsearch state
+	steadyPeriod = 5 * time.Minute
+	steadyResult = ctrl.Result{RequeueAfter: steadyPeriod}
 )
 
 func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
@@ -44,12 +77,22 @@ func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result,
 			log.Info("Flushing nodes", "objectKey", request.NamespacedName)
 			k8shandler.FlushNodes(request.NamespacedName.Name, request.NamespacedName.Namespace)
 			k8shandler.RemoveDashboardConfigMap(r.Client)
-			return ctrl.Result{}, nil
+
+			// the claims of the deleted cluster are kept until their retention passed
+			wait, err := k8shandler.DeleteExpiredClaims(request.Name, request.Namespace, r.Client)
+			if err != nil {
+				return reconcileResult, err
+			}
+			return ctrl.Result{RequeueAfter: wait}, nil
 		}
 
 		return ctrl.Result{}, err
 	}
 
+	if cluster.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, k8shandler.ReleaseClusterStorage(cluster, r.Client)
+	}
+
 	if cluster.Spec.ManagementState == loggingv1.ManagementStateUnmanaged {
 		// Cluster state changes from Managed -> Unmanaged, so set "unmanaged" as 1 and set "managed" as 0.
 		metrics.SetEsClusterManagementStateUnmanaged()
@@ -72,7 +115,7 @@ func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result,
 		if !exists {
 			cluster.Status.Conditions = append(cluster.Status.Conditions, loggingv1.ClusterCondition{
 				Type:               loggingv1.CustomImage,
-				Status:             v1.ConditionTrue,
+				Status:             corev1.ConditionTrue,
 				LastTransitionTime: metav1.Now(),
 				Reason:             "CustomImageUnsupported",
 				Message:            "Specifiying a custom image from the custom resource is not supported",
@@ -81,16 +124,224 @@ func (r *ElasticsearchReconciler) Reconcile(request ctrl.Request) (ctrl.Result,
 
 	}
 
-	if err = k8shandler.Reconcile(cluster, r.Client); err != nil {
+	if err = k8shandler.Reconcile(cluster, r.Client, r.APIReader, r.Capabilities); err != nil {
 		return reconcileResult, err
 	}
 
-	return reconcileResult, nil
+	return requeueResult(cluster), nil
+}
+
+// requeueResult checks back quickly while a restart or upgrade is in flight and
+// while the cluster is recovering, volume snapshots are taken or storage is migrated. Otherwise the watches on the owned resources
+// pick up any drift and the cluster is only polled occasionally.
+func requeueResult(cluster *loggingv1.Elasticsearch) ctrl.Result {
+	switch {
+	case k8shandler.IsRestartInProgress(cluster):
+		return restartResult
+	case cluster.Status.Cluster.Status != "green", k8shandler.IsVolumeSnapshotInProgress(cluster),
+		k8shandler.IsStorageMigrationInProgress(cluster):
+		return reconcileResult
+	default:
+		return steadyResult
+	}
+}
+
+// newClusterRateLimiter only applies the exponential backoff per cluster. The default
+// controller rate limiter additionally uses an overall token bucket shared by all
+// clusters, so one failing cluster could slow down the reconciles of every other one.
+func newClusterRateLimiter() ratelimiter.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(5*time.Millisecond, 5*time.Minute)
+}
+
+// clusterSpecPredicate ignores the status updates the operator itself makes to the cluster
+var clusterSpecPredicate = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations()) ||
+				!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels())
+		},
+	},
+)
+
+// ownedResourcePredicate only lets through deletions and changes to the owned resources that
+// need the operator to act, e.g. manual edits or the progress of a rollout, but not the
+// resource version bumps of their status heartbeats.
+var ownedResourcePredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return false },
+	DeleteFunc: func(e event.DeleteEvent) bool { return true },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() {
+			return true
+		}
+
+		switch old := e.ObjectOld.(type) {
+		case *appsv1.Deployment:
+			cur := e.ObjectNew.(*appsv1.Deployment)
+			return old.Status.ReadyReplicas != cur.Status.ReadyReplicas ||
+				old.Status.UpdatedReplicas != cur.Status.UpdatedReplicas ||
+				old.Status.ObservedGeneration != cur.Status.ObservedGeneration
+		case *appsv1.StatefulSet:
+			cur := e.ObjectNew.(*appsv1.StatefulSet)
+			return old.Status.ReadyReplicas != cur.Status.ReadyReplicas ||
+				old.Status.UpdatedReplicas != cur.Status.UpdatedReplicas ||
+				old.Status.ObservedGeneration != cur.Status.ObservedGeneration
+		case *corev1.ConfigMap:
+			cur := e.ObjectNew.(*corev1.ConfigMap)
+			return !reflect.DeepEqual(old.Data, cur.Data)
+		case *corev1.Service:
+			cur := e.ObjectNew.(*corev1.Service)
+			return !reflect.DeepEqual(old.Spec, cur.Spec)
+		}
+
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// podPredicate lets through the elasticsearch pods that became ready or unready or were deleted
+var podPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return false },
+	DeleteFunc: func(e event.DeleteEvent) bool { return isElasticsearchPod(e.Meta) },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if !isElasticsearchPod(e.MetaNew) {
+			return false
+		}
+		old, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		cur, ok := e.ObjectNew.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		return isPodReady(old) != isPodReady(cur)
+	},
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// pvcPredicate lets through the deletions of and the capacity changes to the elasticsearch volumes,
+// and the volumes awaiting their deletion when the operator starts
+var pvcPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return e.Meta.GetAnnotations()[k8shandler.ClaimDeleteAfterAnnotation] != ""
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool { return e.Meta.GetLabels()[pvcClusterLabel] != "" },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.MetaNew.GetLabels()[pvcClusterLabel] == "" {
+			return false
+		}
+		old, ok := e.ObjectOld.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			return false
+		}
+		cur, ok := e.ObjectNew.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			return false
+		}
+		return old.Status.Phase != cur.Status.Phase ||
+			!reflect.DeepEqual(old.Status.Capacity, cur.Status.Capacity) ||
+			!reflect.DeepEqual(old.Status.Conditions, cur.Status.Conditions)
+	},
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// dataRecoveryPredicate lets through the data recoveries that add or remove a recovery
+// cluster from the reindex whitelist of their cluster
+var dataRecoveryPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return true },
+	DeleteFunc: func(e event.DeleteEvent) bool { return true },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*loggingv1.ElasticsearchDataRecovery)
+		if !ok {
+			return false
+		}
+		cur, ok := e.ObjectNew.(*loggingv1.ElasticsearchDataRecovery)
+		if !ok {
+			return false
+		}
+		return old.Status.Phase != cur.Status.Phase ||
+			old.GetDeletionTimestamp().IsZero() != cur.GetDeletionTimestamp().IsZero()
+	},
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// dataRecoveryToCluster maps a data recovery to the cluster it reindexes into and the cluster
+// whose claims it runs on, which are not deleted while the recovery runs
+var dataRecoveryToCluster = handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+	recovery, ok := a.Object.(*loggingv1.ElasticsearchDataRecovery)
+	if !ok {
+		return nil
+	}
+	requests := clusterRequest(recovery.Namespace, recovery.Spec.ClusterName)
+	if source := recovery.Spec.SourceClusterName; source != "" && source != recovery.Spec.ClusterName {
+		requests = append(requests, clusterRequest(recovery.Namespace, source)...)
+	}
+	return requests
+})
+
+const pvcClusterLabel = "logging-cluster"
+
+func isElasticsearchPod(meta metav1.Object) bool {
+	labels := meta.GetLabels()
+	return labels["component"] == "elasticsearch" && labels["cluster-name"] != ""
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podToCluster maps the elasticsearch pods, which are owned by replicasets and statefulsets,
+// to the cluster named in their labels
+var podToCluster = handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+	return clusterRequest(a.Meta.GetNamespace(), a.Meta.GetLabels()["cluster-name"])
+})
+
+// pvcToCluster maps the elasticsearch volumes, which are not owned by the cluster so that
+// they survive its deletion, to the cluster named in their labels
+var pvcToCluster = handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+	return clusterRequest(a.Meta.GetNamespace(), a.Meta.GetLabels()[pvcClusterLabel])
+})
+
+func clusterRequest(namespace, name string) []reconcile.Request {
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}},
+	}
 }
 
 func (r *ElasticsearchReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("elasticsearch-controller").
-		For(&loggingv1.Elasticsearch{}).
+		For(&loggingv1.Elasticsearch{}, builder.WithPredicates(clusterSpecPredicate)).
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(ownedResourcePredicate)).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(ownedResourcePredicate)).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(ownedResourcePredicate)).
+		Owns(&corev1.Service{}, builder.WithPredicates(ownedResourcePredicate)).
+		Owns(&networkingv1.NetworkPolicy{}, builder.WithPredicates(ownedResourcePredicate)).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: podToCluster,
+		}, builder.WithPredicates(podPredicate)).
+		Watches(&source.Kind{Type: &corev1.PersistentVolumeClaim{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: pvcToCluster,
+		}, builder.WithPredicates(pvcPredicate)).
+		Watches(&source.Kind{Type: &loggingv1.ElasticsearchDataRecovery{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: dataRecoveryToCluster,
+		}, builder.WithPredicates(dataRecoveryPredicate)).
+		// the secret of a cluster shares its name, changes to the HTTP certificates
+		// are rolled out through the pod template
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{},
+			builder.WithPredicates(esSecretUpdatePredicate(r.Client))).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             newClusterRateLimiter(),
+		}).
 		Complete(r)
 }
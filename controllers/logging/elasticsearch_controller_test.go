@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestRequeueResult(t *testing.T) {
+	tests := []struct {
+		desc   string
+		status loggingv1.ElasticsearchStatus
+		want   ctrl.Result
+	}{
+		{
+			desc: "healthy cluster",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: "green"},
+			},
+			want: steadyResult,
+		},
+		{
+			desc: "recovering cluster",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: "yellow"},
+			},
+			want: reconcileResult,
+		},
+		{
+			desc: "node under upgrade",
+			status: loggingv1.ElasticsearchStatus{
+				Cluster: loggingv1.ClusterHealth{Status: "green"},
+				Nodes: []loggingv1.ElasticsearchNodeStatus{
+					{
+						DeploymentName: "elasticsearch-cdm-1",
+						UpgradeStatus: loggingv1.ElasticsearchNodeUpgradeStatus{
+							UnderUpgrade: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			want: restartResult,
+		},
+	}
+
+	for _, test := range tests {
+		cluster := &loggingv1.Elasticsearch{Status: test.status}
+		if got := requeueResult(cluster); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestOwnedResourcePredicate(t *testing.T) {
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-cdm-1", Generation: 1, ResourceVersion: "1"},
+	}
+
+	heartbeat := dpl.DeepCopy()
+	heartbeat.ResourceVersion = "2"
+
+	edited := dpl.DeepCopy()
+	edited.Generation = 2
+
+	progressed := dpl.DeepCopy()
+	progressed.Status.ReadyReplicas = 1
+
+	tests := []struct {
+		desc string
+		new  *appsv1.Deployment
+		want bool
+	}{
+		{desc: "resource version bump", new: heartbeat, want: false},
+		{desc: "spec edit", new: edited, want: true},
+		{desc: "rollout progress", new: progressed, want: true},
+	}
+
+	for _, test := range tests {
+		e := event.UpdateEvent{
+			MetaOld:   dpl,
+			ObjectOld: dpl,
+			MetaNew:   test.new,
+			ObjectNew: test.new,
+		}
+		if got := ownedResourcePredicate.Update(e); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.desc, got, test.want)
+		}
+	}
+
+	cm := &corev1.ConfigMap{Data: map[string]string{"elasticsearch.yml": "old"}}
+	changed := cm.DeepCopy()
+	changed.Data["elasticsearch.yml"] = "new"
+
+	e := event.UpdateEvent{MetaOld: cm, ObjectOld: cm, MetaNew: changed, ObjectNew: changed}
+	if !ownedResourcePredicate.Update(e) {
+		t.Errorf("expected changes to configmap data to trigger a reconcile")
+	}
+}
+
+func TestPodPredicate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1-abc",
+			Namespace: "openshift-logging",
+			Labels: map[string]string{
+				"cluster-name": "elasticsearch",
+				"component":    "elasticsearch",
+			},
+		},
+	}
+
+	ready := pod.DeepCopy()
+	ready.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+	}
+
+	if !podPredicate.Update(event.UpdateEvent{MetaOld: pod, ObjectOld: pod, MetaNew: ready, ObjectNew: ready}) {
+		t.Errorf("expected pod becoming ready to trigger a reconcile")
+	}
+
+	if podPredicate.Update(event.UpdateEvent{MetaOld: ready, ObjectOld: ready, MetaNew: ready, ObjectNew: ready}) {
+		t.Errorf("expected unchanged readiness not to trigger a reconcile")
+	}
+
+	other := ready.DeepCopy()
+	other.Labels = map[string]string{"component": "kibana"}
+	if podPredicate.Delete(event.DeleteEvent{Meta: other, Object: other}) {
+		t.Errorf("expected pods of other components to be ignored")
+	}
+
+	requests := podToCluster(handler.MapObject{Meta: pod, Object: pod})
+	if len(requests) != 1 || requests[0].Name != "elasticsearch" || requests[0].Namespace != "openshift-logging" {
+		t.Errorf("expected pod to map to its cluster, got %v", requests)
+	}
+}
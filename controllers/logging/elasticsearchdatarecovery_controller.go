@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler"
+)
+
+// the recovery waits on the clusters and the reindex tasks, none of which are watched
+var dataRecoveryResult = ctrl.Result{RequeueAfter: 15 * time.Second}
+
+// ElasticsearchDataRecoveryReconciler reconciles a ElasticsearchDataRecovery object
+type ElasticsearchDataRecoveryReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *ElasticsearchDataRecoveryReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	recovery := &loggingv1.ElasticsearchDataRecovery{}
+	if err := r.Get(context.TODO(), request.NamespacedName, recovery); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	esClient := elasticsearch.NewClient(recovery.Spec.ClusterName, recovery.Namespace, r.Client)
+	recoveryClient := elasticsearch.NewClient(recovery.Name+"-recovery", recovery.Namespace, r.Client)
+	if err := k8shandler.ReconcileDataRecovery(recovery, r.Client, esClient, recoveryClient); err != nil {
+		return dataRecoveryResult, err
+	}
+
+	if recovery.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+	switch recovery.Status.Phase {
+	case loggingv1.DataRecoveryCompleted, loggingv1.DataRecoveryFailed:
+		return ctrl.Result{}, nil
+	}
+	return dataRecoveryResult, nil
+}
+
+func (r *ElasticsearchDataRecoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.ElasticsearchDataRecovery{}).
+		Complete(r)
+}
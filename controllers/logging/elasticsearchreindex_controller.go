@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler"
+)
+
+// the reindex task isn't watched, its progress is polled
+var reindexResult = ctrl.Result{RequeueAfter: 15 * time.Second}
+
+// ElasticsearchReindexReconciler reconciles a ElasticsearchReindex object
+type ElasticsearchReindexReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *ElasticsearchReindexReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	reindex := &loggingv1.ElasticsearchReindex{}
+	if err := r.Get(context.TODO(), request.NamespacedName, reindex); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	esClient := elasticsearch.NewClient(reindex.Spec.ClusterName, reindex.Namespace, r.Client)
+	if err := k8shandler.ReconcileReindex(reindex, r.Client, esClient); err != nil {
+		return reindexResult, err
+	}
+
+	if reindex.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+	switch reindex.Status.Phase {
+	case loggingv1.ReindexCompleted, loggingv1.ReindexFailed, loggingv1.ReindexCancelled:
+		return ctrl.Result{}, nil
+	}
+	return reindexResult, nil
+}
+
+func (r *ElasticsearchReindexReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.ElasticsearchReindex{}).
+		Complete(r)
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler"
+)
+
+// ElasticsearchRoleMappingReconciler reconciles a ElasticsearchRoleMapping object
+type ElasticsearchRoleMappingReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *ElasticsearchRoleMappingReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	mapping := &loggingv1.ElasticsearchRoleMapping{}
+	if err := r.Get(context.TODO(), request.NamespacedName, mapping); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	esClient := elasticsearch.NewClient(mapping.Spec.ClusterName, mapping.Namespace, r.Client)
+	appliedClient := elasticsearch.NewClient(mapping.AppliedClusterName(), mapping.Namespace, r.Client)
+	if err := k8shandler.ReconcileRoleMapping(mapping, r.Client, esClient, appliedClient); err != nil {
+		return reconcileResult, err
+	}
+
+	if mapping.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// the security config is reset when it is reseeded, e.g. after the cluster was
+	// recreated, so the roles are applied again from time to time
+	return steadyResult, nil
+}
+
+// roleMappingPredicate ignores the status updates the operator itself makes to the role mapping
+var roleMappingPredicate = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaNew.GetDeletionTimestamp() != nil
+		},
+	},
+)
+
+// clusterCreatedPredicate lets through new clusters only, their role mappings were rejected so far
+var clusterCreatedPredicate = predicate.Funcs{
+	CreateFunc:  func(e event.CreateEvent) bool { return true },
+	DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+	UpdateFunc:  func(e event.UpdateEvent) bool { return false },
+	GenericFunc: func(e event.GenericEvent) bool { return false },
+}
+
+// roleMappingsForCluster returns requests for the role mappings of the cluster
+func (r *ElasticsearchRoleMappingReconciler) roleMappingsForCluster(a handler.MapObject) []reconcile.Request {
+	mappings := &loggingv1.ElasticsearchRoleMappingList{}
+	if err := r.List(context.TODO(), mappings, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "failed to list role mappings", "namespace", a.Meta.GetNamespace())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, mapping := range mappings.Items {
+		if mapping.Spec.ClusterName == a.Meta.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: client.ObjectKey{Name: mapping.Name, Namespace: mapping.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+func (r *ElasticsearchRoleMappingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&loggingv1.ElasticsearchRoleMapping{}, builder.WithPredicates(roleMappingPredicate)).
+		Watches(&source.Kind{Type: &loggingv1.Elasticsearch{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.roleMappingsForCluster),
+		}, builder.WithPredicates(clusterCreatedPredicate)).
+		Complete(r)
+}
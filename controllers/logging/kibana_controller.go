@@ -31,6 +31,7 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/internal/k8shandler"
 	"github.com/openshift/elasticsearch-operator/internal/k8shandler/kibana"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 )
 
@@ -55,6 +56,12 @@ type KibanaReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// APIReader reads from the API server directly, bypassing the cache of the manager
+	APIReader client.Reader
+
+	// Capabilities are the optional APIs served by the platform
+	Capabilities platform.Capabilities
 }
 
 func (r *KibanaReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
@@ -90,12 +97,15 @@ func (r *KibanaReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error)
 	}
 
 	esClient := elasticsearch.NewClient(es.Name, es.Namespace, r.Client)
-	proxyCfg, err := kibana.GetProxyConfig(r.Client)
-	if err != nil {
-		return reconcile.Result{}, err
+	var proxyCfg *configv1.Proxy
+	if r.Capabilities.Has(platform.ClusterProxy) {
+		proxyCfg, err = kibana.GetProxyConfig(r.Client)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
 	}
 
-	if err := kibana.Reconcile(kibanaInstance, r.Client, esClient, proxyCfg); err != nil {
+	if err := kibana.Reconcile(kibanaInstance, r.Client, r.APIReader, esClient, proxyCfg, r.Capabilities); err != nil {
 		return reconcile.Result{}, err
 	}
 
@@ -275,16 +285,23 @@ func (r *KibanaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	// TODO: replace the watches with For and Own
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("kibana-controller").
 		For(&loggingv1.Kibana{}).
 		Watches(&source.Kind{Type: &corev1.Secret{}}, &namespacedMapHandler, builder.WithPredicates(secretPred)).
-		Watches(&source.Kind{Type: &configv1.Proxy{}}, &globalMapHandler, builder.WithPredicates(proxyPred)).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &namespacedMapHandler, builder.WithPredicates(trustedBundlePred)).
-		Watches(&source.Kind{Type: &corev1.Pod{}}, &namespacedMapHandler, builder.WithPredicates(podPred)).
-		Watches(&source.Kind{Type: &routev1.Route{}}, &handler.EnqueueRequestForOwner{
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &namespacedMapHandler, builder.WithPredicates(podPred))
+
+	// watching kinds the platform doesn't serve fails to start the controller
+	if r.Capabilities.Has(platform.ClusterProxy) {
+		b = b.Watches(&source.Kind{Type: &configv1.Proxy{}}, &globalMapHandler, builder.WithPredicates(proxyPred))
+	}
+	if r.Capabilities.Has(platform.Routes) {
+		b = b.Watches(&source.Kind{Type: &routev1.Route{}}, &handler.EnqueueRequestForOwner{
 			OwnerType:    &loggingv1.Kibana{},
 			IsController: true,
-		}, builder.WithPredicates(routePred)).
-		Complete(r)
+		}, builder.WithPredicates(routePred))
+	}
+
+	return b.Complete(r)
 }
@@ -0,0 +1,293 @@
+package certificates
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+)
+
+const (
+	// CAValidity matches the lifetime of the signer created by hack/cert_generation.sh
+	CAValidity = 5 * 365 * 24 * time.Hour
+	// CertValidity matches the lifetime of the certificates created by hack/cert_generation.sh
+	CertValidity = 2 * 365 * 24 * time.Hour
+)
+
+var (
+	// KeySize is the size of the generated RSA keys, tests may lower it
+	KeySize = 4096
+
+	// nodeOID is the registered ID opendistro security uses to recognize the certificates of other nodes
+	nodeOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 5}
+
+	oidSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+)
+
+// KeyPair is a certificate together with its private key
+type KeyPair struct {
+	Cert *x509.Certificate
+	Key  *rsa.PrivateKey
+}
+
+// Request describes a certificate to be signed by a CA
+type Request struct {
+	CommonName string
+	DNSNames   []string
+	IPs        []net.IP
+	// Node adds the registered ID which marks the certificate as belonging to an elasticsearch node
+	Node bool
+	// Server allows the certificate to be used for serving in addition to client authentication
+	Server   bool
+	Validity time.Duration
+}
+
+// NewCA creates a self signed certificate authority
+func NewCA(commonName string, validity time.Duration) (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, KeySize)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to generate CA key")
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         commonName,
+			OrganizationalUnit: []string{"Logging Signing CA"},
+			Organization:       []string{"OpenShift Origin"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		SubjectKeyId:          subjectKeyID(&key.PublicKey),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create CA certificate")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse CA certificate")
+	}
+
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// Sign creates a key and a certificate for the request signed by the CA
+func (ca *KeyPair) Sign(req Request) (*KeyPair, error) {
+	key, err := rsa.GenerateKey(rand.Reader, KeySize)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to generate key", "common_name", req.CommonName)
+	}
+
+	serial, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:         req.CommonName,
+			OrganizationalUnit: []string{"OpenShift"},
+			Organization:       []string{"Logging"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(req.Validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		SubjectKeyId:          subjectKeyID(&key.PublicKey),
+	}
+
+	// a certificate can't outlive the CA that signed it
+	if template.NotAfter.After(ca.Cert.NotAfter) {
+		template.NotAfter = ca.Cert.NotAfter
+	}
+
+	if req.Server {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+
+	if len(req.DNSNames) > 0 || len(req.IPs) > 0 || req.Node {
+		// the standard library can't encode registered IDs, so the whole extension is built here
+		ext, err := subjectAltNames(req.DNSNames, req.IPs, req.Node)
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to encode subject alternative names", "common_name", req.CommonName)
+		}
+		template.ExtraExtensions = append(template.ExtraExtensions, ext)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &key.PublicKey, ca.Key)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create certificate", "common_name", req.CommonName)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse certificate", "common_name", req.CommonName)
+	}
+
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// IsSignedBy returns true if the certificate was issued by the given CA and is currently valid
+func (kp *KeyPair) IsSignedBy(ca *KeyPair) bool {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	_, err := kp.Cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
+
+// CertPEM returns the PEM encoded certificate
+func (kp *KeyPair) CertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: kp.Cert.Raw})
+}
+
+// KeyPEM returns the PEM encoded private key
+func (kp *KeyPair) KeyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(kp.Key)})
+}
+
+// KeyPKCS8PEM returns the PEM encoded private key in PKCS #8. The security plugin only reads
+// PKCS #8 keys from the PEM files of its transport settings, which the remote trust mounts. The
+// secrets of the cluster keep the PKCS #1 keys of KeyPEM, the image builds its JKS stores from
+// them.
+func (kp *KeyPair) KeyPKCS8PEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(kp.Key)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to encode private key")
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseKeyPair decodes a PEM encoded certificate and private key
+func ParseKeyPair(certPEM, keyPEM []byte) (*KeyPair, error) {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, kverrors.New("failed to decode PEM private key")
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		var parsed interface{}
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err == nil {
+			var ok bool
+			if key, ok = parsed.(*rsa.PrivateKey); !ok {
+				return nil, kverrors.New("private key is not an RSA key")
+			}
+		}
+	}
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse private key")
+	}
+
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(key.N) != 0 || pub.E != key.E {
+		return nil, kverrors.New("private key does not match the certificate",
+			"common_name", cert.Subject.CommonName)
+	}
+
+	return &KeyPair{Cert: cert, Key: key}, nil
+}
+
+// ParseCertificate decodes the first certificate of a PEM bundle
+func ParseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, kverrors.New("failed to decode PEM certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse certificate")
+	}
+	return cert, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to generate serial number")
+	}
+	return serial, nil
+}
+
+func subjectKeyID(pub *rsa.PublicKey) []byte {
+	sum := sha1.Sum(x509.MarshalPKCS1PublicKey(pub))
+	return sum[:]
+}
+
+// subjectAltNames encodes the GeneralNames of RFC 5280 section 4.2.1.6
+func subjectAltNames(dnsNames []string, ips []net.IP, node bool) (pkix.Extension, error) {
+	const (
+		tagDNSName      = 2
+		tagIPAddress    = 7
+		tagRegisteredID = 8
+	)
+
+	var names []asn1.RawValue
+	for _, name := range dnsNames {
+		names = append(names, asn1.RawValue{Tag: tagDNSName, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		names = append(names, asn1.RawValue{Tag: tagIPAddress, Class: asn1.ClassContextSpecific, Bytes: ip})
+	}
+
+	if node {
+		oid, err := asn1.Marshal(nodeOID)
+		if err != nil {
+			return pkix.Extension{}, err
+		}
+		// registeredID is an implicitly tagged OBJECT IDENTIFIER, keep its content and replace the tag
+		var raw asn1.RawValue
+		if _, err := asn1.Unmarshal(oid, &raw); err != nil {
+			return pkix.Extension{}, err
+		}
+		names = append(names, asn1.RawValue{Tag: tagRegisteredID, Class: asn1.ClassContextSpecific, Bytes: raw.Bytes})
+	}
+
+	value, err := asn1.Marshal(names)
+	if err != nil {
+		return pkix.Extension{}, err
+	}
+
+	return pkix.Extension{Id: oidSubjectAltName, Value: value}, nil
+}
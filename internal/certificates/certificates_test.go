@@ -0,0 +1,145 @@
+package certificates
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func init() {
+	// keep the tests fast
+	KeySize = 1024
+}
+
+func TestSignedCertificateVerifies(t *testing.T) {
+	ca, err := NewCA("test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	kp, err := ca.Sign(Request{
+		CommonName: "elasticsearch",
+		DNSNames:   []string{"elasticsearch", "elasticsearch-cluster.openshift-logging.svc"},
+		IPs:        []net.IP{net.ParseIP("127.0.0.1")},
+		Node:       true,
+		Server:     true,
+		Validity:   time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if !kp.IsSignedBy(ca) {
+		t.Errorf("expected certificate to be signed by the CA")
+	}
+
+	other, err := NewCA("other-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if kp.IsSignedBy(other) {
+		t.Errorf("expected certificate not to be signed by another CA")
+	}
+
+	if _, err := kp.Cert.Verify(x509.VerifyOptions{
+		DNSName:   "elasticsearch-cluster.openshift-logging.svc",
+		Roots:     rootsOf(ca),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}); err != nil {
+		t.Errorf("expected certificate to be valid for the service name: %s", err)
+	}
+
+	if diff := cmp.Diff(kp.Cert.Subject.String(), "CN=elasticsearch,OU=OpenShift,O=Logging"); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+
+	if !hasRegisteredID(t, kp.Cert, nodeOID) {
+		t.Errorf("expected node certificate to carry the node OID")
+	}
+}
+
+func TestClientCertificateIsNotServing(t *testing.T) {
+	ca, err := NewCA("test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	kp, err := ca.Sign(Request{CommonName: "system.admin", Validity: time.Hour})
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	_, err = kp.Cert.Verify(x509.VerifyOptions{
+		Roots:     rootsOf(ca),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err == nil {
+		t.Errorf("expected client certificate not to be usable for serving")
+	}
+}
+
+func TestParseKeyPairRoundTrip(t *testing.T) {
+	ca, err := NewCA("test-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	parsed, err := ParseKeyPair(ca.CertPEM(), ca.KeyPEM())
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if !parsed.Cert.Equal(ca.Cert) {
+		t.Errorf("expected parsed certificate to equal the original")
+	}
+
+	other, err := NewCA("other-signer", time.Hour)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := ParseKeyPair(ca.CertPEM(), other.KeyPEM()); err == nil {
+		t.Errorf("expected mismatching key to be rejected")
+	}
+
+	if _, err := ParseKeyPair(nil, nil); err == nil {
+		t.Errorf("expected missing certificate to be rejected")
+	}
+
+	pkcs8, err := ca.KeyPKCS8PEM()
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := ParseKeyPair(ca.CertPEM(), pkcs8); err != nil {
+		t.Errorf("expected PKCS #8 key to be parsed, got err: %s", err)
+	}
+}
+
+func rootsOf(ca *KeyPair) *x509.CertPool {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	return roots
+}
+
+func hasRegisteredID(t *testing.T, cert *x509.Certificate, oid asn1.ObjectIdentifier) bool {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+
+		var names []asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &names); err != nil {
+			t.Fatalf("got err: %s", err)
+		}
+
+		want, _ := asn1.Marshal(oid)
+		for _, name := range names {
+			if name.Tag == 8 && string(name.Bytes) == string(want[2:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -12,6 +12,7 @@ const (
 	KibanaTrustedCAName         = "kibana-trusted-ca-bundle"
 	SecretHashPrefix            = "logging.openshift.io/"
 	ElasticsearchDefaultImage   = "quay.io/openshift/origin-logging-elasticsearch6"
+	ElasticsearchDefaultVersion = "6.8.1"
 	ProxyDefaultImage           = "quay.io/openshift/origin-elasticsearch-proxy:latest"
 	TheoreticalShardMaxSizeInMB = 40960
 
@@ -20,9 +21,10 @@ const (
 )
 
 var (
-	ReconcileForGlobalProxyList = []string{KibanaTrustedCAName}
-	packagedElasticsearchImage  = utils.LookupEnvWithDefault("ELASTICSEARCH_IMAGE", ElasticsearchDefaultImage)
-	ExpectedSecretKeys          = []string{
+	ReconcileForGlobalProxyList  = []string{KibanaTrustedCAName}
+	packagedElasticsearchImage   = utils.LookupEnvWithDefault("ELASTICSEARCH_IMAGE", ElasticsearchDefaultImage)
+	packagedElasticsearchVersion = utils.LookupEnvWithDefault("ELASTICSEARCH_VERSION", ElasticsearchDefaultVersion)
+	ExpectedSecretKeys           = []string{
 		"admin-ca",
 		"admin-cert",
 		"admin-key",
@@ -36,3 +38,9 @@ var (
 func PackagedElasticsearchImage() string {
 	return packagedElasticsearchImage
 }
+
+// PackagedElasticsearchVersion is the version of Elasticsearch the packaged image runs, the
+// nodes are upgraded to it
+func PackagedElasticsearchVersion() string {
+	return packagedElasticsearchVersion
+}
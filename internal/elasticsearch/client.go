@@ -38,11 +38,16 @@ type Client interface {
 
 	// Cluster Settings API
 	GetClusterNodeVersions() ([]string, error)
+	GetClusterSettings() (map[string]interface{}, error)
 	GetThresholdEnabled() (bool, error)
 	GetDiskWatermarks() (interface{}, interface{}, error)
 	GetMinMasterNodes() (int32, error)
 	SetMinMasterNodes(numberMasters int32) (bool, error)
 	DoSynchronizedFlush() (bool, error)
+	SetRemoteClusters(remotes map[string]*estypes.RemoteClusterSettings) error
+
+	// Remote Info API
+	GetRemoteClusterInfo() (estypes.RemoteInfoResponse, error)
 
 	// Cluster State API
 	GetLowestClusterVersion() (string, error)
@@ -57,10 +62,22 @@ type Client interface {
 	GetIndex(name string) (*estypes.Index, error)
 	CreateIndex(name string, index *estypes.Index) error
 	ReIndex(src, dst, script, lang string) error
+	ReIndexFromRemote(host, src, dst string) (string, error)
+	StartReIndex(reIndex *estypes.ReIndex, slices, requestsPerSecond int32) (string, error)
+	RethrottleReIndex(taskID string, requestsPerSecond int32) error
+	GetReIndexTask(taskID string) (*estypes.ReIndexTask, error)
+	CancelTask(taskID string) error
 	GetAllIndices(name string) (estypes.CatIndicesResponses, error)
+	GetIndexVersions(pattern string) (map[string]string, error)
+
+	// Document API
+	GetDocument(index, id string) (map[string]interface{}, error)
+	IndexDocument(index, id string, doc interface{}) error
+	DeleteDocument(index, id string) error
 
 	// Index Alias API
 	ListIndicesForAlias(aliasPattern string) ([]string, error)
+	GetIndexAliases(index string) (map[string]estypes.AliasDefinition, error)
 	UpdateAlias(actions estypes.AliasActions) error
 	AddAliasForOldIndices() bool
 
@@ -79,6 +96,8 @@ type Client interface {
 	ClearTransientShardAllocation() (bool, error)
 	GetShardAllocation() (string, error)
 	SetShardAllocation(state api.ShardAllocationState) (bool, error)
+	SetShardAllocationExclusion(nodeNames []string) (bool, error)
+	GetNodeShardCount(nodeName string) (int32, error)
 
 	// Index Templates API
 	CreateIndexTemplate(name string, template *estypes.IndexTemplate) error
@@ -87,6 +106,12 @@ type Client interface {
 	GetIndexTemplates() (map[string]estypes.GetIndexTemplate, error)
 	UpdateTemplatePrimaryShards(shardCount int32) error
 
+	// Security API
+	PutSecurityRole(name string, role *estypes.SecurityRole) error
+	DeleteSecurityRole(name string) error
+	PutSecurityRoleMapping(name string, mapping *estypes.SecurityRoleMapping) error
+	DeleteSecurityRoleMapping(name string) error
+
 	SetSendRequestFn(fn FnEsSendRequest)
 }
 
@@ -148,7 +173,7 @@ func sendEsRequest(cluster, namespace string, payload *EsRequest, client k8sclie
 	}
 
 	switch payload.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodDelete:
 		// no more to do to request...
 	case http.MethodPost:
 		if payload.RequestBody != "" {
@@ -225,7 +250,7 @@ func sendRequestWithMTlsClient(clusterName, namespace string, payload *EsRequest
 	}
 
 	switch payload.Method {
-	case http.MethodGet:
+	case http.MethodGet, http.MethodDelete:
 		// no more to do to request...
 	case http.MethodPost:
 		if payload.RequestBody != "" {
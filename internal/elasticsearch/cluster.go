@@ -199,6 +199,32 @@ func (ec *esClient) DoSynchronizedFlush() (bool, error) {
 	return payload.StatusCode == 200, payload.Error
 }
 
+// GetClusterSettings returns the persistent and the transient cluster settings flattened to
+// their full names, transient settings override persistent ones
+func (ec *esClient) GetClusterSettings() (map[string]interface{}, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cluster/settings?flat_settings=true",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get cluster settings",
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	settings := map[string]interface{}{}
+	for _, scope := range []string{"persistent", "transient"} {
+		values, _ := payload.ResponseBody[scope].(map[string]interface{})
+		for name, value := range values {
+			settings[name] = value
+		}
+	}
+	return settings, nil
+}
+
 func (ec *esClient) GetLowestClusterVersion() (string, error) {
 	payload := &EsRequest{
 		Method: http.MethodGet,
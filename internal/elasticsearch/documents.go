@@ -0,0 +1,86 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+// GetDocument returns the source of a document or nil if either the document or its index
+// doesn't exist
+func (ec *esClient) GetDocument(index, id string) (map[string]interface{}, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("%s/_doc/%s", index, id),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error == nil && payload.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get document",
+			"index", index,
+			"id", id,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	doc := struct {
+		Source map[string]interface{} `json:"_source"`
+	}{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &doc); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse document",
+			"index", index,
+			"id", id)
+	}
+	return doc.Source, nil
+}
+
+// IndexDocument creates or replaces a document and refreshes the index so that the document
+// is found right away
+func (ec *esClient) IndexDocument(index, id string, doc interface{}) error {
+	body, err := utils.ToJSON(doc)
+	if err != nil {
+		return err
+	}
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         fmt.Sprintf("%s/_doc/%s?refresh=true", index, id),
+		RequestBody: body,
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || (payload.StatusCode != http.StatusOK && payload.StatusCode != http.StatusCreated) {
+		return ec.errorCtx().New("failed to index document",
+			"index", index,
+			"id", id,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+// DeleteDocument deletes a document, it succeeds if the document doesn't exist
+func (ec *esClient) DeleteDocument(index, id string) error {
+	payload := &EsRequest{
+		Method: http.MethodDelete,
+		URI:    fmt.Sprintf("%s/_doc/%s?refresh=true", index, id),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error == nil && payload.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return ec.errorCtx().New("failed to delete document",
+			"index", index,
+			"id", id,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -70,6 +71,47 @@ func (ec *esClient) GetAllIndices(name string) (estypes.CatIndicesResponses, err
 	return res, nil
 }
 
+// GetIndexVersions returns the version of Elasticsearch the indices matching the pattern were
+// created with, indices are only readable by the next major version
+func (ec *esClient) GetIndexVersions(pattern string) (map[string]string, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("%s/_settings/index.version.created?flat_settings=true", pattern),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get index versions",
+			"pattern", pattern,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse index versions",
+			"pattern", pattern)
+	}
+
+	versions := map[string]string{}
+	for index, settings := range res {
+		id, err := strconv.Atoi(settings.Settings["index.version.created"])
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to parse index version",
+				"index", index)
+		}
+		versions[index] = versionFromID(id)
+	}
+	return versions, nil
+}
+
+// versionFromID turns the version ID of Elasticsearch into its version, e.g. 6080199 into 6.8.1
+func versionFromID(id int) string {
+	return fmt.Sprintf("%d.%d.%d", id/1000000, id/10000%100, id/100%100)
+}
+
 func (ec *esClient) CreateIndex(name string, index *estypes.Index) error {
 	body, err := utils.ToJSON(index)
 	if err != nil {
@@ -146,7 +188,7 @@ func (ec *esClient) ReIndex(src, dst, script, lang string) error {
 	reIndex := estypes.ReIndex{
 		Source: estypes.IndexRef{Index: src},
 		Dest:   estypes.IndexRef{Index: dst},
-		Script: estypes.ReIndexScript{
+		Script: &estypes.ReIndexScript{
 			Inline: script,
 			Lang:   lang,
 		},
@@ -174,6 +216,145 @@ func (ec *esClient) ReIndex(src, dst, script, lang string) error {
 	return nil
 }
 
+// ReIndexFromRemote starts copying an index of a remote cluster in the background and
+// returns the ID of the task
+func (ec *esClient) ReIndexFromRemote(host, src, dst string) (string, error) {
+	reIndex := estypes.RemoteReIndex{
+		Source: estypes.RemoteIndexRef{
+			Remote: estypes.RemoteHost{Host: host},
+			Index:  src,
+		},
+		Dest: estypes.IndexRef{Index: dst},
+	}
+
+	body, err := utils.ToJSON(reIndex)
+	if err != nil {
+		return "", err
+	}
+	payload := &EsRequest{
+		Method:      http.MethodPost,
+		URI:         "_reindex?wait_for_completion=false",
+		RequestBody: body,
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	task, ok := payload.ResponseBody["task"].(string)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK || !ok {
+		return "", ec.errorCtx().New("failed to reindex from remote",
+			"host", host,
+			"from", src,
+			"to", dst,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	return task, nil
+}
+
+// StartReIndex starts a reindex in the background and returns the ID of the task. The
+// reindex is split into the number of slices and throttled to the requests per second,
+// zero leaves either to the defaults.
+func (ec *esClient) StartReIndex(reIndex *estypes.ReIndex, slices, requestsPerSecond int32) (string, error) {
+	body, err := utils.ToJSON(reIndex)
+	if err != nil {
+		return "", err
+	}
+
+	uri := "_reindex?wait_for_completion=false"
+	if slices > 0 {
+		uri = fmt.Sprintf("%s&slices=%d", uri, slices)
+	}
+	if requestsPerSecond > 0 {
+		uri = fmt.Sprintf("%s&requests_per_second=%d", uri, requestsPerSecond)
+	}
+	payload := &EsRequest{
+		Method:      http.MethodPost,
+		URI:         uri,
+		RequestBody: body,
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	task, ok := payload.ResponseBody["task"].(string)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK || !ok {
+		return "", ec.errorCtx().New("failed to start reindex",
+			"from", reIndex.Source.Index,
+			"to", reIndex.Dest.Index,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	return task, nil
+}
+
+// RethrottleReIndex changes the requests per second of a running reindex, zero removes
+// the throttle
+func (ec *esClient) RethrottleReIndex(taskID string, requestsPerSecond int32) error {
+	rate := "-1"
+	if requestsPerSecond > 0 {
+		rate = fmt.Sprintf("%d", requestsPerSecond)
+	}
+	payload := &EsRequest{
+		Method: http.MethodPost,
+		URI:    fmt.Sprintf("_reindex/%s/_rethrottle?requests_per_second=%s", taskID, rate),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return ec.errorCtx().New("failed to rethrottle reindex",
+			"task", taskID,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+	return nil
+}
+
+// GetReIndexTask returns the progress of a reindex running in the background or nil if the
+// task is unknown, e.g. because its node restarted
+func (ec *esClient) GetReIndexTask(taskID string) (*estypes.ReIndexTask, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("_tasks/%s", taskID),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get task",
+			"task", taskID,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	task := &estypes.ReIndexTask{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), task); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse task response body",
+			"task", taskID)
+	}
+	return task, nil
+}
+
+// CancelTask cancels a task, tasks that already completed are ignored
+func (ec *esClient) CancelTask(taskID string) error {
+	payload := &EsRequest{
+		Method: http.MethodPost,
+		URI:    fmt.Sprintf("_tasks/%s/_cancel", taskID),
+	}
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error == nil && (payload.StatusCode == http.StatusNotFound || payload.StatusCode == http.StatusOK) {
+		return nil
+	}
+
+	return ec.errorCtx().New("failed to cancel task",
+		"task", taskID,
+		"response_error", payload.Error,
+		"response_status", payload.StatusCode,
+		"response_body", payload.ResponseBody)
+}
+
 func (ec *esClient) UpdateAlias(actions estypes.AliasActions) error {
 	body, err := utils.ToJSON(actions)
 	if err != nil {
@@ -223,6 +404,37 @@ func (ec *esClient) ListIndicesForAlias(aliasPattern string) ([]string, error) {
 	return response, nil
 }
 
+// GetIndexAliases returns the definitions of the aliases of the index by their name
+func (ec *esClient) GetIndexAliases(index string) (map[string]estypes.AliasDefinition, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    fmt.Sprintf("%s/_alias", index),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get aliases of index",
+			"index", index,
+			"response_error", payload.Error,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := map[string]struct {
+		Aliases map[string]estypes.AliasDefinition `json:"aliases"`
+	}{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse aliases of index",
+			"index", index)
+	}
+
+	aliases := res[index].Aliases
+	if aliases == nil {
+		aliases = map[string]estypes.AliasDefinition{}
+	}
+	return aliases, nil
+}
+
 func (ec *esClient) AddAliasForOldIndices() bool {
 	// get .operations.*/_alias
 	// get project.*/_alias
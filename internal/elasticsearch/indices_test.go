@@ -1,6 +1,7 @@
 package elasticsearch_test
 
 import (
+	"reflect"
 	"testing"
 
 	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
@@ -349,3 +350,29 @@ func TestAliasOperationsNotNeeded(t *testing.T) {
 		t.Errorf("Expected creation of aliases to succeed")
 	}
 }
+
+func TestGetIndexVersions(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			"*/_settings/index.version.created?flat_settings=true": {
+				{
+					StatusCode: 200,
+					Body: `{
+                      "app-000001": {"settings": {"index.version.created": "6080199"}},
+                      "project.test": {"settings": {"index.version.created": "5061699"}}
+                    }`,
+				},
+			},
+		},
+	)
+	esClient := testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fakeClient, chatter)
+
+	versions, err := esClient.GetIndexVersions("*")
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	want := map[string]string{"app-000001": "6.8.1", "project.test": "5.6.16"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("got %v, want %v", versions, want)
+	}
+}
@@ -0,0 +1,74 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"net/http"
+
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+)
+
+// SetRemoteClusters updates the persistent settings of the remote clusters, the remote clusters
+// mapped to nil are removed
+func (ec *esClient) SetRemoteClusters(remotes map[string]*estypes.RemoteClusterSettings) error {
+	settings := map[string]estypes.RemoteClusterSettings{}
+	for name, remote := range remotes {
+		if remote == nil {
+			remote = &estypes.RemoteClusterSettings{}
+		}
+		settings[name] = *remote
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"persistent": map[string]interface{}{
+			"cluster": map[string]interface{}{
+				"remote": settings,
+			},
+		},
+	})
+	if err != nil {
+		return ec.errorCtx().Wrap(err, "failed to encode remote cluster settings")
+	}
+
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         "_cluster/settings",
+		RequestBody: string(body),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return ec.errorCtx().Wrap(payload.Error, "failed to set remote clusters")
+	}
+	if payload.StatusCode != http.StatusOK {
+		return ec.errorCtx().New("failed to set remote clusters",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	return nil
+}
+
+// GetRemoteClusterInfo returns the connections of the node answering to the remote clusters
+func (ec *esClient) GetRemoteClusterInfo() (estypes.RemoteInfoResponse, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_remote/info",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return nil, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return nil, ec.errorCtx().New("failed to get remote cluster info",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := estypes.RemoteInfoResponse{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return nil, ec.errorCtx().Wrap(err, "failed to decode raw response body into `estypes.RemoteInfoResponse`")
+	}
+
+	return res, nil
+}
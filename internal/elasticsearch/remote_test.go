@@ -0,0 +1,60 @@
+package elasticsearch_test
+
+import (
+	"testing"
+
+	"github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestSetRemoteClusters(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/settings": {
+			{
+				StatusCode: 200,
+				Body:       `{"acknowledged": true}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	skip := true
+	err := esClient.SetRemoteClusters(map[string]*elasticsearch.RemoteClusterSettings{
+		"east": {Seeds: []string{"east-cluster.logging.svc:9300"}, SkipUnavailable: &skip},
+		"west": nil,
+	})
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+
+	req, found := chatter.GetRequest("_cluster/settings")
+	if !found {
+		t.Fatalf("expected request to _cluster/settings")
+	}
+	want := `{"persistent":{"cluster":{"remote":{"east":{"seeds":["east-cluster.logging.svc:9300"],"skip_unavailable":true},"west":{"seeds":null,"skip_unavailable":null}}}}}`
+	if req.Body != want {
+		t.Errorf("got body %s, want %s", req.Body, want)
+	}
+}
+
+func TestGetRemoteClusterInfo(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_remote/info": {
+			{
+				StatusCode: 200,
+				Body: `{"east": {"seeds": ["east-cluster.logging.svc:9300"], "http_addresses": [], "connected": true,
+					"num_nodes_connected": 3, "max_connections_per_cluster": 3, "skip_unavailable": true}}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	info, err := esClient.GetRemoteClusterInfo()
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	east := info["east"]
+	if !east.Connected || east.NumNodesConnected != 3 || !east.SkipUnavailable {
+		t.Errorf("got remote cluster info %v", east)
+	}
+}
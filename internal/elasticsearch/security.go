@@ -0,0 +1,70 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+const securityAPI = "_opendistro/_security/api"
+
+func (ec *esClient) PutSecurityRole(name string, role *estypes.SecurityRole) error {
+	return ec.putSecurityResource("roles", name, role)
+}
+
+func (ec *esClient) DeleteSecurityRole(name string) error {
+	return ec.deleteSecurityResource("roles", name)
+}
+
+func (ec *esClient) PutSecurityRoleMapping(name string, mapping *estypes.SecurityRoleMapping) error {
+	return ec.putSecurityResource("rolesmapping", name, mapping)
+}
+
+func (ec *esClient) DeleteSecurityRoleMapping(name string) error {
+	return ec.deleteSecurityResource("rolesmapping", name)
+}
+
+func (ec *esClient) putSecurityResource(kind, name string, resource interface{}) error {
+	body, err := utils.ToJSON(resource)
+	if err != nil {
+		return err
+	}
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         fmt.Sprintf("%s/%s/%s", securityAPI, kind, name),
+		RequestBody: body,
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil || (payload.StatusCode != 200 && payload.StatusCode != 201) {
+		return ec.errorCtx().New("failed to put security resource",
+			"kind", kind,
+			"name", name,
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody,
+			"response_error", payload.Error,
+		)
+	}
+	return nil
+}
+
+func (ec *esClient) deleteSecurityResource(kind, name string) error {
+	payload := &EsRequest{
+		Method: http.MethodDelete,
+		URI:    fmt.Sprintf("%s/%s/%s", securityAPI, kind, name),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error == nil && (payload.StatusCode == 404 || payload.StatusCode < 300) {
+		return nil
+	}
+
+	return ec.errorCtx().New("failed to delete security resource",
+		"kind", kind,
+		"name", name,
+		"response_status", payload.StatusCode,
+		"response_body", payload.ResponseBody,
+		"response_error", payload.Error)
+}
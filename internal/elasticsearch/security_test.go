@@ -0,0 +1,100 @@
+package elasticsearch_test
+
+import (
+	"net/http"
+	"testing"
+
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestPutSecurityRole(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		wantErr    bool
+	}{
+		{desc: "created", statusCode: http.StatusCreated},
+		{desc: "updated", statusCode: http.StatusOK},
+		{desc: "rejected", statusCode: http.StatusBadRequest, wantErr: true},
+	}
+
+	role := &estypes.SecurityRole{
+		Cluster: []string{},
+		Indices: map[string]map[string][]string{
+			"app-team-a-*": {"*": {"READ"}},
+		},
+	}
+
+	for _, test := range tests {
+		uri := "_opendistro/_security/api/roles/team-a-reader"
+		chatter := testhelpers.NewFakeElasticsearchChatter(
+			map[string]testhelpers.FakeElasticsearchResponses{
+				uri: {{StatusCode: test.statusCode, Body: "{}"}},
+			})
+		esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+		err := esClient.PutSecurityRole("team-a-reader", role)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got err %v, want err %t", test.desc, err, test.wantErr)
+		}
+
+		req, _ := chatter.GetRequest(uri)
+		if req.Method != http.MethodPut {
+			t.Errorf("%s: got method %s, want PUT", test.desc, req.Method)
+		}
+		if want := testhelpers.NormalizeJSON(`{"cluster":[],"indices":{"app-team-a-*":{"*":["READ"]}}}`); testhelpers.NormalizeJSON(req.Body) != want {
+			t.Errorf("got body %s, want %s", req.Body, want)
+		}
+	}
+}
+
+func TestPutSecurityRoleMapping(t *testing.T) {
+	uri := "_opendistro/_security/api/rolesmapping/team-a-reader"
+	chatter := testhelpers.NewFakeElasticsearchChatter(
+		map[string]testhelpers.FakeElasticsearchResponses{
+			uri: {{StatusCode: http.StatusCreated, Body: "{}"}},
+		})
+	esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+	mapping := &estypes.SecurityRoleMapping{
+		Users:        []string{"system:serviceaccount:team-a:exporter"},
+		BackendRoles: []string{"team-a"},
+	}
+	if err := esClient.PutSecurityRoleMapping("team-a-reader", mapping); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	req, _ := chatter.GetRequest(uri)
+	if want := testhelpers.NormalizeJSON(`{"users":["system:serviceaccount:team-a:exporter"],"backendroles":["team-a"]}`); testhelpers.NormalizeJSON(req.Body) != want {
+		t.Errorf("got body %s, want %s", req.Body, want)
+	}
+}
+
+func TestDeleteSecurityRole(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		wantErr    bool
+	}{
+		{desc: "deleted", statusCode: http.StatusOK},
+		{desc: "already gone", statusCode: http.StatusNotFound},
+		{desc: "failed", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, test := range tests {
+		chatter := testhelpers.NewFakeElasticsearchChatter(
+			map[string]testhelpers.FakeElasticsearchResponses{
+				"_opendistro/_security/api/roles/team-a-reader":        {{StatusCode: test.statusCode, Body: "{}"}},
+				"_opendistro/_security/api/rolesmapping/team-a-reader": {{StatusCode: test.statusCode, Body: "{}"}},
+			})
+		esClient := testhelpers.NewFakeElasticsearchClient(cluster, namespace, k8sClient, chatter)
+
+		if err := esClient.DeleteSecurityRole("team-a-reader"); (err != nil) != test.wantErr {
+			t.Errorf("%s: got err %v, want err %t", test.desc, err, test.wantErr)
+		}
+		if err := esClient.DeleteSecurityRoleMapping("team-a-reader"); (err != nil) != test.wantErr {
+			t.Errorf("%s: got err %v, want err %t", test.desc, err, test.wantErr)
+		}
+	}
+}
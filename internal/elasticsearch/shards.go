@@ -1,10 +1,13 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
 )
 
 func (ec *esClient) ClearTransientShardAllocation() (bool, error) {
@@ -75,3 +78,60 @@ func (ec *esClient) GetShardAllocation() (string, error) {
 
 	return allocationString, payload.Error
 }
+
+// SetShardAllocationExclusion excludes the given nodes from shard allocation so that
+// their shards are relocated onto the remaining nodes. An empty list clears the exclusion.
+func (ec *esClient) SetShardAllocationExclusion(nodeNames []string) (bool, error) {
+	value := "null"
+	if len(nodeNames) > 0 {
+		value = fmt.Sprintf("%q", strings.Join(nodeNames, ","))
+	}
+
+	payload := &EsRequest{
+		Method:      http.MethodPut,
+		URI:         "_cluster/settings",
+		RequestBody: fmt.Sprintf("{%q:{%q:%s}}", "persistent", "cluster.routing.allocation.exclude._name", value),
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+
+	acknowledged := false
+	if acknowledgedBool, ok := payload.ResponseBody["acknowledged"].(bool); ok {
+		acknowledged = acknowledgedBool
+	}
+	return payload.StatusCode == 200 && acknowledged, ec.errorCtx().Wrap(payload.Error, "failed to set shard allocation exclusion",
+		"nodes", nodeNames)
+}
+
+// GetNodeShardCount returns the number of shards that are allocated to or relocating away from the node
+func (ec *esClient) GetNodeShardCount(nodeName string) (int32, error) {
+	payload := &EsRequest{
+		Method: http.MethodGet,
+		URI:    "_cat/shards?h=node&format=json",
+	}
+
+	ec.fnSendEsRequest(ec.cluster, ec.namespace, payload, ec.k8sClient)
+	if payload.Error != nil {
+		return -1, payload.Error
+	}
+	if payload.StatusCode != http.StatusOK {
+		return -1, ec.errorCtx().New("failed to get shards",
+			"response_status", payload.StatusCode,
+			"response_body", payload.ResponseBody)
+	}
+
+	res := estypes.CatShardsResponses{}
+	if err := json.Unmarshal([]byte(payload.RawResponseBody), &res); err != nil {
+		return -1, ec.errorCtx().Wrap(err, "failed to parse _cat/shards response body")
+	}
+
+	count := int32(0)
+	for _, shard := range res {
+		// relocating shards are reported as "<source> -> <ip> <id> <target>"
+		if fields := strings.Fields(shard.Node); len(fields) > 0 && fields[0] == nodeName {
+			count++
+		}
+	}
+
+	return count, nil
+}
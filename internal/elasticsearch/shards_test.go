@@ -0,0 +1,56 @@
+package elasticsearch_test
+
+import (
+	"testing"
+
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestGetNodeShardCount(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cat/shards?h=node&format=json": {
+			{
+				StatusCode: 200,
+				Body:       `[{"node": "node1"}, {"node": "node2"}, {"node": "node1 -> 10.0.0.1 abc node2"}, {"node": null}]`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	got, err := esClient.GetNodeShardCount("node1")
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if got != 2 {
+		t.Errorf("got %d, want %d", got, 2)
+	}
+}
+
+func TestSetShardAllocationExclusion(t *testing.T) {
+	chatter := helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+		"_cluster/settings": {
+			{
+				StatusCode: 200,
+				Body:       `{"acknowledged": true}`,
+			},
+		},
+	})
+	esClient := helpers.NewFakeElasticsearchClient("elasticsearch", "test-namespace", fakeClient, chatter)
+
+	ok, err := esClient.SetShardAllocationExclusion([]string{"node1", "node2"})
+	if err != nil {
+		t.Errorf("got err: %s", err)
+	}
+	if !ok {
+		t.Errorf("expected exclusion to be acknowledged")
+	}
+
+	req, found := chatter.GetRequest("_cluster/settings")
+	if !found {
+		t.Fatalf("expected request to _cluster/settings")
+	}
+	want := `{"persistent":{"cluster.routing.allocation.exclude._name":"node1,node2"}}`
+	if req.Body != want {
+		t.Errorf("got body %s, want %s", req.Body, want)
+	}
+}
@@ -0,0 +1,125 @@
+package k8shandler
+
+import (
+	"github.com/ViaQ/logerr/kverrors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+// auditLogIndex is the index management mapping of the audit index, the security
+// plugin writes to its write alias
+const auditLogIndex = "security-audit"
+
+// auditCategories are all categories of the events recorded by the audit log
+var auditCategories = []string{
+	"AUTHENTICATED",
+	"BAD_HEADERS",
+	"FAILED_LOGIN",
+	"GRANTED_PRIVILEGES",
+	"MISSING_PRIVILEGES",
+	"OPENDISTRO_SECURITY_INDEX_ATTEMPT",
+	"SSL_EXCEPTION",
+}
+
+// auditLogConfig is used to render the audit settings of elasticsearch.yml
+type auditLogConfig struct {
+	Type               string
+	Index              string
+	IgnoreUsers        []string
+	DisabledCategories []string
+}
+
+// newAuditLogConfig returns the audit settings of the spec or nil if the audit log is disabled
+func newAuditLogConfig(spec *api.ElasticsearchAuditLogSpec) *auditLogConfig {
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	config := &auditLogConfig{
+		Type:        "internal_elasticsearch",
+		Index:       formatWriteAlias(api.IndexManagementPolicyMappingSpec{Name: auditLogIndex}),
+		IgnoreUsers: spec.IgnoreUsers,
+	}
+	if spec.Target == api.AuditLogTargetLog4j {
+		config.Type = "log4j"
+		config.Index = ""
+	}
+
+	// keep the defaults of the plugin unless categories are selected
+	if len(spec.IncludeCategories) == 0 && len(spec.ExcludeCategories) == 0 {
+		return config
+	}
+
+	disabled := sets.NewString()
+	if len(spec.IncludeCategories) > 0 {
+		disabled.Insert(auditCategories...)
+		for _, category := range spec.IncludeCategories {
+			disabled.Delete(string(category))
+		}
+	}
+	for _, category := range spec.ExcludeCategories {
+		disabled.Insert(string(category))
+	}
+
+	config.DisabledCategories = disabled.List()
+	if len(config.DisabledCategories) == 0 {
+		config.DisabledCategories = []string{"NONE"}
+	}
+	return config
+}
+
+// auditLogMapping returns the index management mapping of the audit index or nil if the
+// audit events are not written to an index
+func auditLogMapping(spec *api.ElasticsearchAuditLogSpec) *api.IndexManagementPolicyMappingSpec {
+	if spec == nil || !spec.Enabled || spec.Target == api.AuditLogTargetLog4j {
+		return nil
+	}
+
+	return &api.IndexManagementPolicyMappingSpec{
+		Name:      auditLogIndex,
+		PolicyRef: spec.PolicyRef,
+	}
+}
+
+// validateAuditLog ensures the audit log settings are consistent
+func validateAuditLog(dpl *api.Elasticsearch) error {
+	spec := dpl.Spec.AuditLog
+	if spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	for _, category := range spec.IncludeCategories {
+		for _, excluded := range spec.ExcludeCategories {
+			if category == excluded {
+				return kverrors.New("audit category is both included and excluded",
+					"category", category)
+			}
+		}
+	}
+
+	if spec.Target == api.AuditLogTargetLog4j {
+		if spec.PolicyRef != "" {
+			return kverrors.New("audit log policy requires the Index target",
+				"policy", spec.PolicyRef)
+		}
+		return nil
+	}
+
+	var policies api.PolicyMap
+	if dpl.Spec.IndexManagement != nil {
+		for _, mapping := range dpl.Spec.IndexManagement.Mappings {
+			if mapping.Name == auditLogIndex {
+				return kverrors.New("index management mapping is reserved for the audit log",
+					"mapping", auditLogIndex)
+			}
+		}
+		policies = dpl.Spec.IndexManagement.PolicyMap()
+	}
+	if spec.PolicyRef != "" && !policies.HasPolicy(spec.PolicyRef) {
+		return kverrors.New("audit log policy is not defined in the index management policies",
+			"policy", spec.PolicyRef)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package k8shandler
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestNewAuditLogConfig(t *testing.T) {
+	tests := []struct {
+		desc string
+		spec *loggingv1.ElasticsearchAuditLogSpec
+		want *auditLogConfig
+	}{
+		{desc: "not configured"},
+		{desc: "disabled", spec: &loggingv1.ElasticsearchAuditLogSpec{}},
+		{
+			desc: "plugin defaults",
+			spec: &loggingv1.ElasticsearchAuditLogSpec{Enabled: true},
+			want: &auditLogConfig{Type: "internal_elasticsearch", Index: "security-audit-write"},
+		},
+		{
+			desc: "log4j",
+			spec: &loggingv1.ElasticsearchAuditLogSpec{Enabled: true, Target: loggingv1.AuditLogTargetLog4j},
+			want: &auditLogConfig{Type: "log4j"},
+		},
+		{
+			desc: "included categories",
+			spec: &loggingv1.ElasticsearchAuditLogSpec{
+				Enabled:           true,
+				IncludeCategories: []loggingv1.AuditCategory{"AUTHENTICATED", "BAD_HEADERS", "FAILED_LOGIN", "GRANTED_PRIVILEGES", "MISSING_PRIVILEGES"},
+			},
+			want: &auditLogConfig{
+				Type:               "internal_elasticsearch",
+				Index:              "security-audit-write",
+				DisabledCategories: []string{"OPENDISTRO_SECURITY_INDEX_ATTEMPT", "SSL_EXCEPTION"},
+			},
+		},
+		{
+			desc: "excluded categories",
+			spec: &loggingv1.ElasticsearchAuditLogSpec{
+				Enabled:           true,
+				ExcludeCategories: []loggingv1.AuditCategory{"SSL_EXCEPTION"},
+				IgnoreUsers:       []string{"kibanaserver"},
+			},
+			want: &auditLogConfig{
+				Type:               "internal_elasticsearch",
+				Index:              "security-audit-write",
+				IgnoreUsers:        []string{"kibanaserver"},
+				DisabledCategories: []string{"SSL_EXCEPTION"},
+			},
+		},
+		{
+			desc: "all categories",
+			spec: &loggingv1.ElasticsearchAuditLogSpec{
+				Enabled:           true,
+				IncludeCategories: []loggingv1.AuditCategory{"AUTHENTICATED", "BAD_HEADERS", "FAILED_LOGIN", "GRANTED_PRIVILEGES", "MISSING_PRIVILEGES", "OPENDISTRO_SECURITY_INDEX_ATTEMPT", "SSL_EXCEPTION"},
+			},
+			want: &auditLogConfig{
+				Type:               "internal_elasticsearch",
+				Index:              "security-audit-write",
+				DisabledCategories: []string{"NONE"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if got := newAuditLogConfig(test.spec); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.desc, got, test.want)
+		}
+	}
+}
+
+func TestRenderEsYmlAuditLog(t *testing.T) {
+	spec := &loggingv1.ElasticsearchAuditLogSpec{
+		Enabled:           true,
+		ExcludeCategories: []loggingv1.AuditCategory{"AUTHENTICATED", "GRANTED_PRIVILEGES"},
+		IgnoreUsers:       []string{"kibanaserver", "system:serviceaccount:openshift-logging:collector"},
+	}
+
+	result := &bytes.Buffer{}
+	if err := renderEsYml(result, "", "my.unicast.host", "2", "3", "false", spec, nil, false); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	config := struct {
+		Security struct {
+			Audit struct {
+				Type        string   `yaml:"type"`
+				IgnoreUsers []string `yaml:"ignore_users"`
+				Config      struct {
+					Index                       string   `yaml:"index"`
+					DisabledRestCategories      []string `yaml:"disabled_rest_categories"`
+					DisabledTransportCategories []string `yaml:"disabled_transport_categories"`
+				} `yaml:"config"`
+			} `yaml:"audit"`
+		} `yaml:"opendistro_security"`
+	}{}
+	if err := yaml.Unmarshal(result.Bytes(), &config); err != nil {
+		t.Fatalf("expected a valid elasticsearch.yml: %s", err)
+	}
+
+	audit := config.Security.Audit
+	if audit.Type != "internal_elasticsearch" || audit.Config.Index != "security-audit-write" {
+		t.Errorf("got audit type %q index %q", audit.Type, audit.Config.Index)
+	}
+	if !reflect.DeepEqual(audit.IgnoreUsers, spec.IgnoreUsers) {
+		t.Errorf("got ignored users %v, want %v", audit.IgnoreUsers, spec.IgnoreUsers)
+	}
+	want := []string{"AUTHENTICATED", "GRANTED_PRIVILEGES"}
+	if !reflect.DeepEqual(audit.Config.DisabledRestCategories, want) || !reflect.DeepEqual(audit.Config.DisabledTransportCategories, want) {
+		t.Errorf("got disabled categories %v and %v, want %v", audit.Config.DisabledRestCategories, audit.Config.DisabledTransportCategories, want)
+	}
+}
+
+func TestValidateAuditLog(t *testing.T) {
+	policies := &loggingv1.IndexManagementSpec{
+		Policies: []loggingv1.IndexManagementPolicySpec{{Name: "audit-policy"}},
+	}
+
+	tests := []struct {
+		desc            string
+		spec            loggingv1.ElasticsearchAuditLogSpec
+		indexManagement *loggingv1.IndexManagementSpec
+		valid           bool
+	}{
+		{desc: "disabled", spec: loggingv1.ElasticsearchAuditLogSpec{PolicyRef: "missing"}, valid: true},
+		{desc: "without policy", spec: loggingv1.ElasticsearchAuditLogSpec{Enabled: true}, valid: true},
+		{
+			desc:            "with policy",
+			spec:            loggingv1.ElasticsearchAuditLogSpec{Enabled: true, PolicyRef: "audit-policy"},
+			indexManagement: policies,
+			valid:           true,
+		},
+		{desc: "unknown policy", spec: loggingv1.ElasticsearchAuditLogSpec{Enabled: true, PolicyRef: "audit-policy"}},
+		{
+			desc:            "policy with log4j",
+			spec:            loggingv1.ElasticsearchAuditLogSpec{Enabled: true, PolicyRef: "audit-policy", Target: loggingv1.AuditLogTargetLog4j},
+			indexManagement: policies,
+		},
+		{
+			desc: "included and excluded",
+			spec: loggingv1.ElasticsearchAuditLogSpec{
+				Enabled:           true,
+				IncludeCategories: []loggingv1.AuditCategory{"FAILED_LOGIN"},
+				ExcludeCategories: []loggingv1.AuditCategory{"FAILED_LOGIN"},
+			},
+		},
+		{
+			desc: "reserved mapping",
+			spec: loggingv1.ElasticsearchAuditLogSpec{Enabled: true},
+			indexManagement: &loggingv1.IndexManagementSpec{
+				Mappings: []loggingv1.IndexManagementPolicyMappingSpec{{Name: auditLogIndex}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		spec := test.spec
+		dpl := &loggingv1.Elasticsearch{
+			Spec: loggingv1.ElasticsearchSpec{
+				AuditLog:        &spec,
+				IndexManagement: test.indexManagement,
+			},
+		}
+		if err := validateAuditLog(dpl); (err == nil) != test.valid {
+			t.Errorf("%s: got err %v, want valid %t", test.desc, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package k8shandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/certificates"
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
+)
+
+const (
+	caCommonName = "openshift-cluster-logging-signer"
+	caCertKey    = "ca.crt"
+	caKeyKey     = "ca.key"
+	adminCAKey   = "admin-ca"
+
+	// certRenewBefore is how long before their expiry generated certificates are replaced
+	certRenewBefore = 60 * 24 * time.Hour
+	// certExpiryWarning is how long before their expiry certificates are reported as expiring soon
+	certExpiryWarning = 30 * 24 * time.Hour
+)
+
+// certificateComponent is a certificate stored in the secret of the cluster
+type certificateComponent struct {
+	certKey string
+	keyKey  string
+	request certificates.Request
+}
+
+func (er *ElasticsearchRequest) isCertificateGenerationEnabled() bool {
+	certs := er.cluster.Spec.Certificates
+	return certs != nil && certs.Generate
+}
+
+// caSecretName is the secret holding the certificate authority of the cluster. It is kept
+// apart from the secret mounted into the pods so the CA key never leaves the operator.
+func caSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-ca", clusterName)
+}
+
+// certificateComponents lists the certificates the pods and the operator expect in the secret
+// of the cluster, see constants.ExpectedSecretKeys
+func (er *ElasticsearchRequest) certificateComponents() []certificateComponent {
+	name := er.cluster.Name
+	namespace := er.cluster.Namespace
+	localhost := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")}
+
+	serviceNames := func(services ...string) []string {
+		names := []string{"localhost"}
+		for _, svc := range services {
+			names = append(names,
+				svc,
+				fmt.Sprintf("%s.%s.svc", svc, namespace),
+				fmt.Sprintf("%s.%s.svc.cluster.local", svc, namespace),
+			)
+		}
+		return names
+	}
+
+	clusterService := fmt.Sprintf("%s-cluster", name)
+	transportNames := append(serviceNames(name, clusterService),
+		// the pods are addressed by their names below the cluster service
+		fmt.Sprintf("*.%s.%s.svc", clusterService, namespace),
+		fmt.Sprintf("*.%s.%s.svc.cluster.local", clusterService, namespace),
+	)
+
+	return []certificateComponent{
+		{
+			certKey: "admin-cert",
+			keyKey:  "admin-key",
+			request: certificates.Request{
+				CommonName: "system.admin",
+				Validity:   certificates.CertValidity,
+			},
+		},
+		{
+			certKey: "elasticsearch.crt",
+			keyKey:  "elasticsearch.key",
+			request: certificates.Request{
+				CommonName: "elasticsearch",
+				DNSNames:   transportNames,
+				IPs:        localhost,
+				Node:       true,
+				Server:     true,
+				Validity:   certificates.CertValidity,
+			},
+		},
+		{
+			certKey: "logging-es.crt",
+			keyKey:  "logging-es.key",
+			request: certificates.Request{
+				CommonName: "logging-es",
+				DNSNames:   serviceNames(name),
+				IPs:        localhost,
+				Server:     true,
+				Validity:   certificates.CertValidity,
+			},
+		},
+	}
+}
+
+// CreateOrUpdateCertificates generates the certificate authority and the certificates of the
+// cluster when the operator manages them. Certificates that are missing, invalid, not signed
+// by the current CA or about to expire are replaced.
+func (er *ElasticsearchRequest) CreateOrUpdateCertificates() error {
+	if !er.isCertificateGenerationEnabled() {
+		return nil
+	}
+
+	ca, err := er.getOrCreateCA()
+	if err != nil {
+		return err
+	}
+
+	name := er.cluster.Name
+	namespace := er.cluster.Namespace
+
+	errCtx := kverrors.NewContext("secret", name,
+		"cluster", name,
+		"namespace", namespace,
+	)
+
+	secret, err := getSecret(name, namespace, er.client)
+	if err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return errCtx.Wrap(err, "failed to get secret")
+	}
+	exists := err == nil
+
+	data := map[string][]byte{}
+	for key, value := range secret.Data {
+		data[key] = value
+	}
+
+	changed := false
+	for _, component := range er.certificateComponents() {
+		current, err := certificates.ParseKeyPair(data[component.certKey], data[component.keyKey])
+		if err == nil && current.IsSignedBy(ca) && time.Until(current.Cert.NotAfter) > certRenewBefore {
+			continue
+		}
+
+		er.L().Info("Generating certificate", "certificate", component.certKey)
+		kp, err := ca.Sign(component.request)
+		if err != nil {
+			return errCtx.Wrap(err, "failed to generate certificate", "certificate", component.certKey)
+		}
+
+		data[component.certKey] = kp.CertPEM()
+		data[component.keyKey] = kp.KeyPEM()
+		changed = true
+	}
+
+	if !bytes.Equal(data[adminCAKey], ca.CertPEM()) {
+		data[adminCAKey] = ca.CertPEM()
+		changed = true
+	}
+
+	if !exists {
+		secret = newSecret(name, namespace, data)
+		er.cluster.AddOwnerRefTo(secret)
+
+		if err := er.client.Create(context.TODO(), secret); err != nil {
+			return errCtx.Wrap(err, "failed to create secret")
+		}
+		return nil
+	}
+
+	if !changed {
+		return nil
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := getSecret(name, namespace, er.client)
+		if err != nil {
+			return err
+		}
+
+		if current.Data == nil {
+			current.Data = map[string][]byte{}
+		}
+		for key, value := range data {
+			current.Data[key] = value
+		}
+
+		return er.client.Update(context.TODO(), current)
+	})
+	if err != nil {
+		return errCtx.Wrap(err, "failed to update secret")
+	}
+
+	return nil
+}
+
+// getOrCreateCA reads the certificate authority of the cluster or creates a new one
+// if it does not exist yet, can't be used anymore or is about to expire
+func (er *ElasticsearchRequest) getOrCreateCA() (*certificates.KeyPair, error) {
+	name := caSecretName(er.cluster.Name)
+	namespace := er.cluster.Namespace
+
+	errCtx := kverrors.NewContext("secret", name,
+		"cluster", er.cluster.Name,
+		"namespace", namespace,
+	)
+
+	secret, err := getSecret(name, namespace, er.client)
+	if err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return nil, errCtx.Wrap(err, "failed to get CA secret")
+	}
+	exists := err == nil
+
+	if exists {
+		ca, err := certificates.ParseKeyPair(secret.Data[caCertKey], secret.Data[caKeyKey])
+		if err == nil && ca.IsSignedBy(ca) && time.Until(ca.Cert.NotAfter) > certRenewBefore {
+			return ca, nil
+		}
+		er.L().Info("Replacing unusable certificate authority", "secret", name, "cause", err)
+	}
+
+	er.L().Info("Generating certificate authority", "secret", name)
+	ca, err := certificates.NewCA(caCommonName, certificates.CAValidity)
+	if err != nil {
+		return nil, errCtx.Wrap(err, "failed to generate certificate authority")
+	}
+
+	data := map[string][]byte{
+		caCertKey: ca.CertPEM(),
+		caKeyKey:  ca.KeyPEM(),
+	}
+
+	if !exists {
+		secret = newSecret(name, namespace, data)
+		er.cluster.AddOwnerRefTo(secret)
+
+		if err := er.client.Create(context.TODO(), secret); err != nil {
+			return nil, errCtx.Wrap(err, "failed to create CA secret")
+		}
+		return ca, nil
+	}
+
+	secret.Data = data
+	if err := er.client.Update(context.TODO(), secret); err != nil {
+		return nil, errCtx.Wrap(err, "failed to update CA secret")
+	}
+
+	return ca, nil
+}
+
+// updateCertificateStatus reports the expiry of every certificate in the secret of the cluster
+// in the status and as metrics, and raises a condition for the ones about to expire
+func (er *ElasticsearchRequest) updateCertificateStatus(status *api.ElasticsearchStatus) {
+	name := er.cluster.Name
+	namespace := er.cluster.Namespace
+	state := er.state()
+
+	certs := []api.ElasticsearchCertificateStatus{}
+	if secret, err := getSecret(name, namespace, er.client); err == nil {
+		// parsing every certificate on each status update is wasted while the secret is unchanged
+		if sum := secretDataSum(secret.Data); sum != state.certificatesSum {
+			state.certificates = certificateStatuses(secret.Data)
+			state.certificatesSum = sum
+		}
+		certs = append(certs, state.certificates...)
+	}
+
+	keys := []string{}
+	expiring := []string{}
+	for _, cert := range certs {
+		keys = append(keys, cert.Key)
+		metrics.SetCertificateExpiry(namespace, name, cert.Key, cert.NotAfter.Time)
+
+		if time.Until(cert.NotAfter.Time) < certExpiryWarning {
+			expiring = append(expiring, cert.Key)
+		}
+	}
+
+	for _, key := range state.certificateKeys {
+		if !sliceContainsString(keys, key) {
+			metrics.DeleteCertificateExpiry(namespace, name, key)
+		}
+	}
+	state.certificateKeys = keys
+
+	if len(certs) == 0 {
+		status.Certificates = nil
+	} else {
+		status.Certificates = certs
+	}
+	updateCertificatesExpiringSoonCondition(status, expiring)
+}
+
+// certificateStatuses parses every certificate in the secret data, keys that don't hold
+// a PEM encoded certificate are skipped
+func certificateStatuses(data map[string][]byte) []api.ElasticsearchCertificateStatus {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	certs := []api.ElasticsearchCertificateStatus{}
+	for _, key := range keys {
+		cert, err := certificates.ParseCertificate(data[key])
+		if err != nil {
+			continue
+		}
+
+		certs = append(certs, api.ElasticsearchCertificateStatus{
+			Key:      key,
+			Subject:  cert.Subject.String(),
+			NotAfter: metav1.NewTime(cert.NotAfter),
+		})
+	}
+
+	return certs
+}
+
+// secretDataSum is the hash of every key and value of the secret data
+func secretDataSum(data map[string][]byte) [32]byte {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write(data[key])
+	}
+
+	sum := [32]byte{}
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func updateCertificatesExpiringSoonCondition(status *api.ElasticsearchStatus, expiring []string) bool {
+	if len(expiring) == 0 {
+		return updateESNodeCondition(status, &api.ClusterCondition{
+			Type:   api.CertificatesExpiringSoon,
+			Status: v1.ConditionFalse,
+		})
+	}
+
+	return updateESNodeCondition(status, &api.ClusterCondition{
+		Type:    api.CertificatesExpiringSoon,
+		Status:  v1.ConditionTrue,
+		Reason:  "CertificatesExpiring",
+		Message: fmt.Sprintf("Certificates expire within %d days: [%s]", int(certExpiryWarning.Hours()/24), strings.Join(expiring, ", ")),
+	})
+}
+
+func newSecret(secretName, namespace string, data map[string][]byte) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+}
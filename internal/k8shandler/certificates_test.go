@@ -0,0 +1,221 @@
+package k8shandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/certificates"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+)
+
+func init() {
+	// keep the tests fast
+	certificates.KeySize = 1024
+}
+
+func newCertificatesRequest(generate bool, objs ...runtime.Object) *ElasticsearchRequest {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchSpec{
+			Certificates: &loggingv1.ElasticsearchCertificatesSpec{Generate: generate},
+		},
+	}
+
+	return &ElasticsearchRequest{
+		client:  fake.NewFakeClient(objs...),
+		cluster: cluster,
+	}
+}
+
+func TestCreateOrUpdateCertificatesGeneratesSecret(t *testing.T) {
+	er := newCertificatesRequest(true)
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if ok, missing := er.hasRequiredSecrets(); !ok {
+		t.Fatalf("expected all required secrets, got: %s", missing)
+	}
+
+	caSecret := &v1.Secret{}
+	key := types.NamespacedName{Name: "elasticsearch-ca", Namespace: "openshift-logging"}
+	if err := er.client.Get(context.TODO(), key, caSecret); err != nil {
+		t.Fatalf("expected CA secret: %s", err)
+	}
+
+	ca, err := certificates.ParseKeyPair(caSecret.Data[caCertKey], caSecret.Data[caKeyKey])
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	secret, _ := getSecret("elasticsearch", "openshift-logging", er.client)
+	transport, err := certificates.ParseKeyPair(secret.Data["elasticsearch.crt"], secret.Data["elasticsearch.key"])
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if !transport.IsSignedBy(ca) {
+		t.Errorf("expected transport certificate to be signed by the CA")
+	}
+
+	for _, name := range []string{
+		"elasticsearch",
+		"elasticsearch-cluster",
+		"elasticsearch-cluster.openshift-logging.svc",
+		"elasticsearch-cdm-1-deadbeef-1.elasticsearch-cluster.openshift-logging.svc",
+	} {
+		if err := transport.Cert.VerifyHostname(name); err != nil {
+			t.Errorf("expected transport certificate to be valid for %q: %s", name, err)
+		}
+	}
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	current, _ := getSecret("elasticsearch", "openshift-logging", er.client)
+	if !reflect.DeepEqual(current.Data, secret.Data) {
+		t.Errorf("expected valid certificates to be kept")
+	}
+}
+
+func TestCreateOrUpdateCertificatesReplacesInvalidKeys(t *testing.T) {
+	secret := newSecret("elasticsearch", "openshift-logging", map[string][]byte{
+		"admin-cert": []byte("not a certificate"),
+		"other":      []byte("kept"),
+	})
+	er := newCertificatesRequest(true, secret)
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	current, _ := getSecret("elasticsearch", "openshift-logging", er.client)
+	for _, key := range constants.ExpectedSecretKeys {
+		if len(current.Data[key]) == 0 {
+			t.Errorf("expected key %q to be generated", key)
+		}
+	}
+	if string(current.Data["other"]) != "kept" {
+		t.Errorf("expected unrelated keys to be kept")
+	}
+}
+
+func TestCreateOrUpdateCertificatesDisabled(t *testing.T) {
+	er := newCertificatesRequest(false)
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if ok, _ := er.hasRequiredSecrets(); ok {
+		t.Errorf("expected no secret to be generated")
+	}
+}
+
+func TestCreateOrUpdateCertificatesRotatesExpiringHTTPCertificate(t *testing.T) {
+	er := newCertificatesRequest(true)
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	caSecret, _ := getSecret("elasticsearch-ca", "openshift-logging", er.client)
+	ca, err := certificates.ParseKeyPair(caSecret.Data[caCertKey], caSecret.Data[caKeyKey])
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	expiring, err := ca.Sign(certificates.Request{CommonName: "logging-es", Server: true, Validity: 10 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	secret, _ := getSecret("elasticsearch", "openshift-logging", er.client)
+	secret.Data["logging-es.crt"] = expiring.CertPEM()
+	secret.Data["logging-es.key"] = expiring.KeyPEM()
+	if err := er.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	status := &loggingv1.ElasticsearchStatus{}
+	er.updateCertificateStatus(status)
+	if !containsClusterCondition(loggingv1.CertificatesExpiringSoon, v1.ConditionTrue, status) {
+		t.Errorf("expected %s condition, got %v", loggingv1.CertificatesExpiringSoon, status.Conditions)
+	}
+
+	secretHash := getSecretDataHash("elasticsearch", "openshift-logging", er.client)
+	httpHash := getHTTPCertHash("elasticsearch", "openshift-logging", er.client)
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if got := getHTTPCertHash("elasticsearch", "openshift-logging", er.client); got == httpHash {
+		t.Errorf("expected the expiring HTTP certificate to be replaced")
+	}
+	if got := getSecretDataHash("elasticsearch", "openshift-logging", er.client); got != secretHash {
+		t.Errorf("expected the rotation not to require a full cluster restart")
+	}
+
+	er.updateCertificateStatus(status)
+	if containsClusterCondition(loggingv1.CertificatesExpiringSoon, v1.ConditionTrue, status) {
+		t.Errorf("expected %s condition to be cleared, got %v", loggingv1.CertificatesExpiringSoon, status.Conditions)
+	}
+
+	keys := []string{}
+	for _, cert := range status.Certificates {
+		keys = append(keys, cert.Key)
+	}
+	want := []string{"admin-ca", "admin-cert", "elasticsearch.crt", "logging-es.crt"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("got certificates %v, want %v", keys, want)
+	}
+}
+
+func TestUpdateCertificateStatusParsesChangedSecretOnly(t *testing.T) {
+	er := newCertificatesRequest(true)
+	defer FlushNodes("elasticsearch", "openshift-logging")
+
+	if err := er.CreateOrUpdateCertificates(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	secret, _ := getSecret("elasticsearch", "openshift-logging", er.client)
+	cached := []loggingv1.ElasticsearchCertificateStatus{{Key: "cached"}}
+	state := er.state()
+	state.certificates = cached
+	state.certificatesSum = secretDataSum(secret.Data)
+
+	status := &loggingv1.ElasticsearchStatus{}
+	er.updateCertificateStatus(status)
+	if !reflect.DeepEqual(status.Certificates, cached) {
+		t.Errorf("expected the certificates of the unchanged secret not to be parsed again, got %v", status.Certificates)
+	}
+
+	delete(secret.Data, "admin-ca")
+	if err := er.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	er.updateCertificateStatus(status)
+	if len(status.Certificates) == 0 {
+		t.Errorf("expected the certificates of the changed secret to be reported")
+	}
+	for _, cert := range status.Certificates {
+		if cert.Key == "cached" || cert.Key == "admin-ca" {
+			t.Errorf("expected the certificates of the changed secret to be parsed, got %v", status.Certificates)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package k8shandler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+// caSecretKeys are the keys of the secret holding the certificate authority the nodes trust.
+// When they change, nodes restarted with the new secret can't talk to the ones still running
+// with the old one, so all nodes have to be restarted at once.
+var caSecretKeys = []string{adminCAKey}
+
+// observeSecretKeys remembers the keys of the secret once every node runs with its current
+// content, later changes to the secret are compared against them
+func (er *ElasticsearchRequest) observeSecretKeys() {
+	nodes := er.getNodes()
+	if len(nodes) == 0 {
+		return
+	}
+
+	name := er.cluster.Name
+	namespace := er.cluster.Namespace
+
+	hash := getSecretDataHash(name, namespace, er.client)
+	for _, node := range nodes {
+		if node.getSecretHash() != hash {
+			return
+		}
+	}
+
+	if hashes := getSecretKeyHashes(name, namespace, er.client); hashes != nil {
+		er.state().secretKeyHashes = hashes
+	}
+}
+
+// planCertRedeploy chooses how the nodes are restarted to pick up the current secret and
+// records the decision in the status. A rolling redeploy that is still in progress is only
+// replaced when the certificate authority changed in the meantime. Returns true if the
+// status changed.
+func (er *ElasticsearchRequest) planCertRedeploy(status *api.ElasticsearchStatus) bool {
+	current := status.CertificateRedeploy
+	inProgress := current != nil && current.CompletionTime == nil
+
+	if inProgress && current.Strategy == api.CertRedeployFullCluster {
+		return false
+	}
+
+	plan := er.newCertRedeployPlan()
+	if inProgress && plan.Strategy == api.CertRedeployRolling {
+		if strings.Join(current.ChangedKeys, ",") == strings.Join(plan.ChangedKeys, ",") {
+			return false
+		}
+		plan.StartTime = current.StartTime
+	}
+
+	er.L().Info("Scheduling certificate redeploy", "strategy", plan.Strategy, "changed_keys", plan.ChangedKeys)
+	status.CertificateRedeploy = plan
+	return true
+}
+
+func (er *ElasticsearchRequest) newCertRedeployPlan() *api.ElasticsearchCertRedeployStatus {
+	plan := &api.ElasticsearchCertRedeployStatus{
+		Strategy:  api.CertRedeployFullCluster,
+		StartTime: metav1.Now(),
+	}
+
+	previous := er.state().secretKeyHashes
+	if previous == nil {
+		plan.Reason = "The secret the nodes were started with is unknown"
+		return plan
+	}
+
+	current := getSecretKeyHashes(er.cluster.Name, er.cluster.Namespace, er.client)
+	plan.ChangedKeys = changedSecretKeys(previous, current)
+
+	for _, key := range plan.ChangedKeys {
+		if sliceContainsString(caSecretKeys, key) {
+			plan.Reason = fmt.Sprintf("The certificate authority %q changed, nodes with old and new certificates can't trust each other", key)
+			return plan
+		}
+	}
+
+	plan.Strategy = api.CertRedeployRolling
+	plan.Reason = "The certificate authority is unchanged, nodes are restarted one at a time"
+	return plan
+}
+
+// completeCertRedeploy marks the current redeploy as completed once no node waits for it
+// anymore. Returns true if the status changed.
+func (er *ElasticsearchRequest) completeCertRedeploy(status *api.ElasticsearchStatus) bool {
+	current := status.CertificateRedeploy
+	if current == nil || current.CompletionTime != nil {
+		return false
+	}
+
+	for _, node := range status.Nodes {
+		if node.UpgradeStatus.ScheduledForCertRedeploy == v1.ConditionTrue {
+			return false
+		}
+	}
+	if containsClusterCondition(api.Recovering, v1.ConditionTrue, status) {
+		return false
+	}
+
+	now := metav1.Now()
+	current.CompletionTime = &now
+	return true
+}
+
+// isRollingCertRedeploy returns true if the nodes scheduled for a cert redeploy are
+// restarted one at a time
+func isRollingCertRedeploy(status *api.ElasticsearchStatus) bool {
+	current := status.CertificateRedeploy
+	return current != nil && current.CompletionTime == nil && current.Strategy == api.CertRedeployRolling
+}
+
+// changedSecretKeys returns the sorted keys that were added, removed or changed
+func changedSecretKeys(previous, current map[string][32]byte) []string {
+	changed := []string{}
+	for key, hash := range current {
+		if old, ok := previous[key]; !ok || old != hash {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
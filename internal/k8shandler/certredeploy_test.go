@@ -0,0 +1,173 @@
+package k8shandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func newCertRedeployRequest(t *testing.T) *ElasticsearchRequest {
+	secret := newSecret("elasticsearch", "openshift-logging", map[string][]byte{
+		"admin-ca":       []byte("ca"),
+		"admin-cert":     []byte("cert"),
+		"logging-es.crt": []byte("http"),
+	})
+
+	er := &ElasticsearchRequest{
+		client: fake.NewFakeClient(secret),
+		cluster: &loggingv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "elasticsearch",
+				Namespace: "openshift-logging",
+			},
+		},
+	}
+
+	t.Cleanup(func() { FlushNodes("elasticsearch", "openshift-logging") })
+
+	er.state().nodes = []NodeTypeInterface{
+		&deploymentNode{
+			clusterName: "elasticsearch",
+			self:        apps.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-logging"}},
+			client:      er.client,
+			secretHash:  getSecretDataHash("elasticsearch", "openshift-logging", er.client),
+		},
+	}
+	er.observeSecretKeys()
+
+	return er
+}
+
+func updateSecretKey(t *testing.T, er *ElasticsearchRequest, key, value string) {
+	secret, err := getSecret("elasticsearch", "openshift-logging", er.client)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	secret.Data[key] = []byte(value)
+	if err := er.client.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+}
+
+func TestPlanCertRedeploy(t *testing.T) {
+	tests := []struct {
+		desc     string
+		key      string
+		strategy loggingv1.CertRedeployStrategy
+	}{
+		{desc: "leaf certificate", key: "admin-cert", strategy: loggingv1.CertRedeployRolling},
+		{desc: "certificate authority", key: "admin-ca", strategy: loggingv1.CertRedeployFullCluster},
+		{desc: "added key", key: "other", strategy: loggingv1.CertRedeployRolling},
+	}
+
+	for _, test := range tests {
+		er := newCertRedeployRequest(t)
+		updateSecretKey(t, er, test.key, "changed")
+
+		status := &loggingv1.ElasticsearchStatus{}
+		if !er.planCertRedeploy(status) {
+			t.Errorf("%s: expected status to change", test.desc)
+			continue
+		}
+
+		plan := status.CertificateRedeploy
+		if plan.Strategy != test.strategy {
+			t.Errorf("%s: got strategy %s, want %s (%s)", test.desc, plan.Strategy, test.strategy, plan.Reason)
+		}
+		if !reflect.DeepEqual(plan.ChangedKeys, []string{test.key}) {
+			t.Errorf("%s: got changed keys %v, want [%s]", test.desc, plan.ChangedKeys, test.key)
+		}
+
+		FlushNodes("elasticsearch", "openshift-logging")
+	}
+}
+
+func TestPlanCertRedeployEscalatesToFullCluster(t *testing.T) {
+	er := newCertRedeployRequest(t)
+	status := &loggingv1.ElasticsearchStatus{}
+
+	updateSecretKey(t, er, "admin-cert", "changed")
+	er.planCertRedeploy(status)
+	if !isRollingCertRedeploy(status) {
+		t.Fatalf("expected a rolling redeploy, got %v", status.CertificateRedeploy)
+	}
+
+	// unchanged secret keeps the plan
+	if er.planCertRedeploy(status) {
+		t.Errorf("expected the plan in progress to be kept")
+	}
+
+	updateSecretKey(t, er, "admin-ca", "changed")
+	if !er.planCertRedeploy(status) || status.CertificateRedeploy.Strategy != loggingv1.CertRedeployFullCluster {
+		t.Errorf("expected a CA change to require a full cluster restart, got %v", status.CertificateRedeploy)
+	}
+
+	// the CA is not rolled back to a rolling restart
+	if er.planCertRedeploy(status) {
+		t.Errorf("expected the full cluster plan to be kept")
+	}
+}
+
+func TestPlanCertRedeployWithoutPreviousSecret(t *testing.T) {
+	er := newCertRedeployRequest(t)
+	er.state().secretKeyHashes = nil
+
+	status := &loggingv1.ElasticsearchStatus{}
+	er.planCertRedeploy(status)
+	if status.CertificateRedeploy.Strategy != loggingv1.CertRedeployFullCluster {
+		t.Errorf("expected a full cluster restart, got %v", status.CertificateRedeploy)
+	}
+}
+
+func TestObserveSecretKeysWaitsForNodes(t *testing.T) {
+	er := newCertRedeployRequest(t)
+	previous := er.state().secretKeyHashes
+
+	updateSecretKey(t, er, "admin-cert", "changed")
+	er.observeSecretKeys()
+	if !reflect.DeepEqual(er.state().secretKeyHashes, previous) {
+		t.Errorf("expected the keys not to be observed while a node runs with the old secret")
+	}
+
+	er.getNodes()[0].refreshHashes()
+	er.observeSecretKeys()
+	if reflect.DeepEqual(er.state().secretKeyHashes, previous) {
+		t.Errorf("expected the keys to be observed once every node runs with the new secret")
+	}
+}
+
+func TestCompleteCertRedeploy(t *testing.T) {
+	er := newCertRedeployRequest(t)
+	status := &loggingv1.ElasticsearchStatus{
+		Nodes: []loggingv1.ElasticsearchNodeStatus{
+			{
+				DeploymentName: "elasticsearch-cdm-1",
+				UpgradeStatus: loggingv1.ElasticsearchNodeUpgradeStatus{
+					ScheduledForCertRedeploy: v1.ConditionTrue,
+				},
+			},
+		},
+	}
+
+	updateSecretKey(t, er, "admin-cert", "changed")
+	er.planCertRedeploy(status)
+
+	if er.completeCertRedeploy(status) {
+		t.Errorf("expected the redeploy not to complete while a node is scheduled")
+	}
+
+	status.Nodes[0].UpgradeStatus.ScheduledForCertRedeploy = v1.ConditionFalse
+	if !er.completeCertRedeploy(status) || status.CertificateRedeploy.CompletionTime == nil {
+		t.Errorf("expected the redeploy to complete")
+	}
+	if isRollingCertRedeploy(status) {
+		t.Errorf("expected a completed redeploy not to be in progress")
+	}
+}
@@ -2,15 +2,19 @@ package k8shandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/openshift/elasticsearch-operator/internal/metrics"
 
+	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
+	"github.com/go-logr/logr"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 
@@ -19,19 +23,33 @@ import (
 
 const expectedMinVersion = "6.0"
 
-var (
-	wrongConfig bool
-	nodes       map[string][]NodeTypeInterface
-)
+func nodeMapKey(clusterName, namespace string) string {
+	return fmt.Sprintf("%v-%v", clusterName, namespace)
+}
 
-var aliasNeededMap map[string]bool
+// logRestartError logs restarts waiting for the cluster to settle as info
+// since they are expected and picked up again on the next reconcile
+func logRestartError(ll logr.Logger, err error, msg string, keysAndValues ...interface{}) {
+	if errors.Is(err, ErrWaitingForCluster) {
+		ll.Info("Waiting to continue restart", append(keysAndValues, "reason", kverrors.Message(err))...)
+		return
+	}
 
-func FlushNodes(clusterName, namespace string) {
-	nodes[nodeMapKey(clusterName, namespace)] = []NodeTypeInterface{}
+	ll.Error(err, msg, keysAndValues...)
 }
 
-func nodeMapKey(clusterName, namespace string) string {
-	return fmt.Sprintf("%v-%v", clusterName, namespace)
+// IsRestartInProgress returns true while nodes of the cluster are being restarted or updated.
+// The reconciler uses it to check back sooner than its regular period.
+func IsRestartInProgress(cluster *api.Elasticsearch) bool {
+	for _, node := range cluster.Status.Nodes {
+		if node.UpgradeStatus.UnderUpgrade == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return containsClusterCondition(api.Restarting, v1.ConditionTrue, &cluster.Status) ||
+		containsClusterCondition(api.UpdatingESSettings, v1.ConditionTrue, &cluster.Status) ||
+		containsClusterCondition(api.Recovering, v1.ConditionTrue, &cluster.Status)
 }
 
 // CreateOrUpdateElasticsearchCluster creates an Elasticsearch deployment
@@ -44,13 +62,13 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 	if err != nil {
 		// if wrongConfig=true then we've already print out error message
 		// don't flood the stderr of the operator with the same message
-		if wrongConfig {
+		if er.state().wrongConfig {
 			return nil
 		}
-		wrongConfig = true
+		er.state().wrongConfig = true
 		return err
 	}
-	wrongConfig = false
+	er.state().wrongConfig = false
 
 	// Populate nodes from the custom resources spec.nodes
 	if err := er.populateNodes(); err != nil {
@@ -61,6 +79,16 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 	// may leave the shard allocation in an undesirable state
 	er.tryEnsureNoTransitiveShardAllocations()
 
+	// grow the claims of nodes whose storage size was raised
+	if err := er.reconcileVolumeExpansion(); err != nil {
+		ll.Error(err, "unable to reconcile volume expansion")
+	}
+
+	// delete or keep the claims of removed node groups as the retention policy says
+	if err := er.reconcileStorageRetention(); err != nil {
+		ll.Error(err, "unable to reconcile storage retention")
+	}
+
 	// Update the cluster status immediately to refresh status.nodes
 	// before progressing with any unschedulable nodes.
 	// Ensures that deleted nodes are removed from status.nodes.
@@ -68,15 +96,33 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		return err
 	}
 	if err := er.progressUnschedulableNodes(); err != nil {
-		ll.Error(err, "unable to progress unschedulable nodes")
+		logRestartError(ll, err, "unable to progress unschedulable nodes")
 		return er.UpdateClusterStatus()
 	}
 
 	certRestartNodes := er.getScheduledCertRedeployNodes()
 	stillRecovering := containsClusterCondition(api.Recovering, v1.ConditionTrue, &er.cluster.Status)
-	if len(certRestartNodes) > 0 || stillRecovering {
+	if len(certRestartNodes) > 0 && !stillRecovering {
+		// the nodes may have been scheduled before the secret reconciler chose how
+		// to restart them
+		er.planCertRedeploy(&er.cluster.Status)
+	}
+
+	if len(certRestartNodes) > 0 && !stillRecovering && isRollingCertRedeploy(&er.cluster.Status) {
+		// restart a single node at a time, a node whose restart is in progress
+		// is picked up below
+		if er.getNodeUpgradeInProgress() == nil {
+			if err := er.PerformNodeRestart(certRestartNodes[0]); err != nil {
+				logRestartError(ll, err, "unable to restart node for cert redeploy", "node", certRestartNodes[0].name())
+				return er.UpdateClusterStatus()
+			}
+
+			metrics.IncrementRestartCounterCert()
+			_ = er.UpdateClusterStatus()
+		}
+	} else if len(certRestartNodes) > 0 || stillRecovering {
 		if err := er.PerformFullClusterCertRestart(certRestartNodes); err != nil {
-			ll.Error(err, "unable to complete full cluster restart")
+			logRestartError(ll, err, "unable to complete full cluster restart")
 			return er.UpdateClusterStatus()
 		}
 
@@ -84,6 +130,18 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		_ = er.UpdateClusterStatus()
 	}
 
+	// restart a single node at a time to finish resizing its filesystem, a node whose
+	// restart is in progress is picked up below
+	if redeployNodes := er.getScheduledRedeployNodes(); len(redeployNodes) > 0 && er.getNodeUpgradeInProgress() == nil {
+		if err := er.PerformNodeRestart(redeployNodes[0]); err != nil {
+			logRestartError(ll, err, "unable to restart node for volume expansion", "node", redeployNodes[0].name())
+			return er.UpdateClusterStatus()
+		}
+
+		metrics.IncrementRestartCounterRolling()
+		_ = er.UpdateClusterStatus()
+	}
+
 	// if there is a node currently being upgraded, work on that first
 	inProgressNode := er.getNodeUpgradeInProgress()
 	scheduledNodes := er.getScheduledUpgradeNodes()
@@ -93,7 +151,7 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		// Check to see if the inProgressNode was being updated or restarted
 		if _, ok := containsNodeTypeInterface(inProgressNode, scheduledNodes); ok {
 			if err := er.PerformNodeUpdate(inProgressNode); err != nil {
-				ll.Error(err, "unable to update node")
+				logRestartError(ll, err, "unable to update node", "node", inProgressNode.name())
 				return er.UpdateClusterStatus()
 			}
 
@@ -101,7 +159,7 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 			scheduledNodes = er.getScheduledUpgradeNodes()
 		} else {
 			if err := er.PerformNodeRestart(inProgressNode); err != nil {
-				ll.Error(err, "unable to restart node", "node", inProgressNode.name())
+				logRestartError(ll, err, "unable to restart node", "node", inProgressNode.name())
 				return er.UpdateClusterStatus()
 			}
 		}
@@ -109,6 +167,22 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 		_ = er.UpdateClusterStatus()
 	}
 
+	// nodes switching between ephemeral and persistent storage lose their data and wait
+	// for the change to be acknowledged
+	scheduledNodes, err = er.confirmStorageChanges(scheduledNodes)
+	if err != nil {
+		ll.Error(err, "unable to confirm storage changes")
+		return er.UpdateClusterStatus()
+	}
+
+	// nodes moving to the packaged version of Elasticsearch wait while a critical issue
+	// blocks the upgrade
+	scheduledNodes, err = er.validateUpgrade(scheduledNodes)
+	if err != nil {
+		ll.Error(err, "unable to validate upgrade")
+		return er.UpdateClusterStatus()
+	}
+
 	// We didn't have any in progress, but we have ones scheduled to be updated
 	if len(scheduledNodes) > 0 {
 
@@ -122,16 +196,24 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 
 		comparison := comparators.CompareVersions(version, expectedMinVersion)
 
-		// if it is < what we expect (6.0) then do full cluster update:
-		if comparison > 0 {
+		// if it is < what we expect (6.0) then do full cluster update, otherwise upgrades
+		// use the strategy they were planned with
+		fullRestart := comparison > 0
+		if strategy := er.upgradeStrategy(scheduledNodes); strategy != "" && !fullRestart {
+			fullRestart = strategy == api.UpgradeFullClusterRestart
+		}
+
+		// changes to the proxy alone and changes of the storage are always rolled
+		// out one node at a time:
+		if fullRestart && !isProxyOnlyUpdate(scheduledNodes) && !anyStorageChange(scheduledNodes) {
 			// perform a full cluster update
 			if err := er.PerformFullClusterUpdate(scheduledNodes); err != nil {
-				log.Error(err, "failed to perform full cluster update")
+				logRestartError(ll, err, "failed to perform full cluster update")
 				return er.UpdateClusterStatus()
 			}
 		} else {
 			if err := er.PerformRollingUpdate(scheduledNodes); err != nil {
-				log.Error(err, "failed to perform rolling update")
+				logRestartError(ll, err, "failed to perform rolling update")
 				return er.UpdateClusterStatus()
 			}
 			metrics.IncrementRestartCounterRolling()
@@ -143,7 +225,7 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 	if er.getNodeUpgradeInProgress() == nil {
 		// We have no updates or restarts in progress
 		// create any nodes we are missing and perform any required operations to ensure state
-		for _, node := range nodes[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] {
+		for _, node := range er.getNodes() {
 			clusterStatus := er.cluster.Status.DeepCopy()
 			_, nodeStatus := getNodeStatus(node.name(), clusterStatus)
 
@@ -163,32 +245,54 @@ func (er *ElasticsearchRequest) CreateOrUpdateElasticsearchCluster() error {
 			}
 		}
 
+		// remove the surge node once every scheduled node was updated
+		if len(er.getScheduledUpgradeNodes()) == 0 {
+			if err := er.retireSurgeNode(); err != nil {
+				log.Error(err, "unable to retire surge node")
+			}
+		}
+
+		// remember the secret every node runs with to tell which keys change next
+		er.observeSecretKeys()
+		er.completeCertRedeploy(&er.cluster.Status)
+
 		// ensure that MinMasters is (n / 2 + 1)
 		er.updateMinMasters()
 
 		// update our template primary shard counts in case they changed
 		er.updatePrimaryShards()
 
-		// ensure we always have shard allocation to All if we aren't doing an update...
-		er.tryEnsureAllShardAllocation()
+		// move the shards of the nodes replaced by storage migrations
+		if err := er.progressStorageMigrations(); err != nil {
+			log.Error(err, "unable to progress storage migrations")
+		}
+
+		// take the volume snapshots of the data nodes the policy asks for
+		if err := er.reconcileVolumeSnapshots(); err != nil {
+			log.Error(err, "unable to reconcile volume snapshots")
+		}
+
+		// report the upgrade completed once every node runs the packaged version
+		if err := er.completeUpgrade(); err != nil {
+			log.Error(err, "unable to complete upgrade")
+		}
+
+		// ensure we always have shard allocation to All if we aren't doing an update
+		// or waiting for volume snapshots to be taken...
+		if !er.takingVolumeSnapshots() {
+			er.tryEnsureAllShardAllocation()
+		}
 
 		// we only want to update our replicas if we aren't in the middle up an update
 		er.updateReplicas()
 
-		// add alias to old indices if they exist and don't have one
-		// this should be removed after one release...
-		if er.ClusterReady() {
-			if aliasNeededMap == nil {
-				aliasNeededMap = make(map[string]bool)
-			}
-			if val, ok := aliasNeededMap[nodeMapKey(er.cluster.Name, er.cluster.Namespace)]; !ok || val {
-				successful := esClient.AddAliasForOldIndices()
-
-				if successful {
-					aliasNeededMap[nodeMapKey(er.cluster.Name, er.cluster.Namespace)] = false
-				}
-			}
+		// connect to the remote clusters of the spec and report the connections
+		if err := er.reconcileRemoteClusters(); err != nil {
+			log.Error(err, "unable to reconcile remote clusters")
 		}
+
+		// run the pending migrations of the data and settings, e.g. after an upgrade
+		er.runMigrations()
 	}
 
 	// Scrape cluster health from elasticsearch every time
@@ -200,7 +304,7 @@ func (er *ElasticsearchRequest) getNodeUpgradeInProgress() NodeTypeInterface {
 
 	for _, node := range cluster.Status.Nodes {
 		if node.UpgradeStatus.UnderUpgrade == v1.ConditionTrue {
-			for _, nodeTypeInterface := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
+			for _, nodeTypeInterface := range er.getNodes() {
 				if node.DeploymentName == nodeTypeInterface.name() ||
 					node.StatefulSetName == nodeTypeInterface.name() {
 					return nodeTypeInterface
@@ -214,7 +318,7 @@ func (er *ElasticsearchRequest) getNodeUpgradeInProgress() NodeTypeInterface {
 
 func (er *ElasticsearchRequest) progressUnschedulableNodes() error {
 	cluster := er.cluster
-	clusterNodes := nodes[nodeMapKey(cluster.GetName(), cluster.GetNamespace())]
+	clusterNodes := er.getNodes()
 
 	for _, nodeStatus := range cluster.Status.Nodes {
 		if isPodUnschedulableConditionTrue(nodeStatus.Conditions) ||
@@ -227,8 +331,10 @@ func (er *ElasticsearchRequest) progressUnschedulableNodes() error {
 						continue
 					}
 
-					if err := node.progressNodeChanges(); err != nil {
-						log.Error(err, "Failed to progress update of unschedulable node", "node", node.name())
+					if err := progressNodeChanges(node); err != nil {
+						if !errors.Is(err, ErrWaitingForCluster) {
+							log.Error(err, "Failed to progress update of unschedulable node", "node", node.name())
+						}
 						return err
 					}
 				}
@@ -293,63 +399,104 @@ func (er *ElasticsearchRequest) populateNodes() error {
 	}
 	er.setUUIDs()
 
-	if nodes == nil {
-		nodes = make(map[string][]NodeTypeInterface)
+	// node groups whose storage class changed are replaced a node at a time
+	if err := er.planStorageMigrations(); err != nil {
+		return err
 	}
 
 	cluster := er.cluster
+	knownNodes := er.getNodes()
 	currentNodes := []NodeTypeInterface{}
 
 	// get list of client only nodes, and collapse node info into the node (self field) if needed
 	for _, node := range cluster.Spec.Nodes {
 		// build the NodeTypeInterface list
-		for _, nodeTypeInterface := range er.GetNodeTypeInterface(*node.GenUUID, node) {
+		for _, nodeTypeInterface := range er.nodeGroupInterfaces(node) {
 
-			nodeIndex, ok := containsNodeTypeInterface(nodeTypeInterface, nodes[nodeMapKey(cluster.Name, cluster.Namespace)])
+			nodeIndex, ok := containsNodeTypeInterface(nodeTypeInterface, knownNodes)
 			if !ok {
 				currentNodes = append(currentNodes, nodeTypeInterface)
 			} else {
-				nodes[nodeMapKey(cluster.Name, cluster.Namespace)][nodeIndex].updateReference(nodeTypeInterface)
-				currentNodes = append(currentNodes, nodes[nodeMapKey(cluster.Name, cluster.Namespace)][nodeIndex])
+				knownNodes[nodeIndex].updateReference(nodeTypeInterface)
+				currentNodes = append(currentNodes, knownNodes[nodeIndex])
 			}
 		}
 	}
 
+	ownedNodes, err := er.getOwnedNodes()
+	if err != nil {
+		return kverrors.Wrap(err, "failed to list nodes of the cluster")
+	}
+
 	minMasterUpdated := false
 
 	// we want to only keep nodes that were generated and purge/delete any other ones...
-	for _, node := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
-		if _, ok := containsNodeTypeInterface(node, currentNodes); !ok {
-			if !minMasterUpdated {
-				// if we're removing a node make sure we set a lower min masters to keep cluster functional
-				if er.AnyNodeReady() {
-					er.updateMinMasters()
-					minMasterUpdated = true
-				}
-			}
-			if err := node.delete(); err != nil {
-				log.Error(err, "unable to delete node")
-			}
+	for nodeName, node := range ownedNodes {
+		if nodeName == surgeNodeName(cluster.Name) || containsNodeName(nodeName, currentNodes) {
+			continue
+		}
 
-			// remove from status.Nodes
-			if index, _ := getNodeStatus(node.name(), &cluster.Status); index != NotFoundIndex {
-				cluster.Status.Nodes = append(cluster.Status.Nodes[:index], cluster.Status.Nodes[index+1:]...)
+		// a node dropped from the spec halfway through its restart or storage migration is
+		// removed once that completed so that its shards aren't lost along with it
+		if isNodeInFlight(nodeName, &cluster.Status) {
+			log.V(1).Info("Postponing the removal of the node until its update completed",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace,
+				"node", nodeName)
+			continue
+		}
+
+		if !minMasterUpdated {
+			// if we're removing a node make sure we set a lower min masters to keep cluster functional
+			if er.AnyNodeReady() {
+				er.updateMinMasters()
+				minMasterUpdated = true
 			}
 		}
+		if err := er.client.Delete(context.TODO(), node); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to delete node", "node", nodeName)
+		}
+
+		// remove from status.Nodes
+		if index, _ := getNodeStatus(nodeName, &cluster.Status); index != NotFoundIndex {
+			cluster.Status.Nodes = append(cluster.Status.Nodes[:index], cluster.Status.Nodes[index+1:]...)
+		}
 	}
 
-	nodes[nodeMapKey(cluster.Name, cluster.Namespace)] = currentNodes
+	er.state().nodes = currentNodes
 
 	return nil
 }
 
+// isNodeInFlight returns true while the node is being restarted or takes part in the
+// migration of its node group onto another storage class
+func isNodeInFlight(nodeName string, status *api.ElasticsearchStatus) bool {
+	for _, node := range status.Nodes {
+		if node.DeploymentName != nodeName && node.StatefulSetName != nodeName {
+			continue
+		}
+		if node.UpgradeStatus.UnderUpgrade == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	for _, migration := range status.StorageMigrations {
+		for _, node := range migration.Nodes {
+			if node.Name == nodeName || node.Replacement == nodeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (er *ElasticsearchRequest) getScheduledUpgradeNodes() []NodeTypeInterface {
 	cluster := er.cluster
 	upgradeNodes := []NodeTypeInterface{}
 
 	for _, node := range cluster.Status.Nodes {
 		if node.UpgradeStatus.ScheduledForUpgrade == v1.ConditionTrue {
-			for _, nodeTypeInterface := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
+			for _, nodeTypeInterface := range er.getNodes() {
 				if node.DeploymentName == nodeTypeInterface.name() ||
 					node.StatefulSetName == nodeTypeInterface.name() {
 					upgradeNodes = append(upgradeNodes, nodeTypeInterface)
@@ -361,6 +508,35 @@ func (er *ElasticsearchRequest) getScheduledUpgradeNodes() []NodeTypeInterface {
 	return upgradeNodes
 }
 
+// isProxyOnlyUpdate returns true if the nodes only wait for changes to their proxy container
+func isProxyOnlyUpdate(nodes []NodeTypeInterface) bool {
+	for _, node := range nodes {
+		if !node.isProxyOnlyChange() {
+			return false
+		}
+	}
+
+	return len(nodes) > 0
+}
+
+func (er *ElasticsearchRequest) getScheduledRedeployNodes() []NodeTypeInterface {
+	cluster := er.cluster
+	redeployNodes := []NodeTypeInterface{}
+
+	for _, node := range cluster.Status.Nodes {
+		if node.UpgradeStatus.ScheduledForRedeploy == v1.ConditionTrue {
+			for _, nodeTypeInterface := range er.getNodes() {
+				if node.DeploymentName == nodeTypeInterface.name() ||
+					node.StatefulSetName == nodeTypeInterface.name() {
+					redeployNodes = append(redeployNodes, nodeTypeInterface)
+				}
+			}
+		}
+	}
+
+	return redeployNodes
+}
+
 func (er *ElasticsearchRequest) getScheduledCertRedeployNodes() []NodeTypeInterface {
 	cluster := er.cluster
 	redeployCertNodes := []NodeTypeInterface{}
@@ -368,7 +544,7 @@ func (er *ElasticsearchRequest) getScheduledCertRedeployNodes() []NodeTypeInterf
 
 	for _, node := range cluster.Status.Nodes {
 		if node.UpgradeStatus.ScheduledForCertRedeploy == v1.ConditionTrue {
-			for _, nodeTypeInterface := range nodes[nodeMapKey(cluster.Name, cluster.Namespace)] {
+			for _, nodeTypeInterface := range er.getNodes() {
 				if node.DeploymentName == nodeTypeInterface.name() {
 					dataNodes = append(dataNodes, nodeTypeInterface)
 				}
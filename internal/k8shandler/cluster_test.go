@@ -0,0 +1,103 @@
+package k8shandler
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestIsRestartInProgress(t *testing.T) {
+	tests := []struct {
+		desc   string
+		status api.ElasticsearchStatus
+		want   bool
+	}{
+		{
+			desc: "no restart",
+			status: api.ElasticsearchStatus{
+				Nodes: []api.ElasticsearchNodeStatus{
+					{DeploymentName: "elasticsearch-cdm-1"},
+				},
+			},
+			want: false,
+		},
+		{
+			desc: "node under upgrade",
+			status: api.ElasticsearchStatus{
+				Nodes: []api.ElasticsearchNodeStatus{
+					{
+						DeploymentName: "elasticsearch-cdm-1",
+						UpgradeStatus: api.ElasticsearchNodeUpgradeStatus{
+							UnderUpgrade: v1.ConditionTrue,
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			desc: "full cluster restart",
+			status: api.ElasticsearchStatus{
+				Conditions: api.ClusterConditions{
+					{
+						Type:   api.Restarting,
+						Status: v1.ConditionTrue,
+					},
+				},
+			},
+			want: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			cluster := &api.Elasticsearch{Status: test.status}
+			if got := IsRestartInProgress(cluster); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsNodeInFlight(t *testing.T) {
+	status := &api.ElasticsearchStatus{
+		Nodes: []api.ElasticsearchNodeStatus{
+			{DeploymentName: "elasticsearch-cdm-1"},
+			{
+				DeploymentName: "elasticsearch-cdm-2",
+				UpgradeStatus: api.ElasticsearchNodeUpgradeStatus{
+					UnderUpgrade: v1.ConditionTrue,
+				},
+			},
+		},
+		StorageMigrations: []api.ElasticsearchStorageMigration{
+			{
+				UUID:    "old",
+				NewUUID: "new",
+				Nodes: []api.ElasticsearchNodeMigration{
+					{Name: "elasticsearch-cdm-old-1", Replacement: "elasticsearch-cdm-new-1", Phase: api.NodeMigrationRelocating},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		nodeName string
+		want     bool
+	}{
+		{nodeName: "elasticsearch-cdm-1", want: false},
+		{nodeName: "elasticsearch-cdm-2", want: true},
+		{nodeName: "elasticsearch-cdm-old-1", want: true},
+		{nodeName: "elasticsearch-cdm-new-1", want: true},
+		{nodeName: "elasticsearch-cdm-unknown", want: false},
+	}
+
+	for _, test := range tests {
+		if got := isNodeInFlight(test.nodeName, status); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.nodeName, got, test.want)
+		}
+	}
+}
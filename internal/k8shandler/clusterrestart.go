@@ -14,6 +14,11 @@ import (
 // ErrFlushShardsFailed indicates a failure when trying to flush shards
 var ErrFlushShardsFailed = kverrors.New("flush shards failed")
 
+// ErrWaitingForCluster indicates that a restart step is waiting for the cluster to settle.
+// Instead of blocking the reconciler the step is retried on a later reconcile, the restart
+// phase it belongs to is persisted in the status.
+var ErrWaitingForCluster = kverrors.New("waiting for cluster to settle")
+
 type ClusterRestart struct {
 	client           elasticsearch.Client
 	clusterName      string
@@ -173,6 +178,11 @@ func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error
 		recovery:         r.ensureClusterHealthValid,
 	}
 
+	if er.isSurgeEnabled() && node.holdsData() {
+		restarter.prep = er.relocateShardsFunc(r, node)
+		restarter.post = r.waitAllNodesRejoinAndClearExclusion
+	}
+
 	updateStatus := func() {
 		if err := er.setNodeStatus(node, restarter.nodeStatus, &er.cluster.Status); err != nil {
 			log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
@@ -181,6 +191,14 @@ func (er *ElasticsearchRequest) PerformNodeRestart(node NodeTypeInterface) error
 
 	restarter.setNodeConditions(updateStatus)
 
+	// the restarted node picked up the current secret and mounted its volume again
+	recovered := restarter.recoverySignaler
+	restarter.recoverySignaler = func() {
+		restarter.nodeStatus.UpgradeStatus.ScheduledForCertRedeploy = v1.ConditionFalse
+		restarter.nodeStatus.UpgradeStatus.ScheduledForRedeploy = v1.ConditionFalse
+		recovered()
+	}
+
 	restarter.nodeStatus = er.getNodeState(node)
 	return restarter.restartCluster()
 }
@@ -206,6 +224,35 @@ func (er *ElasticsearchRequest) PerformNodeUpdate(node NodeTypeInterface) error
 		recovery:         r.ensureClusterHealthValid,
 	}
 
+	if er.isSurgeEnabled() && node.holdsData() {
+		restarter.prep = er.relocateShardsFunc(r, node)
+		restarter.post = r.waitAllNodesRejoinAndClearExclusion
+	}
+
+	restarter.nodeStatus = er.getNodeState(node)
+
+	// the data of a node switching between ephemeral and persistent storage is lost, its
+	// shards are moved onto the other data nodes first. The switch can't be told from the
+	// node once its new template was pushed, so the node status keeps the exclusion until
+	// it is cleared after the node rejoined.
+	if isStorageChangeAcknowledged(er.cluster) && node.holdsData() && getDataCount(er.cluster) > 1 && hasStorageChange(node) {
+		restarter.prep = func() error {
+			restarter.nodeStatus.UpgradeStatus.AllocationExcluded = v1.ConditionTrue
+			return r.relocateShards(node)
+		}
+	}
+	post := restarter.post
+	restarter.post = func() error {
+		if restarter.nodeStatus.UpgradeStatus.AllocationExcluded != v1.ConditionTrue {
+			return post()
+		}
+		if err := r.waitAllNodesRejoinAndClearExclusion(); err != nil {
+			return err
+		}
+		restarter.nodeStatus.UpgradeStatus.AllocationExcluded = ""
+		return nil
+	}
+
 	updateStatus := func() {
 		if err := er.setNodeStatus(node, restarter.nodeStatus, &er.cluster.Status); err != nil {
 			log.Error(err, "unable to update node status", "namespace", er.cluster.Namespace, "name", er.cluster.Name)
@@ -214,7 +261,6 @@ func (er *ElasticsearchRequest) PerformNodeUpdate(node NodeTypeInterface) error
 
 	restarter.setNodeConditions(updateStatus)
 
-	restarter.nodeStatus = er.getNodeState(node)
 	return restarter.restartCluster()
 }
 
@@ -248,7 +294,7 @@ func (er *ElasticsearchRequest) scaleDownThenUpFunc(clusterRestart ClusterRestar
 		}
 
 		if er.AnyNodeReady() {
-			return kverrors.New("waiting for all nodes to leave the cluster")
+			return kverrors.Wrap(ErrWaitingForCluster, "waiting for all nodes to leave the cluster")
 		}
 
 		if err := clusterRestart.scaleUpNodes(); err != nil {
@@ -266,7 +312,7 @@ func (cr ClusterRestart) restartNoop() error {
 
 func (cr ClusterRestart) ensureClusterHealthValid() error {
 	if status, _ := cr.client.GetClusterHealthStatus(); !utils.Contains(desiredClusterStates, status) {
-		return kverrors.New("Waiting for cluster to be recovered",
+		return kverrors.Wrap(ErrWaitingForCluster, "Waiting for cluster to be recovered",
 			"namespace", cr.clusterNamespace,
 			"cluster", cr.clusterName,
 			"status", status,
@@ -348,14 +394,11 @@ func (cr ClusterRestart) scaleDownThenUpNodes() error {
 		}
 	}
 
+	// waiting for the nodes to rejoin is left to the post phase
 	if err := cr.scaleUpNodes(); err != nil {
 		return err
 	}
 
-	if err := cr.waitAllNodesRejoin(); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -385,7 +428,7 @@ func (cr ClusterRestart) scaleUpNodes() error {
 
 func (cr ClusterRestart) pushNodeUpdates() error {
 	for _, node := range cr.scheduledNodes {
-		if err := node.progressNodeChanges(); err != nil {
+		if err := progressNodeChanges(node); err != nil {
 			return err
 		}
 	}
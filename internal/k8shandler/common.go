@@ -1,15 +1,13 @@
 package k8shandler
 
 import (
-	"context"
 	"fmt"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
-	"github.com/ViaQ/logerr/kverrors"
 	v1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -19,9 +17,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
-
-	networking "k8s.io/api/networking/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 var defaultResources = map[string]v1.ResourceRequirements{
@@ -186,9 +181,30 @@ func newElasticsearchContainer(imageName string, envVars []v1.EnvVar, resourceRe
 	}
 }
 
-func newProxyContainer(imageName, clusterName, namespace string, logConfig LogConfig, resourceRequirements v1.ResourceRequirements) v1.Container {
+func newProxyContainer(imageName, clusterName, namespace string, logConfig LogConfig, resourceRequirements v1.ResourceRequirements, proxy *api.ElasticsearchProxySpec) v1.Container {
+	args := []string{
+		// HTTPS default listener for Elasticsearch
+		"--listening-address=:60000",
+		"--tls-cert=/etc/proxy/elasticsearch/logging-es.crt",
+		"--tls-key=/etc/proxy/elasticsearch/logging-es.key",
+		"--tls-client-ca=/etc/proxy/elasticsearch/admin-ca",
+
+		// HTTPs listener for metrics
+		"--metrics-listening-address=:60001",
+		"--metrics-tls-cert=/etc/proxy/secrets/tls.crt",
+		"--metrics-tls-key=/etc/proxy/secrets/tls.key",
+
+		"--upstream-ca=/etc/proxy/elasticsearch/admin-ca",
+		"--cache-expiry=60s",
+	}
+	args = append(args, newProxyAuthRoleArgs(namespace, proxy)...)
+	args = append(args,
+		"--auth-admin-role=admin_reader",
+		"--auth-default-role=project_user",
+	)
+
 	container := v1.Container{
-		Name:            "proxy",
+		Name:            proxyContainerName,
 		Image:           imageName,
 		ImagePullPolicy: "IfNotPresent",
 		Ports: []v1.ContainerPort{
@@ -219,28 +235,7 @@ func newProxyContainer(imageName, clusterName, namespace string, logConfig LogCo
 				MountPath: "/etc/proxy/elasticsearch",
 			},
 		},
-		Args: []string{
-			// HTTPS default listener for Elasticsearch
-			"--listening-address=:60000",
-			"--tls-cert=/etc/proxy/elasticsearch/logging-es.crt",
-			"--tls-key=/etc/proxy/elasticsearch/logging-es.key",
-			"--tls-client-ca=/etc/proxy/elasticsearch/admin-ca",
-
-			// HTTPs listener for metrics
-			"--metrics-listening-address=:60001",
-			"--metrics-tls-cert=/etc/proxy/secrets/tls.crt",
-			"--metrics-tls-key=/etc/proxy/secrets/tls.key",
-
-			"--upstream-ca=/etc/proxy/elasticsearch/admin-ca",
-			"--cache-expiry=60s",
-			`--auth-backend-role=admin_reader={"namespace": "default", "verb": "get", "resource": "pods/log"}`,
-			`--auth-backend-role=prometheus={"verb": "get", "resource": "/metrics"}`,
-			`--auth-backend-role=jaeger={"verb": "get", "resource": "/jaeger", "resourceAPIGroup": "elasticsearch.jaegertracing.io"}`,
-			`--auth-backend-role=elasticsearch-operator={"namespace": "*", "verb": "*", "resource": "*", "resourceAPIGroup": "logging.openshift.io"}`,
-			fmt.Sprintf("--auth-backend-role=index-management={\"namespace\":\"%s\", \"verb\": \"*\", \"resource\": \"indices\", \"resourceAPIGroup\": \"elasticsearch.openshift.io\"}", namespace),
-			"--auth-admin-role=admin_reader",
-			"--auth-default-role=project_user",
-		},
+		Args:      args,
 		Resources: resourceRequirements,
 	}
 	return container
@@ -337,7 +332,7 @@ func newLabelSelector(clusterName, nodeName string, roleMap map[api.Elasticsearc
 	}
 }
 
-func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec, labels map[string]string, roleMap map[api.ElasticsearchNodeRole]bool, client client.Client, logConfig LogConfig) v1.PodTemplateSpec {
+func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.ElasticsearchNode, commonSpec api.ElasticsearchNodeSpec, labels map[string]string, roleMap map[api.ElasticsearchNodeRole]bool, client client.Client, logConfig LogConfig, proxy *api.ElasticsearchProxySpec) v1.PodTemplateSpec {
 	resourceRequirements := newESResourceRequirements(node.Resources, commonSpec.Resources)
 	proxyResourceRequirements := newESProxyResourceRequirements(node.ProxyResources, commonSpec.ProxyResources)
 
@@ -355,31 +350,98 @@ func newPodTemplateSpec(nodeName, clusterName, namespace string, node api.Elasti
 		},
 	})
 
-	return v1.PodTemplateSpec{
+	podSpec := mergePodSpec(node.ElasticsearchPodSpec, commonSpec.ElasticsearchPodSpec)
+
+	annotations := mergeSelectors(podSpec.PodAnnotations, nil)
+	setManagedKeys(annotations, podAnnotationKeysAnnotation, podSpec.PodAnnotations)
+	setManagedKeys(annotations, podLabelKeysAnnotation, podSpec.PodLabels)
+	if hash := getHTTPCertHash(clusterName, namespace, client); hash != "" {
+		annotations[httpCertHashAnnotation] = hash
+	}
+	if hosts := getReindexRemoteHosts(clusterName, namespace, client); len(hosts) > 0 {
+		annotations[reindexRemoteHostsAnnotation] = strings.Join(hosts, ",")
+	}
+	remoteTrustHash := getRemoteTrustHash(clusterName, namespace, client)
+	if remoteTrustHash != "" {
+		annotations[remoteTrustHashAnnotation] = remoteTrustHash
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	serviceAccountName := podSpec.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = clusterName
+	}
+
+	containers := []v1.Container{
+		newElasticsearchContainer(
+			getESImage(),
+			newEnvVars(nodeName, clusterName, resourceRequirements.Limits.Memory().String(), roleMap),
+			resourceRequirements,
+		),
+		newProxyContainer(
+			getESProxyImage(),
+			clusterName,
+			namespace,
+			logConfig,
+			proxyResourceRequirements,
+			proxy),
+	}
+	for i := range containers {
+		containers[i].SecurityContext = podSpec.SecurityContext
+	}
+
+	template := v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: labels,
+			// the labels of the operator select the pods, they can't be overridden
+			Labels:      mergeSelectors(labels, mergeSelectors(podSpec.PodLabels, nil)),
+			Annotations: annotations,
 		},
 		Spec: v1.PodSpec{
-			Affinity: newAffinity(roleMap),
-			Containers: []v1.Container{
-				newElasticsearchContainer(
-					getESImage(),
-					newEnvVars(nodeName, clusterName, resourceRequirements.Limits.Memory().String(), roleMap),
-					resourceRequirements,
-				),
-				newProxyContainer(
-					getESProxyImage(),
-					clusterName,
-					namespace,
-					logConfig,
-					proxyResourceRequirements),
-			},
+			Affinity:           newAffinity(roleMap),
+			Containers:         containers,
 			NodeSelector:       selectors,
-			ServiceAccountName: clusterName,
+			ServiceAccountName: serviceAccountName,
+			SecurityContext:    podSpec.PodSecurityContext,
+			ImagePullSecrets:   podSpec.ImagePullSecrets,
+			PriorityClassName:  podSpec.PriorityClassName,
 			Volumes:            newVolumes(clusterName, nodeName, namespace, node, client),
 			Tolerations:        tolerations,
 		},
 	}
+	if remoteTrustHash != "" {
+		mountRemoteTrust(&template, clusterName)
+	}
+
+	return template
+}
+
+// mergePodSpec returns the pod settings of the node, falling back to the settings common to
+// all nodes. Annotations and labels are merged with the ones of the node taking precedence.
+func mergePodSpec(node, common api.ElasticsearchPodSpec) api.ElasticsearchPodSpec {
+	merged := *common.DeepCopy()
+	node = *node.DeepCopy()
+
+	if node.ServiceAccountName != "" {
+		merged.ServiceAccountName = node.ServiceAccountName
+	}
+	if node.PodSecurityContext != nil {
+		merged.PodSecurityContext = node.PodSecurityContext
+	}
+	if node.SecurityContext != nil {
+		merged.SecurityContext = node.SecurityContext
+	}
+	if len(node.ImagePullSecrets) > 0 {
+		merged.ImagePullSecrets = node.ImagePullSecrets
+	}
+	if node.PriorityClassName != "" {
+		merged.PriorityClassName = node.PriorityClassName
+	}
+	merged.PodAnnotations = mergeSelectors(node.PodAnnotations, merged.PodAnnotations)
+	merged.PodLabels = mergeSelectors(node.PodLabels, merged.PodLabels)
+
+	return merged
 }
 
 func newESResourceRequirements(nodeResRequirements, commonResRequirements v1.ResourceRequirements) v1.ResourceRequirements {
@@ -585,7 +647,8 @@ func newVolumeSource(clusterName, nodeName, namespace string, node api.Elasticse
 	}
 
 	// in the case where we do not have a size provided we need to
-	// fall back to using ephemeral storage since a pvc requires a size
+	// fall back to using ephemeral storage since a pvc requires a size,
+	// running nodes only switch once the change was acknowledged
 	if specVol.Size == nil {
 		log.Info("Storage size is required but was missing. Defaulting to EmptyDirVolume. Please adjust your CR accordingly.")
 		volSource.EmptyDir = &v1.EmptyDirVolumeSource{}
@@ -598,7 +661,19 @@ func newVolumeSource(clusterName, nodeName, namespace string, node api.Elasticse
 		ClaimName: claimName,
 	}
 
-	volSpec := v1.PersistentVolumeClaimSpec{
+	volSpec := newPersistentVolumeClaimSpec(specVol)
+
+	err := createOrUpdatePersistentVolumeClaim(volSpec, claimName, namespace, clusterName, client)
+	if err != nil {
+		log.Error(err, "Unable to create PersistentVolumeClaim")
+	}
+	return volSource
+}
+
+// newPersistentVolumeClaimSpec returns the claim of a node with persistent storage, the size
+// of the storage spec must be set
+func newPersistentVolumeClaimSpec(specVol api.ElasticsearchStorageSpec) v1.PersistentVolumeClaimSpec {
+	return v1.PersistentVolumeClaimSpec{
 		AccessModes: []v1.PersistentVolumeAccessMode{
 			v1.ReadWriteOnce,
 		},
@@ -608,13 +683,8 @@ func newVolumeSource(clusterName, nodeName, namespace string, node api.Elasticse
 			},
 		},
 		StorageClassName: specVol.StorageClassName,
+		DataSource:       specVol.DataSource,
 	}
-
-	err := createOrUpdatePersistentVolumeClaim(volSpec, claimName, namespace, clusterName, client)
-	if err != nil {
-		log.Error(err, "Unable to create PersistentVolumeClaim")
-	}
-	return volSource
 }
 
 func sortDataHashKeys(dataHash map[string][32]byte) []string {
@@ -627,127 +697,3 @@ func sortDataHashKeys(dataHash map[string][32]byte) []string {
 
 	return keys
 }
-
-/*
-kind: NetworkPolicy
-apiVersion: extensions/v1beta1
-metadata:
-  name: restricted-es-access
-spec:
-  podSelector:
-    matchLabels:
-      component: elasticsearch
-  ingress:
-  - from:
-    - podSelector:
-        matchLabels:
-          name: elasticsearch-operator
-    ports:
-    - protocol: TCP
-      port: 9200
-*/
-func newNetworkPolicy(namespace string) networking.NetworkPolicy {
-	protocol := v1.ProtocolTCP
-	port := intstr.FromInt(9200)
-	internalPort := intstr.FromInt(9300)
-
-	return networking.NetworkPolicy{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "NetworkPolicy",
-			APIVersion: networking.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "restricted-es-policy",
-			Namespace: namespace,
-		},
-		Spec: networking.NetworkPolicySpec{
-			PodSelector: metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"component": "elasticsearch",
-				},
-			},
-			Ingress: []networking.NetworkPolicyIngressRule{
-				{
-					From: []networking.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									"name": "elasticsearch-operator",
-								},
-							},
-							// This needs to be present but empty so it will select all namespaces
-							// since we do not have a label for our operator namespace
-							NamespaceSelector: &metav1.LabelSelector{},
-						},
-					},
-					Ports: []networking.NetworkPolicyPort{
-						{
-							Protocol: &protocol,
-							Port:     &port,
-						},
-					},
-				},
-				{
-					From: []networking.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									"component": "elasticsearch",
-								},
-							},
-						},
-					},
-					Ports: []networking.NetworkPolicyPort{
-						{
-							Protocol: &protocol,
-							Port:     &port,
-						},
-					},
-				},
-				{
-					From: []networking.NetworkPolicyPeer{
-						{
-							PodSelector: &metav1.LabelSelector{
-								MatchLabels: map[string]string{
-									"component": "elasticsearch",
-								},
-							},
-						},
-					},
-					Ports: []networking.NetworkPolicyPort{
-						{
-							Protocol: &protocol,
-							Port:     &internalPort,
-						},
-					},
-				},
-			},
-		},
-	}
-}
-
-func EnforceNetworkPolicy(namespace string, client client.Client, ownerRef []metav1.OwnerReference) error {
-	policy := newNetworkPolicy(namespace)
-	policy.ObjectMeta.OwnerReferences = ownerRef
-
-	err := client.Create(context.TODO(), &policy)
-	if err != nil {
-		if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
-			return kverrors.Wrap(err, "failed to create network policy")
-		}
-	}
-
-	return nil
-}
-
-func RelaxNetworkPolicy(namespace string, client client.Client) error {
-	policy := newNetworkPolicy(namespace)
-	err := client.Delete(context.TODO(), &policy)
-	if err != nil {
-		if !apierrors.IsNotFound(kverrors.Root(err)) {
-			return kverrors.Wrap(err, "failed to delete network policy")
-		}
-	}
-
-	return nil
-}
@@ -14,7 +14,9 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
@@ -463,7 +465,7 @@ func TestProxyContainerTLSClientAuthDefined(t *testing.T) {
 
 	empty := v1.ResourceRequirements{}
 	proxyResources := newESProxyResourceRequirements(empty, empty)
-	proxyContainer := newProxyContainer(imageName, clusterName, "openshift-logging", LogConfig{}, proxyResources)
+	proxyContainer := newProxyContainer(imageName, clusterName, "openshift-logging", LogConfig{}, proxyResources, nil)
 
 	want := []string{
 		"--tls-cert=/etc/proxy/elasticsearch/logging-es.crt",
@@ -497,7 +499,7 @@ func TestProxyContainerSpec(t *testing.T) {
 
 	empty := v1.ResourceRequirements{}
 	proxyResources := newESProxyResourceRequirements(empty, empty)
-	proxyContainer := newProxyContainer(imageName, clusterName, "openshift-logging", LogConfig{}, proxyResources)
+	proxyContainer := newProxyContainer(imageName, clusterName, "openshift-logging", LogConfig{}, proxyResources, nil)
 
 	wantArgs := []string{
 		"--metrics-listening-address=:60001",
@@ -555,7 +557,7 @@ func TestPodSpecHasTaintTolerations(t *testing.T) {
 		},
 	}
 
-	podTemplateSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, map[api.ElasticsearchNodeRole]bool{}, nil, LogConfig{})
+	podTemplateSpec := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", api.ElasticsearchNode{}, api.ElasticsearchNodeSpec{}, map[string]string{}, map[api.ElasticsearchNodeRole]bool{}, fake.NewFakeClient(), LogConfig{}, nil)
 
 	if !reflect.DeepEqual(podTemplateSpec.Spec.Tolerations, expectedTolerations) {
 		t.Errorf("Exp. the tolerations to be %v but was %v", expectedTolerations, podTemplateSpec.Spec.Tolerations)
@@ -784,8 +786,9 @@ func preparePodTemplateSpecProvidingNodeSelectors(selectors map[string]string) v
 		api.ElasticsearchNodeSpec{},
 		map[string]string{},
 		map[api.ElasticsearchNodeRole]bool{},
-		nil,
-		LogConfig{})
+		fake.NewFakeClient(),
+		LogConfig{},
+		nil)
 }
 
 func buildResource(cpuLimit, cpuRequest, memLimit, memRequest resource.Quantity) v1.ResourceRequirements {
@@ -857,3 +860,98 @@ var _ = Describe("common.go", func() {
 		})
 	})
 })
+
+func TestPodTemplateSpecPodSettings(t *testing.T) {
+	nonRoot := true
+	noEscalation := false
+
+	common := api.ElasticsearchNodeSpec{
+		ElasticsearchPodSpec: api.ElasticsearchPodSpec{
+			ServiceAccountName: "logging",
+			PodSecurityContext: &v1.PodSecurityContext{RunAsNonRoot: &nonRoot},
+			ImagePullSecrets:   []v1.LocalObjectReference{{Name: "registry"}},
+			PodAnnotations:     map[string]string{"team": "logging", "backup": "true"},
+			PodLabels:          map[string]string{"component": "override", "team": "logging"},
+		},
+	}
+	node := api.ElasticsearchNode{
+		ElasticsearchPodSpec: api.ElasticsearchPodSpec{
+			SecurityContext:   &v1.SecurityContext{AllowPrivilegeEscalation: &noEscalation},
+			PriorityClassName: "logging-critical",
+			PodAnnotations:    map[string]string{"backup": "false"},
+		},
+	}
+	labels := map[string]string{"component": "elasticsearch"}
+
+	template := newPodTemplateSpec("test-node-name", "test-cluster-name", "test-namespace-name", node, common, labels, map[api.ElasticsearchNodeRole]bool{}, fake.NewFakeClient(), LogConfig{}, nil)
+
+	if template.Spec.ServiceAccountName != "logging" {
+		t.Errorf("Exp. the service account of the common spec but was %q", template.Spec.ServiceAccountName)
+	}
+	if !reflect.DeepEqual(template.Spec.SecurityContext, common.PodSecurityContext) {
+		t.Errorf("Exp. the pod security context %v but was %v", common.PodSecurityContext, template.Spec.SecurityContext)
+	}
+	if !reflect.DeepEqual(template.Spec.ImagePullSecrets, common.ImagePullSecrets) {
+		t.Errorf("Exp. the image pull secrets %v but were %v", common.ImagePullSecrets, template.Spec.ImagePullSecrets)
+	}
+	if template.Spec.PriorityClassName != "logging-critical" {
+		t.Errorf("Exp. the priority class of the node but was %q", template.Spec.PriorityClassName)
+	}
+	for _, container := range template.Spec.Containers {
+		if !reflect.DeepEqual(container.SecurityContext, node.SecurityContext) {
+			t.Errorf("Exp. the security context of the node for container %s but was %v", container.Name, container.SecurityContext)
+		}
+	}
+
+	wantAnnotations := map[string]string{
+		"team":                      "logging",
+		"backup":                    "false",
+		podAnnotationKeysAnnotation: "backup,team",
+		podLabelKeysAnnotation:      "component,team",
+	}
+	if !reflect.DeepEqual(template.Annotations, wantAnnotations) {
+		t.Errorf("Exp. the annotations %v but were %v", wantAnnotations, template.Annotations)
+	}
+	wantLabels := map[string]string{"component": "elasticsearch", "team": "logging"}
+	if !reflect.DeepEqual(template.Labels, wantLabels) {
+		t.Errorf("Exp. the labels %v but were %v", wantLabels, template.Labels)
+	}
+	if !reflect.DeepEqual(labels, map[string]string{"component": "elasticsearch"}) {
+		t.Errorf("Exp. the labels of the operator to be left untouched but were %v", labels)
+	}
+}
+
+func TestPodTemplateSpecDefaultServiceAccount(t *testing.T) {
+	template := preparePodTemplateSpecProvidingNodeSelectors(nil)
+	if template.Spec.ServiceAccountName != "test-cluster-name" {
+		t.Errorf("Exp. the service account of the cluster but was %q", template.Spec.ServiceAccountName)
+	}
+}
+
+// newTestCluster returns the cluster elasticsearch in the namespace openshift-logging
+func newTestCluster(spec api.ElasticsearchSpec) *api.Elasticsearch {
+	return &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+			UID:       "cluster-uid",
+		},
+		Spec: spec,
+	}
+}
+
+// newTestRequest returns a request for the cluster whose fake client holds the cluster and the
+// objects and whose fake Elasticsearch client answers from the chatter
+func newTestRequest(cluster *api.Elasticsearch, chatter *helpers.FakeElasticsearchChatter, objs ...runtime.Object) *ElasticsearchRequest {
+	_ = api.SchemeBuilder.AddToScheme(scheme.Scheme)
+	if chatter == nil {
+		chatter = helpers.NewFakeElasticsearchChatter(nil)
+	}
+
+	c := fake.NewFakeClient(append(objs, cluster)...)
+	return &ElasticsearchRequest{
+		client:   c,
+		cluster:  cluster,
+		esClient: helpers.NewFakeElasticsearchClient(cluster.Name, cluster.Namespace, c, chatter),
+	}
+}
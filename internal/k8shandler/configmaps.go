@@ -19,6 +19,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 )
 
 const (
@@ -34,6 +36,9 @@ type esYmlStruct struct {
 	NodeQuorum           string
 	RecoverExpectedNodes string
 	SystemCallFilter     string
+	AuditLog             *auditLogConfig
+	ReindexRemoteHosts   []string
+	RemoteTrust          bool
 }
 
 type log4j2PropertiesStruct struct {
@@ -107,11 +112,20 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() (err error) {
 	if err != nil {
 		return err
 	}
+
 	dataNodeCount := int(getDataCount(dpl))
 	masterNodeCount := int(getMasterCount(dpl))
 
 	logConfig := getLogConfig(dpl.GetAnnotations())
 
+	// nodes switch to the remote trust once all of them mount it
+	remoteTrust := getRemoteTrustHash(dpl.Name, dpl.Namespace, er.client) != ""
+	if remoteTrust {
+		if remoteTrust, err = er.isRemoteTrustMounted(); err != nil {
+			return err
+		}
+	}
+
 	configmap := newConfigMap(
 		dpl.Name,
 		dpl.Namespace,
@@ -124,6 +138,9 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() (err error) {
 		strconv.Itoa(calculateReplicaCount(dpl)),
 		strconv.FormatBool(runtime.GOARCH == "amd64"),
 		logConfig,
+		dpl.Spec.AuditLog,
+		getReindexRemoteHosts(dpl.Name, dpl.Namespace, er.client),
+		remoteTrust,
 	)
 
 	dpl.AddOwnerRefTo(configmap)
@@ -181,10 +198,10 @@ func (er *ElasticsearchRequest) CreateOrUpdateConfigMaps() (err error) {
 	return nil
 }
 
-func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig) (map[string]string, error) {
+func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig, auditLog *api.ElasticsearchAuditLogSpec, reindexRemoteHosts []string, remoteTrust bool) (map[string]string, error) {
 	data := map[string]string{}
 	buf := &bytes.Buffer{}
-	if err := renderEsYml(buf, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter); err != nil {
+	if err := renderEsYml(buf, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter, auditLog, reindexRemoteHosts, remoteTrust); err != nil {
 		return data, err
 	}
 	data[esConfig] = buf.String()
@@ -206,8 +223,8 @@ func renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes
 
 // newConfigMap returns a v1.ConfigMap object
 func newConfigMap(configMapName, namespace string, labels map[string]string,
-	kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig) *v1.ConfigMap {
-	data, err := renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter, logConfig)
+	kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter string, logConfig LogConfig, auditLog *api.ElasticsearchAuditLogSpec, reindexRemoteHosts []string, remoteTrust bool) *v1.ConfigMap {
+	data, err := renderData(kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, primaryShardsCount, replicaShardsCount, systemCallFilter, logConfig, auditLog, reindexRemoteHosts, remoteTrust)
 	if err != nil {
 		return nil
 	}
@@ -251,7 +268,7 @@ func configMapContentChanged(old, new *v1.ConfigMap) bool {
 	return false
 }
 
-func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter string) error {
+func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recoverExpectedNodes, systemCallFilter string, auditLog *api.ElasticsearchAuditLogSpec, reindexRemoteHosts []string, remoteTrust bool) error {
 	t := template.New("elasticsearch.yml")
 	config := esYmlTmpl
 	t, err := t.Parse(config)
@@ -264,6 +281,9 @@ func renderEsYml(w io.Writer, kibanaIndexMode, esUnicastHost, nodeQuorum, recove
 		NodeQuorum:           nodeQuorum,
 		RecoverExpectedNodes: recoverExpectedNodes,
 		SystemCallFilter:     systemCallFilter,
+		AuditLog:             newAuditLogConfig(auditLog),
+		ReindexRemoteHosts:   reindexRemoteHosts,
+		RemoteTrust:          remoteTrust,
 	}
 
 	return t.Execute(w, esy)
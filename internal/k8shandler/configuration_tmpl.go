@@ -37,6 +37,25 @@ prometheus:
 
 # increase the max header size above 8kb default
 http.max_header_size: 128kb
+{{- with .ReindexRemoteHosts }}
+
+# clusters the indices are reindexed from, authenticating with the HTTP certificate
+reindex:
+  remote.whitelist:
+{{- range . }}
+  - "{{.}}"
+{{- end }}
+  ssl:
+    verification_mode: certificate
+    truststore:
+      path: /etc/elasticsearch/secret/truststore
+      password: tspass
+      type: jks
+    keystore:
+      path: /etc/elasticsearch/secret/key
+      password: kspass
+      type: jks
+{{- end }}
 
 opendistro_security:
   authcz.admin_dn:
@@ -44,16 +63,49 @@ opendistro_security:
   config_index_name: ".security"
   restapi:
     roles_enabled: ["kibana_server"]
+{{- with .AuditLog }}
+  audit:
+    type: {{.Type}}
+{{- if .IgnoreUsers }}
+    ignore_users:
+{{- range .IgnoreUsers }}
+    - "{{.}}"
+{{- end }}
+{{- end }}
+{{- if or .Index .DisabledCategories }}
+    config:
+{{- if .Index }}
+      index: "{{.Index}}"
+{{- end }}
+{{- if .DisabledCategories }}
+      disabled_rest_categories:
+{{- range .DisabledCategories }}
+      - {{.}}
+{{- end }}
+      disabled_transport_categories:
+{{- range .DisabledCategories }}
+      - {{.}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
   ssl:
     transport:
       enabled: true
       enforce_hostname_verification: false
+{{- if .RemoteTrust }}
+      # trusts the certificate authorities of the remote clusters besides the own one
+      pemcert_filepath: /etc/elasticsearch/remote-trust/elasticsearch.crt
+      pemkey_filepath: /etc/elasticsearch/remote-trust/elasticsearch.key
+      pemtrustedcas_filepath: /etc/elasticsearch/remote-trust/ca-bundle.crt
+{{- else }}
       keystore_type: JKS
       keystore_filepath: /etc/elasticsearch/secret/searchguard.key
       keystore_password: kspass
       truststore_type: JKS
       truststore_filepath: /etc/elasticsearch/secret/searchguard.truststore
       truststore_password: tspass
+{{- end }}
     http:
       enabled: true
       keystore_type: JKS
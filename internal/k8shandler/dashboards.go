@@ -6,6 +6,7 @@ import (
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -17,9 +18,13 @@ const (
 	defaultElasticDashboardFile = "/etc/elasticsearch-operator/files/dashboards/logging-dashboard-elasticsearch.json"
 	grafanaCMName               = "grafana-dashboard-elasticsearch"
 	grafanaCMNameSpace          = "openshift-config-managed"
+
+	// grafanaSidecarLabel is the default label the dashboard sidecar of the Grafana helm chart watches
+	grafanaSidecarLabel = "grafana_dashboard"
 )
 
-// CreateOrUpdateDashboards creates/updates the cluster logging dashboard ConfigMap
+// CreateOrUpdateDashboards creates/updates the cluster logging dashboard ConfigMap. Without the
+// OpenShift console it is created next to the cluster for the dashboard sidecar of Grafana.
 func (er *ElasticsearchRequest) CreateOrUpdateDashboards() error {
 	fp := utils.LookupEnvWithDefault("ES_DASHBOARD_FILE", defaultElasticDashboardFile)
 	b, err := ioutil.ReadFile(fp)
@@ -43,6 +48,14 @@ func (er *ElasticsearchRequest) CreateOrUpdateDashboards() error {
 		},
 	}
 
+	if !er.capabilities.Has(platform.Console) {
+		cm.Namespace = er.cluster.Namespace
+		cm.Labels = map[string]string{
+			grafanaSidecarLabel: "1",
+		}
+		er.cluster.AddOwnerRefTo(cm)
+	}
+
 	return er.CreateOrUpdateConfigMap(cm)
 }
 
@@ -0,0 +1,95 @@
+package k8shandler
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
+)
+
+func TestCreateOrUpdateDashboards(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dashboards")
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fp := filepath.Join(dir, "dashboard.json")
+	if err := ioutil.WriteFile(fp, []byte("{}"), 0600); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	os.Setenv("ES_DASHBOARD_FILE", fp)
+	defer os.Unsetenv("ES_DASHBOARD_FILE")
+
+	tests := []struct {
+		desc         string
+		capabilities platform.Capabilities
+		namespace    string
+		label        string
+	}{
+		{desc: "openshift", namespace: grafanaCMNameSpace, label: "console.openshift.io/dashboard"},
+		{
+			desc:         "without the console",
+			capabilities: platform.Capabilities{}.Without(platform.Console),
+			namespace:    "openshift-logging",
+			label:        grafanaSidecarLabel,
+		},
+	}
+
+	for _, test := range tests {
+		er := &ElasticsearchRequest{
+			client: fake.NewFakeClient(),
+			cluster: &loggingv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+			},
+			capabilities: test.capabilities,
+		}
+		if err := er.CreateOrUpdateDashboards(); err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+
+		cm := &v1.ConfigMap{}
+		key := types.NamespacedName{Name: grafanaCMName, Namespace: test.namespace}
+		if err := er.client.Get(context.TODO(), key, cm); err != nil {
+			t.Fatalf("%s: expected the dashboard in %s: %s", test.desc, test.namespace, err)
+		}
+		if _, ok := cm.Labels[test.label]; !ok {
+			t.Errorf("%s: expected label %s, got %v", test.desc, test.label, cm.Labels)
+		}
+	}
+}
+
+func TestUpdateIntegrationsDisabledCondition(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+	}
+	client := fake.NewFakeClient(cluster)
+
+	disabled := platform.Capabilities{}.Without(platform.Routes, platform.Console).Disabled()
+	if err := updateIntegrationsDisabledCondition(cluster, disabled, client); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.IntegrationsDisabled)
+	if condition == nil || condition.Message != "Integrations disabled as their APIs are not served: Console, Routes" {
+		t.Errorf("got condition %v", condition)
+	}
+
+	if err := updateIntegrationsDisabledCondition(cluster, nil, client); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.IntegrationsDisabled); condition != nil {
+		t.Errorf("expected the condition to be removed, got %v", condition)
+	}
+}
@@ -0,0 +1,630 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+const (
+	// dataRecoveryFinalizer keeps the data recovery around until its reindex task was cancelled
+	dataRecoveryFinalizer = "logging.openshift.io/data-recovery"
+
+	// dataClusterNameAnnotation makes the nodes of a cluster run on the claims and the data path
+	// of the named cluster, it is set on the recovery clusters of data recoveries
+	dataClusterNameAnnotation = "elasticsearch.openshift.io/data-cluster-name"
+
+	// reindexRemoteHostsAnnotation is the pod template annotation that rolls out changes to the
+	// clusters the indices are reindexed from
+	reindexRemoteHostsAnnotation = "elasticsearch.openshift.io/reindex-remote-hosts"
+
+	// dataRecoveryReaderRole grants the cluster reindexing the recovered indices read access
+	// to the recovery cluster
+	dataRecoveryReaderRole = "data-recovery-reader"
+
+	// dataRecoveryReaderDN is the subject of the HTTP certificate the cluster authenticates with
+	// when reindexing from remote, the recovery cluster runs with a copy of its secret
+	dataRecoveryReaderDN = "CN=logging-es,OU=OpenShift,O=Logging"
+)
+
+// defaultRecoveredIndices are the log indices of the current and the former data model
+var defaultRecoveredIndices = []string{"app-*", "infra-*", "audit-*", "project.*", ".operations.*"}
+
+func dataRecoveryClusterName(recovery *api.ElasticsearchDataRecovery) string {
+	return fmt.Sprintf("%s-recovery", recovery.Name)
+}
+
+// dataRecoveryHost is the address of the recovery cluster as listed in reindex.remote.whitelist
+func dataRecoveryHost(recovery *api.ElasticsearchDataRecovery) string {
+	return fmt.Sprintf("%s.%s.svc:9200", dataRecoveryClusterName(recovery), recovery.Namespace)
+}
+
+func dataRecoverySourceCluster(recovery *api.ElasticsearchDataRecovery) string {
+	if recovery.Spec.SourceClusterName != "" {
+		return recovery.Spec.SourceClusterName
+	}
+	return recovery.Spec.ClusterName
+}
+
+func isDataRecoveryDone(phase api.ElasticsearchDataRecoveryPhase) bool {
+	return phase == api.DataRecoveryCompleted || phase == api.DataRecoveryFailed
+}
+
+// getReindexRemoteHosts returns the recovery clusters the cluster reindexes from. Recoveries
+// that are done or being deleted are left out, which rolls the cluster once more.
+func getReindexRemoteHosts(clusterName, namespace string, c client.Client) []string {
+	recoveries := &api.ElasticsearchDataRecoveryList{}
+	if err := c.List(context.TODO(), recoveries, client.InNamespace(namespace)); err != nil {
+		return nil
+	}
+
+	hosts := []string{}
+	for i := range recoveries.Items {
+		recovery := &recoveries.Items[i]
+		if recovery.Spec.ClusterName != clusterName ||
+			recovery.GetDeletionTimestamp() != nil ||
+			isDataRecoveryDone(recovery.Status.Phase) {
+			continue
+		}
+		hosts = append(hosts, dataRecoveryHost(recovery))
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// useDataOfCluster points the nodes of a recovery cluster to the claims and the data path of
+// the cluster named by the data cluster annotation
+func useDataOfCluster(template *v1.PodTemplateSpec, cluster *api.Elasticsearch, nodeName string) {
+	dataCluster := cluster.GetAnnotations()[dataClusterNameAnnotation]
+	if dataCluster == "" {
+		return
+	}
+
+	claimName := fmt.Sprintf("%s-%s%s", dataCluster, dataCluster, strings.TrimPrefix(nodeName, cluster.Name))
+	for i, volume := range template.Spec.Volumes {
+		if volume.Name == "elasticsearch-storage" {
+			template.Spec.Volumes[i].VolumeSource = v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			}
+		}
+	}
+
+	for i, container := range template.Spec.Containers {
+		if container.Name != "elasticsearch" {
+			continue
+		}
+		for j, env := range container.Env {
+			if env.Name == "CLUSTER_NAME" {
+				template.Spec.Containers[i].Env[j].Value = dataCluster
+			}
+		}
+	}
+}
+
+// recoveryClaim is a claim of the source cluster identified by its name, e.g.
+// elasticsearch-elasticsearch-cdm-xm9nl5cb-1 is replica 1 of the cdm node xm9nl5cb
+type recoveryClaim struct {
+	name    string
+	roles   string
+	uuid    string
+	replica int
+}
+
+// parseRecoveryClaim splits the name of a claim of the source cluster, the replica is zero
+// for the claims of statefulsets
+func parseRecoveryClaim(sourceCluster, name string) (recoveryClaim, bool) {
+	prefix := fmt.Sprintf("%s-%s-", sourceCluster, sourceCluster)
+	if !strings.HasPrefix(name, prefix) {
+		return recoveryClaim{}, false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(name, prefix), "-")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || strings.Trim(parts[0], "cdm") != "" {
+		return recoveryClaim{}, false
+	}
+
+	claim := recoveryClaim{name: name, roles: parts[0], uuid: parts[1]}
+	if len(parts) == 3 {
+		replica, err := strconv.Atoi(parts[2])
+		if err != nil || replica < 1 {
+			return recoveryClaim{}, false
+		}
+		claim.replica = replica
+	}
+	return claim, true
+}
+
+// newRecoveryNodes rebuilds the nodes of the UUIDs from the claims of the source cluster. It
+// returns the nodes, the claims they run on and why they can't be rebuilt.
+func newRecoveryNodes(recovery *api.ElasticsearchDataRecovery, claims []v1.PersistentVolumeClaim) ([]api.ElasticsearchNode, []string, string) {
+	byUUID := map[string][]recoveryClaim{}
+	for _, pvc := range claims {
+		if claim, ok := parseRecoveryClaim(dataRecoverySourceCluster(recovery), pvc.Name); ok {
+			byUUID[claim.uuid] = append(byUUID[claim.uuid], claim)
+		}
+	}
+
+	nodes := []api.ElasticsearchNode{}
+	claimNames := []string{}
+	hasMaster := false
+	for _, uuid := range recovery.Spec.UUIDs {
+		found := byUUID[uuid]
+		if len(found) == 0 {
+			return nil, nil, fmt.Sprintf("No claims of cluster %q found for node UUID %s", dataRecoverySourceCluster(recovery), uuid)
+		}
+		sort.Slice(found, func(i, j int) bool { return found[i].replica < found[j].replica })
+
+		roles := found[0].roles
+		isData := strings.Contains(roles, "d")
+		for i, claim := range found {
+			if claim.roles != roles {
+				return nil, nil, fmt.Sprintf("The claims of node UUID %s have different roles", uuid)
+			}
+			// data nodes run a deployment per claim numbered from 1, others a statefulset
+			if (isData && claim.replica != i+1) || (!isData && claim.replica != 0) {
+				return nil, nil, fmt.Sprintf("Claim %s doesn't match the other claims of node UUID %s", claim.name, uuid)
+			}
+			claimNames = append(claimNames, claim.name)
+		}
+
+		node := api.ElasticsearchNode{
+			NodeCount: int32(len(found)),
+			GenUUID:   &[]string{uuid}[0],
+		}
+		if strings.Contains(roles, "c") {
+			node.Roles = append(node.Roles, api.ElasticsearchRoleClient)
+		}
+		if isData {
+			node.Roles = append(node.Roles, api.ElasticsearchRoleData)
+		}
+		if strings.Contains(roles, "m") {
+			node.Roles = append(node.Roles, api.ElasticsearchRoleMaster)
+			hasMaster = true
+		}
+		nodes = append(nodes, node)
+	}
+
+	if !hasMaster {
+		return nil, nil, "The node UUIDs must include a master node"
+	}
+	return nodes, claimNames, ""
+}
+
+// newRecoveryCluster runs the nodes on the claims of the source cluster without replicas
+func newRecoveryCluster(recovery *api.ElasticsearchDataRecovery, nodes []api.ElasticsearchNode) *api.Elasticsearch {
+	cluster := &api.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dataRecoveryClusterName(recovery),
+			Namespace: recovery.Namespace,
+			Annotations: map[string]string{
+				dataClusterNameAnnotation: dataRecoverySourceCluster(recovery),
+			},
+		},
+		Spec: api.ElasticsearchSpec{
+			ManagementState:  api.ManagementStateManaged,
+			RedundancyPolicy: api.ZeroRedundancy,
+			Spec: api.ElasticsearchNodeSpec{
+				Resources: recovery.Spec.Resources,
+			},
+			Nodes: nodes,
+		},
+	}
+	recovery.AddOwnerRefTo(cluster)
+	return cluster
+}
+
+// recoveredIndexName inserts "recovered" into the name of the index so the recovered indices
+// neither clash with nor roll over into the indices of the cluster
+func recoveredIndexName(index string) string {
+	switch {
+	case strings.HasPrefix(index, ".operations."):
+		return ".operations.recovered." + strings.TrimPrefix(index, ".operations.")
+	case strings.HasPrefix(index, "project."):
+		// project.<namespace>.<uid>.YYYY.MM.DD
+		parts := strings.Split(index, ".")
+		if len(parts) > 3 {
+			date := strings.Join(parts[len(parts)-3:], ".")
+			return strings.Join(parts[:len(parts)-3], ".") + ".recovered." + date
+		}
+	default:
+		// <alias>-NNNNNN
+		if i := strings.LastIndex(index, "-"); i > 0 {
+			return index[:i] + "-recovered" + index[i:]
+		}
+	}
+	return index + "-recovered"
+}
+
+// ReconcileDataRecovery runs a recovery cluster on the claims of the data recovery, reindexes
+// its indices into the cluster one at a time and removes the recovery cluster once done
+func ReconcileDataRecovery(recovery *api.ElasticsearchDataRecovery, c client.Client, esClient, recoveryClient elasticsearch.Client) error {
+	if recovery.GetDeletionTimestamp() != nil {
+		return removeDataRecovery(recovery, c, esClient)
+	}
+
+	if !utils.ContainsString(recovery.GetFinalizers(), dataRecoveryFinalizer) {
+		recovery.SetFinalizers(append(recovery.GetFinalizers(), dataRecoveryFinalizer))
+		if err := c.Update(context.TODO(), recovery); err != nil {
+			return kverrors.Wrap(err, "failed to add finalizer to data recovery",
+				"data_recovery", recovery.Name)
+		}
+	}
+
+	if isDataRecoveryDone(recovery.Status.Phase) {
+		return nil
+	}
+
+	status := *recovery.Status.DeepCopy()
+	status.RecoveryClusterName = dataRecoveryClusterName(recovery)
+
+	cluster := &api.Elasticsearch{}
+	key := types.NamespacedName{Name: recovery.Spec.ClusterName, Namespace: recovery.Namespace}
+	if err := c.Get(context.TODO(), key, cluster); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to get elasticsearch cluster",
+				"cluster", recovery.Spec.ClusterName)
+		}
+		status.Phase = api.DataRecoveryPending
+		status.Message = fmt.Sprintf("Elasticsearch cluster %q not found", recovery.Spec.ClusterName)
+		return updateDataRecoveryStatus(recovery, status, c)
+	}
+
+	var err error
+	if status.Phase == api.DataRecoveryReindexing {
+		err = reindexRecoveredIndices(recovery, cluster, &status, c, esClient)
+	} else {
+		err = provisionRecoveryCluster(recovery, cluster, &status, c, recoveryClient)
+	}
+
+	if isDataRecoveryDone(status.Phase) {
+		if teardownErr := removeRecoveryCluster(recovery, c); teardownErr != nil {
+			return teardownErr
+		}
+	}
+
+	if updateErr := updateDataRecoveryStatus(recovery, status, c); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// provisionRecoveryCluster starts the recovery cluster on the claims and lists the indices to
+// reindex once it is up
+func provisionRecoveryCluster(recovery *api.ElasticsearchDataRecovery, cluster *api.Elasticsearch, status *api.ElasticsearchDataRecoveryStatus, c client.Client, recoveryClient elasticsearch.Client) error {
+	source := dataRecoverySourceCluster(recovery)
+	sourceCluster := cluster
+	if source != cluster.Name {
+		sourceCluster = &api.Elasticsearch{}
+		key := types.NamespacedName{Name: source, Namespace: recovery.Namespace}
+		if err := c.Get(context.TODO(), key, sourceCluster); err != nil {
+			if !apierrors.IsNotFound(kverrors.Root(err)) {
+				return kverrors.Wrap(err, "failed to get source elasticsearch cluster",
+					"cluster", source)
+			}
+			sourceCluster = nil
+		}
+	}
+
+	// the claims of the nodes the source cluster still runs are in use
+	if sourceCluster != nil {
+		for _, node := range sourceCluster.Spec.Nodes {
+			if node.GenUUID != nil && utils.ContainsString(recovery.Spec.UUIDs, *node.GenUUID) {
+				status.Phase = api.DataRecoveryFailed
+				status.Message = fmt.Sprintf("Node UUID %s is used by cluster %q", *node.GenUUID, source)
+				return nil
+			}
+		}
+	}
+
+	claims, err := GetPVCList(recovery.Namespace, map[string]string{"logging-cluster": source}, c)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to list the claims of the source cluster",
+			"cluster", source)
+	}
+
+	nodes, claimNames, reason := newRecoveryNodes(recovery, claims.Items)
+	if reason != "" {
+		status.Phase = api.DataRecoveryFailed
+		status.Message = reason
+		return nil
+	}
+	status.Phase = api.DataRecoveryProvisioning
+	status.Claims = claimNames
+
+	secret, err := getSecret(cluster.Name, cluster.Namespace, c)
+	if err != nil {
+		if apierrors.IsNotFound(kverrors.Root(err)) {
+			status.Message = fmt.Sprintf("Waiting for the secret of cluster %q", cluster.Name)
+			return nil
+		}
+		return err
+	}
+
+	// the recovery cluster shares the certificates of the cluster so each trusts the other
+	recoverySecret := newSecret(dataRecoveryClusterName(recovery), recovery.Namespace, secret.Data)
+	recovery.AddOwnerRefTo(recoverySecret)
+	if err := createOrUpdateRecoverySecret(recoverySecret, c); err != nil {
+		return err
+	}
+
+	recoveryCluster := newRecoveryCluster(recovery, nodes)
+	if err := c.Create(context.TODO(), recoveryCluster); err != nil && !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create recovery cluster",
+			"cluster", recoveryCluster.Name)
+	}
+
+	health, err := recoveryClient.GetClusterHealthStatus()
+	if err != nil || (health != "green" && health != "yellow") {
+		status.Message = "Waiting for the recovery cluster to start"
+		return nil
+	}
+
+	reader := &estypes.SecurityRole{
+		Cluster: []string{"CLUSTER_MONITOR", "CLUSTER_COMPOSITE_OPS_RO"},
+		Indices: map[string]map[string][]string{
+			"*": {"*": {"INDICES_MONITOR", "READ"}},
+		},
+	}
+	if err := recoveryClient.PutSecurityRole(dataRecoveryReaderRole, reader); err != nil {
+		return err
+	}
+	mapping := &estypes.SecurityRoleMapping{Users: []string{dataRecoveryReaderDN}}
+	if err := recoveryClient.PutSecurityRoleMapping(dataRecoveryReaderRole, mapping); err != nil {
+		return err
+	}
+
+	patterns := recovery.Spec.Indices
+	if len(patterns) == 0 {
+		patterns = defaultRecoveredIndices
+	}
+	seen := map[string]bool{}
+	indices := []api.ElasticsearchRecoveredIndex{}
+	for _, pattern := range patterns {
+		found, err := recoveryClient.GetAllIndices(pattern)
+		if err != nil {
+			return err
+		}
+		for _, index := range found {
+			if seen[index.Index] {
+				continue
+			}
+			seen[index.Index] = true
+			indices = append(indices, api.ElasticsearchRecoveredIndex{
+				Source:      index.Index,
+				Destination: recoveredIndexName(index.Index),
+				Phase:       api.RecoveredIndexPending,
+			})
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i].Source < indices[j].Source })
+
+	status.Indices = indices
+	if len(indices) == 0 {
+		status.Phase = api.DataRecoveryCompleted
+		status.Message = "No indices to recover found on the claims"
+		return nil
+	}
+	status.Phase = api.DataRecoveryReindexing
+	status.Message = fmt.Sprintf("Waiting for cluster %q to allow reindexing from the recovery cluster", cluster.Name)
+	return nil
+}
+
+func createOrUpdateRecoverySecret(secret *v1.Secret, c client.Client) error {
+	err := c.Create(context.TODO(), secret)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create recovery cluster secret",
+			"secret", secret.Name)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &v1.Secret{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, current); err != nil {
+			return err
+		}
+		if reflect.DeepEqual(current.Data, secret.Data) {
+			return nil
+		}
+		current.Data = secret.Data
+		return c.Update(context.TODO(), current)
+	})
+}
+
+// isReindexHostRolledOut tells whether every node of the cluster runs with the recovery
+// cluster in its reindex whitelist
+func isReindexHostRolledOut(host string, cluster *api.Elasticsearch, c client.Client) (bool, error) {
+	for _, node := range cluster.Status.Nodes {
+		if node.UpgradeStatus.ScheduledForUpgrade == v1.ConditionTrue || node.UpgradeStatus.UnderUpgrade == v1.ConditionTrue {
+			return false, nil
+		}
+	}
+
+	selector := map[string]string{"cluster-name": cluster.Name}
+	templates := []v1.PodTemplateSpec{}
+	deployments, err := GetDeploymentList(cluster.Namespace, selector, c)
+	if err != nil {
+		return false, err
+	}
+	for _, dpl := range deployments.Items {
+		templates = append(templates, dpl.Spec.Template)
+	}
+	statefulSets, err := GetStatefulSetList(cluster.Namespace, selector, c)
+	if err != nil {
+		return false, err
+	}
+	for _, sts := range statefulSets.Items {
+		templates = append(templates, sts.Spec.Template)
+	}
+
+	if len(templates) == 0 {
+		return false, nil
+	}
+	for _, template := range templates {
+		hosts := strings.Split(template.Annotations[reindexRemoteHostsAnnotation], ",")
+		if !utils.ContainsString(hosts, host) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reindexRecoveredIndices follows the reindex task of the running index and starts the next
+// pending index once it is done
+func reindexRecoveredIndices(recovery *api.ElasticsearchDataRecovery, cluster *api.Elasticsearch, status *api.ElasticsearchDataRecoveryStatus, c client.Client, esClient elasticsearch.Client) error {
+	for i := range status.Indices {
+		index := &status.Indices[i]
+		if index.Phase != api.RecoveredIndexRunning {
+			continue
+		}
+
+		task, err := esClient.GetReIndexTask(index.Task)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			// the task is lost when its node restarts, reindexing again overwrites the
+			// documents copied so far
+			index.Phase = api.RecoveredIndexPending
+			index.Task = ""
+			continue
+		}
+		index.Created = task.Task.Status.Created
+		index.Total = task.Task.Status.Total
+		if !task.Completed {
+			status.Message = fmt.Sprintf("Reindexing %s into %s", index.Source, index.Destination)
+			return nil
+		}
+
+		index.Phase = api.RecoveredIndexCompleted
+		index.Message = ""
+		if task.Error != nil {
+			index.Phase = api.RecoveredIndexFailed
+			index.Message = fmt.Sprintf("%v", task.Error["reason"])
+		} else if task.Response != nil && len(task.Response.Failures) > 0 {
+			index.Phase = api.RecoveredIndexFailed
+			index.Message = fmt.Sprintf("%d documents failed to reindex", len(task.Response.Failures))
+		}
+	}
+
+	host := dataRecoveryHost(recovery)
+	for i := range status.Indices {
+		index := &status.Indices[i]
+		if index.Phase != api.RecoveredIndexPending {
+			continue
+		}
+
+		rolledOut, err := isReindexHostRolledOut(host, cluster, c)
+		if err != nil {
+			return err
+		}
+		if !rolledOut {
+			status.Message = fmt.Sprintf("Waiting for cluster %q to allow reindexing from the recovery cluster", cluster.Name)
+			return nil
+		}
+
+		taskID, err := esClient.ReIndexFromRemote("https://"+host, index.Source, index.Destination)
+		if err != nil {
+			return err
+		}
+		index.Phase = api.RecoveredIndexRunning
+		index.Task = taskID
+		status.Message = fmt.Sprintf("Reindexing %s into %s", index.Source, index.Destination)
+		return nil
+	}
+
+	recovered := 0
+	for _, index := range status.Indices {
+		if index.Phase == api.RecoveredIndexCompleted {
+			recovered++
+		}
+	}
+	status.Phase = api.DataRecoveryCompleted
+	status.Message = fmt.Sprintf("Recovered %d of %d indices", recovered, len(status.Indices))
+	return nil
+}
+
+// removeRecoveryCluster deletes the recovery cluster and its secret once the recovery is done
+func removeRecoveryCluster(recovery *api.ElasticsearchDataRecovery, c client.Client) error {
+	meta := metav1.ObjectMeta{Name: dataRecoveryClusterName(recovery), Namespace: recovery.Namespace}
+	for _, obj := range []runtime.Object{
+		&api.Elasticsearch{ObjectMeta: meta},
+		&v1.Secret{ObjectMeta: meta},
+	} {
+		if err := c.Delete(context.TODO(), obj); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to remove recovery cluster",
+				"cluster", meta.Name)
+		}
+	}
+	return nil
+}
+
+// removeDataRecovery cancels the running reindex and releases the data recovery, the recovery
+// cluster is garbage collected with it
+func removeDataRecovery(recovery *api.ElasticsearchDataRecovery, c client.Client, esClient elasticsearch.Client) error {
+	if !utils.ContainsString(recovery.GetFinalizers(), dataRecoveryFinalizer) {
+		return nil
+	}
+
+	for _, index := range recovery.Status.Indices {
+		if index.Phase != api.RecoveredIndexRunning {
+			continue
+		}
+		// the task fails by itself once the recovery cluster is gone
+		if err := esClient.CancelTask(index.Task); err != nil {
+			log.Error(err, "failed to cancel reindex of recovered index",
+				"data_recovery", recovery.Name,
+				"index", index.Source)
+		}
+	}
+
+	recovery.SetFinalizers(utils.RemoveString(recovery.GetFinalizers(), dataRecoveryFinalizer))
+	if err := c.Update(context.TODO(), recovery); err != nil {
+		return kverrors.Wrap(err, "failed to remove finalizer from data recovery",
+			"data_recovery", recovery.Name)
+	}
+	return nil
+}
+
+func updateDataRecoveryStatus(recovery *api.ElasticsearchDataRecovery, status api.ElasticsearchDataRecoveryStatus, c client.Client) error {
+	if reflect.DeepEqual(recovery.Status, status) {
+		return nil
+	}
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &api.ElasticsearchDataRecovery{}
+		key := types.NamespacedName{Name: recovery.Name, Namespace: recovery.Namespace}
+		if err := c.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+
+		current.Status = status
+		return c.Status().Update(context.TODO(), current)
+	})
+	if retryErr != nil {
+		return kverrors.Wrap(retryErr, "failed to update data recovery status",
+			"data_recovery", recovery.Name)
+	}
+
+	recovery.Status = status
+	return nil
+}
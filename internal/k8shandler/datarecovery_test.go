@@ -0,0 +1,332 @@
+package k8shandler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newDataRecovery(uuids ...string) *loggingv1.ElasticsearchDataRecovery {
+	return &loggingv1.ElasticsearchDataRecovery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lost-node",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchDataRecoverySpec{
+			ClusterName: "elasticsearch",
+			UUIDs:       uuids,
+			Indices:     []string{"app-*"},
+		},
+	}
+}
+
+func newRecoveryClaims(names ...string) []v1.PersistentVolumeClaim {
+	claims := []v1.PersistentVolumeClaim{}
+	for _, name := range names {
+		claims = append(claims, v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "openshift-logging",
+				Labels:    map[string]string{"logging-cluster": "elasticsearch"},
+			},
+		})
+	}
+	return claims
+}
+
+func getDataRecovery(t *testing.T, c client.Client) *loggingv1.ElasticsearchDataRecovery {
+	recovery := &loggingv1.ElasticsearchDataRecovery{}
+	key := types.NamespacedName{Name: "lost-node", Namespace: "openshift-logging"}
+	if err := c.Get(context.TODO(), key, recovery); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	return recovery
+}
+
+func TestNewRecoveryNodes(t *testing.T) {
+	tests := []struct {
+		desc   string
+		uuids  []string
+		claims []string
+		nodes  []loggingv1.ElasticsearchNode
+		reason string
+	}{
+		{
+			desc:   "data nodes",
+			uuids:  []string{"abcd1234"},
+			claims: []string{"elasticsearch-elasticsearch-cdm-abcd1234-2", "elasticsearch-elasticsearch-cdm-abcd1234-1", "elasticsearch-elasticsearch-cdm-efgh5678-1"},
+			nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleClient, loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleMaster},
+					NodeCount: 2,
+					GenUUID:   &[]string{"abcd1234"}[0],
+				},
+			},
+		},
+		{
+			desc:   "master statefulset",
+			uuids:  []string{"abcd1234", "efgh5678"},
+			claims: []string{"elasticsearch-elasticsearch-d-abcd1234-1", "elasticsearch-elasticsearch-m-efgh5678"},
+			nodes: []loggingv1.ElasticsearchNode{
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+					NodeCount: 1,
+					GenUUID:   &[]string{"abcd1234"}[0],
+				},
+				{
+					Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster},
+					NodeCount: 1,
+					GenUUID:   &[]string{"efgh5678"}[0],
+				},
+			},
+		},
+		{
+			desc:   "no claims",
+			uuids:  []string{"abcd1234"},
+			claims: []string{"elasticsearch-elasticsearch-cdm-efgh5678-1"},
+			reason: "No claims of cluster \"elasticsearch\" found for node UUID abcd1234",
+		},
+		{
+			desc:   "missing replica",
+			uuids:  []string{"abcd1234"},
+			claims: []string{"elasticsearch-elasticsearch-cdm-abcd1234-2"},
+			reason: "Claim elasticsearch-elasticsearch-cdm-abcd1234-2 doesn't match the other claims of node UUID abcd1234",
+		},
+		{
+			desc:   "no master",
+			uuids:  []string{"abcd1234"},
+			claims: []string{"elasticsearch-elasticsearch-d-abcd1234-1"},
+			reason: "The node UUIDs must include a master node",
+		},
+	}
+
+	for _, test := range tests {
+		nodes, _, reason := newRecoveryNodes(newDataRecovery(test.uuids...), newRecoveryClaims(test.claims...))
+		if reason != test.reason {
+			t.Errorf("%s: got reason %q, want %q", test.desc, reason, test.reason)
+		}
+		if test.reason == "" && !reflect.DeepEqual(nodes, test.nodes) {
+			t.Errorf("%s: got nodes %v, want %v", test.desc, nodes, test.nodes)
+		}
+	}
+}
+
+func TestRecoveredIndexName(t *testing.T) {
+	tests := map[string]string{
+		"app-000001":                     "app-recovered-000001",
+		"infra-000002":                   "infra-recovered-000002",
+		"project.my-app.1234.2020.10.01": "project.my-app.1234.recovered.2020.10.01",
+		".operations.2020.10.01":         ".operations.recovered.2020.10.01",
+		"custom":                         "custom-recovered",
+	}
+
+	for index, want := range tests {
+		if got := recoveredIndexName(index); got != want {
+			t.Errorf("%s: got %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestUseDataOfCluster(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "lost-node-recovery",
+			Annotations: map[string]string{dataClusterNameAnnotation: "elasticsearch"},
+		},
+	}
+	template := &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{Name: "elasticsearch", Env: []v1.EnvVar{{Name: "CLUSTER_NAME", Value: "lost-node-recovery"}}},
+			},
+			Volumes: []v1.Volume{
+				{Name: "elasticsearch-storage", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+
+	useDataOfCluster(template, cluster, "lost-node-recovery-cdm-abcd1234-1")
+
+	claim := template.Spec.Volumes[0].PersistentVolumeClaim
+	if claim == nil || claim.ClaimName != "elasticsearch-elasticsearch-cdm-abcd1234-1" {
+		t.Errorf("expected the storage to be the claim of the source node, got %v", template.Spec.Volumes[0].VolumeSource)
+	}
+	if got := template.Spec.Containers[0].Env[0].Value; got != "elasticsearch" {
+		t.Errorf("expected the cluster name of the source cluster, got %q", got)
+	}
+}
+
+func TestGetReindexRemoteHosts(t *testing.T) {
+	running := newDataRecovery("abcd1234")
+	completed := newDataRecovery("efgh5678")
+	completed.Name = "done"
+	completed.Status.Phase = loggingv1.DataRecoveryCompleted
+	other := newDataRecovery("ijkl9012")
+	other.Name = "other"
+	other.Spec.ClusterName = "other"
+
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), nil, running, completed, other)
+	got := getReindexRemoteHosts("elasticsearch", "openshift-logging", er.client)
+	want := []string{"lost-node-recovery.openshift-logging.svc:9200"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got hosts %v, want %v", got, want)
+	}
+}
+
+func TestRenderEsYmlReindexWhitelist(t *testing.T) {
+	hosts := []string{"lost-node-recovery.openshift-logging.svc:9200"}
+
+	result := &bytes.Buffer{}
+	if err := renderEsYml(result, "", "my.unicast.host", "2", "3", "false", nil, hosts, false); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	config := struct {
+		Reindex struct {
+			Whitelist []string `yaml:"remote.whitelist"`
+			SSL       struct {
+				VerificationMode string `yaml:"verification_mode"`
+			} `yaml:"ssl"`
+		} `yaml:"reindex"`
+	}{}
+	if err := yaml.Unmarshal(result.Bytes(), &config); err != nil {
+		t.Fatalf("expected a valid elasticsearch.yml: %s", err)
+	}
+	if !reflect.DeepEqual(config.Reindex.Whitelist, hosts) {
+		t.Errorf("got whitelist %v, want %v", config.Reindex.Whitelist, hosts)
+	}
+	if config.Reindex.SSL.VerificationMode != "certificate" {
+		t.Errorf("got verification mode %q", config.Reindex.SSL.VerificationMode)
+	}
+}
+
+func TestReconcileDataRecovery(t *testing.T) {
+	esChatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_reindex?wait_for_completion=false": {{StatusCode: http.StatusOK, Body: `{"task": "node:1"}`}},
+		"_tasks/node:1":                      {{StatusCode: http.StatusOK, Body: `{"completed": true, "task": {"status": {"total": 10, "created": 10}}, "response": {"failures": []}}`}},
+	})
+	recovery := newDataRecovery("abcd1234")
+	secret := newSecret("elasticsearch", "openshift-logging", map[string][]byte{"admin-ca": []byte("ca")})
+	claims := newRecoveryClaims("elasticsearch-elasticsearch-cdm-abcd1234-1")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), esChatter, recovery, secret, &claims[0])
+	c, esClient := er.client, er.esClient
+
+	recoveryChatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/health": {{StatusCode: http.StatusOK, Body: `{"status": "green"}`}},
+		"_opendistro/_security/api/roles/data-recovery-reader":        {{StatusCode: http.StatusOK, Body: "{}"}},
+		"_opendistro/_security/api/rolesmapping/data-recovery-reader": {{StatusCode: http.StatusOK, Body: "{}"}},
+		"_cat/indices/app-*?format=json":                              {{StatusCode: http.StatusOK, Body: `[{"index": "app-000001"}]`}},
+	})
+	recoveryClient := testhelpers.NewFakeElasticsearchClient("lost-node-recovery", "openshift-logging", c, recoveryChatter)
+
+	if err := ReconcileDataRecovery(recovery, c, esClient, recoveryClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	recovery = getDataRecovery(t, c)
+	if recovery.Status.Phase != loggingv1.DataRecoveryReindexing {
+		t.Fatalf("expected the recovery to reindex, got %s: %s", recovery.Status.Phase, recovery.Status.Message)
+	}
+	want := []loggingv1.ElasticsearchRecoveredIndex{
+		{Source: "app-000001", Destination: "app-recovered-000001", Phase: loggingv1.RecoveredIndexPending},
+	}
+	if !reflect.DeepEqual(recovery.Status.Indices, want) {
+		t.Errorf("got indices %v, want %v", recovery.Status.Indices, want)
+	}
+
+	recoveryCluster := &loggingv1.Elasticsearch{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "lost-node-recovery", Namespace: "openshift-logging"}, recoveryCluster); err != nil {
+		t.Fatalf("expected the recovery cluster to be created, got err: %s", err)
+	}
+	if got := recoveryCluster.Annotations[dataClusterNameAnnotation]; got != "elasticsearch" {
+		t.Errorf("expected the recovery cluster to use the data of the cluster, got %q", got)
+	}
+
+	// the cluster still runs without the recovery cluster in its whitelist
+	dpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-efgh5678-1",
+			Namespace: "openshift-logging",
+			Labels:    map[string]string{"cluster-name": "elasticsearch"},
+		},
+	}
+	if err := c.Create(context.TODO(), dpl); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if err := ReconcileDataRecovery(recovery, c, esClient, recoveryClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if recovery = getDataRecovery(t, c); recovery.Status.Indices[0].Phase != loggingv1.RecoveredIndexPending {
+		t.Fatalf("expected the reindex to wait for the whitelist, got %s", recovery.Status.Indices[0].Phase)
+	}
+
+	dpl.Spec.Template.Annotations = map[string]string{reindexRemoteHostsAnnotation: "lost-node-recovery.openshift-logging.svc:9200"}
+	if err := c.Update(context.TODO(), dpl); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := ReconcileDataRecovery(recovery, c, esClient, recoveryClient); err != nil {
+			t.Fatalf("got err: %s", err)
+		}
+	}
+
+	recovery = getDataRecovery(t, c)
+	if recovery.Status.Phase != loggingv1.DataRecoveryCompleted || recovery.Status.Message != "Recovered 1 of 1 indices" {
+		t.Errorf("expected the recovery to complete, got %s: %s", recovery.Status.Phase, recovery.Status.Message)
+	}
+	if index := recovery.Status.Indices[0]; index.Phase != loggingv1.RecoveredIndexCompleted || index.Created != 10 {
+		t.Errorf("expected the index to be recovered, got %v", index)
+	}
+	request, _ := esChatter.GetRequest("_reindex?wait_for_completion=false")
+	if request == nil || !strings.Contains(request.Body, `"host":"https://lost-node-recovery.openshift-logging.svc:9200"`) {
+		t.Errorf("expected the index to be reindexed from the recovery cluster, got %v", request)
+	}
+
+	err := c.Get(context.TODO(), types.NamespacedName{Name: "lost-node-recovery", Namespace: "openshift-logging"}, recoveryCluster)
+	if err == nil {
+		t.Errorf("expected the recovery cluster to be removed")
+	}
+}
+
+func TestReconcileDataRecoveryRejectsUsedUUIDs(t *testing.T) {
+	uuid := "abcd1234"
+	used := loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{{GenUUID: &uuid}}}
+
+	for _, source := range []string{"", "elasticsearch-old"} {
+		recovery := newDataRecovery(uuid)
+		recovery.Spec.SourceClusterName = source
+		var er *ElasticsearchRequest
+		if source == "" {
+			er = newTestRequest(newTestCluster(used), nil, recovery)
+		} else {
+			sourceCluster := newTestCluster(used)
+			sourceCluster.Name = source
+			er = newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), nil, recovery, sourceCluster)
+		}
+
+		if err := ReconcileDataRecovery(recovery, er.client, er.esClient, er.esClient); err != nil {
+			t.Fatalf("got err: %s", err)
+		}
+
+		recovery = getDataRecovery(t, er.client)
+		if recovery.Status.Phase != loggingv1.DataRecoveryFailed || !strings.Contains(recovery.Status.Message, "is used by cluster") {
+			t.Errorf("source %q: expected the recovery to fail, got %s: %s", source, recovery.Status.Phase, recovery.Status.Message)
+		}
+		if !reflect.DeepEqual(recovery.Finalizers, []string{dataRecoveryFinalizer}) {
+			t.Errorf("source %q: expected the finalizer to be added, got %v", source, recovery.Finalizers)
+		}
+	}
+}
@@ -2,14 +2,12 @@ package k8shandler
 
 import (
 	"context"
-	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -65,8 +63,9 @@ func (node *deploymentNode) populateReference(nodeName string, n api.Elasticsear
 		},
 		ProgressDeadlineSeconds: &progressDeadlineSeconds,
 		Paused:                  false,
-		Template:                newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, n, cluster.Spec.Spec, labels, roleMap, client, logConfig),
+		Template:                newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, n, cluster.Spec.Spec, labels, roleMap, client, logConfig, cluster.Spec.Proxy),
 	}
+	useDataOfCluster(&deployment.Spec.Template, cluster, nodeName)
 
 	cluster.AddOwnerRefTo(&deployment)
 
@@ -140,39 +139,31 @@ func (node *deploymentNode) create() error {
 		if err != nil {
 			if !apierrors.IsAlreadyExists(err) {
 				return kverrors.Wrap(err, "could not create node resource")
-			} else {
-				return node.pause()
 			}
+		} else {
+			// update the hashmaps
+			node.configmapHash = getConfigmapDataHash(node.clusterName, node.self.Namespace, node.client)
+			node.secretHash = getSecretDataHash(node.clusterName, node.self.Namespace, node.client)
 		}
+	}
 
-		// created unpaused, pause after deployment...
-		// wait until we have a revision annotation...
-		if err = node.waitForInitialRollout(); err != nil {
-			return err
-		}
-
-		// update the hashmaps
-		node.configmapHash = getConfigmapDataHash(node.clusterName, node.self.Namespace, node.client)
-		node.secretHash = getSecretDataHash(node.clusterName, node.self.Namespace, node.client)
+	// created unpaused, pause after the initial rollout started. Until then
+	// we leave it to a later reconcile instead of waiting for it here
+	if !node.isInitialRolloutStarted() {
+		return nil
 	}
 
 	return node.pause()
 }
 
-func (node *deploymentNode) waitForInitialRollout() error {
-	err := wait.Poll(time.Second*1, time.Second*30, func() (done bool, err error) {
-		if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, &node.self); err != nil {
-			return false, err
-		}
-
-		_, ok := node.self.ObjectMeta.Annotations["deployment.kubernetes.io/revision"]
-		if ok {
-			return true, nil
-		}
+func (node *deploymentNode) isInitialRolloutStarted() bool {
+	current := &apps.Deployment{}
+	if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, current); err != nil {
+		return false
+	}
 
-		return false, nil
-	})
-	return err
+	_, ok := current.ObjectMeta.Annotations["deployment.kubernetes.io/revision"]
+	return ok
 }
 
 func (node *deploymentNode) nodeRevision() string {
@@ -185,18 +176,7 @@ func (node *deploymentNode) nodeRevision() string {
 	return ""
 }
 
-func (node *deploymentNode) waitForNodeRollout() error {
-	podLabels := map[string]string{
-		"node-name": node.name(),
-	}
-
-	err := wait.Poll(time.Second*1, time.Second*30, func() (done bool, err error) {
-		return node.checkPodSpecMatches(podLabels), nil
-	})
-	return err
-}
-
-func (node *deploymentNode) podSpecMatches() bool {
+func (node *deploymentNode) isRolledOut() bool {
 	podLabels := map[string]string{
 		"node-name": node.name(),
 	}
@@ -212,7 +192,8 @@ func (node *deploymentNode) checkPodSpecMatches(labels map[string]string) bool {
 	}
 
 	for _, pod := range podList.Items {
-		if !ArePodSpecDifferent(pod.Spec, node.self.Spec.Template.Spec, false) {
+		if !ArePodSpecDifferent(pod.Spec, node.self.Spec.Template.Spec, false) &&
+			areRolloutAnnotationsSame(pod.Annotations, node.self.Spec.Template.Annotations) {
 			return true
 		}
 	}
@@ -314,22 +295,38 @@ func (node *deploymentNode) replicaCount() (int32, error) {
 	return nodeCopy.Status.Replicas, nil
 }
 
+// waitForNodeRejoinCluster does not block but returns ErrWaitingForCluster
+// while the node has not rejoined the cluster yet
 func (node *deploymentNode) waitForNodeRejoinCluster() (bool, error) {
-	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
-		return node.esClient.IsNodeInCluster(node.name())
-	})
+	inCluster, err := node.esClient.IsNodeInCluster(node.name())
+	if err != nil {
+		return false, err
+	}
+
+	if !inCluster {
+		return false, kverrors.Wrap(ErrWaitingForCluster, "waiting for node to rejoin the cluster",
+			"node", node.name(),
+		)
+	}
 
-	return err == nil, err
+	return true, nil
 }
 
+// waitForNodeLeaveCluster does not block but returns ErrWaitingForCluster
+// while the node is still part of the cluster
 func (node *deploymentNode) waitForNodeLeaveCluster() (bool, error) {
-	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
-		inCluster, checkErr := node.esClient.IsNodeInCluster(node.name())
+	inCluster, err := node.esClient.IsNodeInCluster(node.name())
+	if err != nil {
+		return false, err
+	}
 
-		return !inCluster, checkErr
-	})
+	if inCluster {
+		return false, kverrors.Wrap(ErrWaitingForCluster, "waiting for node to leave the cluster",
+			"node", node.name(),
+		)
+	}
 
-	return err == nil, err
+	return true, nil
 }
 
 func (node *deploymentNode) isMissing() bool {
@@ -367,37 +364,47 @@ func (node *deploymentNode) executeUpdate() error {
 	})
 }
 
-func (node *deploymentNode) progressNodeChanges() error {
-	if !node.isChanged() && node.podSpecMatches() {
-		return nil
-	}
+func (node *deploymentNode) holdsData() bool {
+	return true
+}
 
-	if err := node.executeUpdate(); err != nil {
-		return err
-	}
+// holdRollout pauses the deployment so that updating its template does not restart the pod
+func (node *deploymentNode) holdRollout(replicas int32) error {
+	return node.pause()
+}
 
-	if err := node.unpause(); err != nil {
-		return kverrors.Wrap(err, "unable to unpause node",
-			"node", node.name(),
-		)
+// releaseRollout unpauses the deployment so that its single pod is recreated with the new template
+func (node *deploymentNode) releaseRollout(ordinal int32) error {
+	return node.unpause()
+}
+
+// releasedOrdinal is 0 while the deployment is unpaused, i.e. its pod was released
+func (node *deploymentNode) releasedOrdinal(replicas int32) (int32, error) {
+	current := &apps.Deployment{}
+	if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, current); err != nil {
+		return -1, err
 	}
 
-	if err := node.waitForNodeRollout(); err != nil {
-		return kverrors.New("timed out waiting for node to rollout",
-			"node", node.name(),
-		)
+	if current.Spec.Paused {
+		return replicas, nil
 	}
 
-	if err := node.pause(); err != nil {
-		return kverrors.Wrap(err, "unable to pause node",
+	return 0, nil
+}
+
+func (node *deploymentNode) waitForPodRollout() error {
+	if !node.isRolledOut() {
+		return kverrors.Wrap(ErrWaitingForCluster, "waiting for node to rollout",
 			"node", node.name(),
 		)
 	}
-
-	node.refreshHashes()
 	return nil
 }
 
+func (node *deploymentNode) completeRollout() error {
+	return node.pause()
+}
+
 func (node *deploymentNode) refreshHashes() {
 	newConfigmapHash := getConfigmapDataHash(node.clusterName, node.self.Namespace, node.client)
 	if newConfigmapHash != node.configmapHash {
@@ -410,6 +417,33 @@ func (node *deploymentNode) refreshHashes() {
 	}
 }
 
+func (node *deploymentNode) isProxyOnlyChange() bool {
+	current := apps.Deployment{}
+	if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, &current); err != nil {
+		return false
+	}
+
+	return isProxyOnlyChange(current.Spec.Template, node.self.Spec.Template)
+}
+
+func (node *deploymentNode) storageChange() (string, string) {
+	current := apps.Deployment{}
+	if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, &current); err != nil {
+		return "", ""
+	}
+
+	return podTemplateStorage(current.Spec.Template), podTemplateStorage(node.self.Spec.Template)
+}
+
+func (node *deploymentNode) imageChange() (string, string) {
+	current := apps.Deployment{}
+	if err := node.client.Get(context.TODO(), types.NamespacedName{Name: node.self.Name, Namespace: node.self.Namespace}, &current); err != nil {
+		return "", ""
+	}
+
+	return podTemplateImage(current.Spec.Template), podTemplateImage(node.self.Spec.Template)
+}
+
 func (node *deploymentNode) isChanged() bool {
 	desiredTemplate := node.self.Spec.Template
 	currentDeployment := apps.Deployment{}
@@ -1,9 +1,17 @@
 package k8shandler
 
 import (
+	"errors"
+
+	"github.com/ViaQ/logerr/kverrors"
 	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler/migrations"
 )
 
+// MigrationsDryRunAnnotation set to "true" only reports the migrations of the data and settings
+// that would run in the status instead of running them
+const MigrationsDryRunAnnotation = "logging.openshift.io/migrations-dry-run"
+
 // this function should be called before we try doing operations to make sure all our nodes are
 // first ready
 func (er *ElasticsearchRequest) ClusterReady() bool {
@@ -49,7 +57,8 @@ func (er *ElasticsearchRequest) updateMinMasters() {
 		er.L().Info("Unable to get current min master count")
 	}
 
-	desiredMasterCount := getMasterCount(er.cluster)/2 + 1
+	// replacement nodes of storage migrations join next to the master nodes they replace
+	desiredMasterCount := (getMasterCount(er.cluster)+er.migratingMasterCount())/2 + 1
 	currentNodeCount, err := er.esClient.GetClusterNodeCount()
 	if err != nil {
 		er.L().Error(err, "Unable to get cluster node count")
@@ -92,6 +101,28 @@ func (er *ElasticsearchRequest) updateReplicas() {
 	}
 }
 
+// runMigrations runs the pending migrations of the data and settings of the cluster and reports
+// them in the status. Once all completed they aren't checked again until the operator restarts,
+// which is when new migrations ship.
+func (er *ElasticsearchRequest) runMigrations() {
+	if er.state().migrationsCompleted || !er.ClusterReady() {
+		return
+	}
+
+	dryRun := er.cluster.Annotations[MigrationsDryRunAnnotation] == "true"
+	statuses, err := migrations.NewMigrationRequest(er.client, er.esClient).RunElasticsearchMigrations(dryRun)
+	er.cluster.Status.Migrations = statuses
+	if errors.Is(err, migrations.ErrMigrationInProgress) {
+		er.L().Info("Waiting for migration to continue", "reason", kverrors.Message(err))
+		return
+	}
+	if err != nil {
+		er.L().Error(err, "Unable to run migrations")
+		return
+	}
+	er.state().migrationsCompleted = !dryRun
+}
+
 func (er *ElasticsearchRequest) updatePrimaryShards() {
 	if er.ClusterReady() {
 		primaryCount := int32(calculatePrimaryCount(er.cluster))
@@ -0,0 +1,65 @@
+package k8shandler
+
+import (
+	"github.com/ViaQ/logerr/kverrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler/exposure"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
+)
+
+// CreateOrUpdateExposure exposes the proxy in front of the client nodes outside of the cluster
+// as defined by spec.exposure and removes the exposure once it was unset
+func (er *ElasticsearchRequest) CreateOrUpdateExposure() error {
+	dpl := er.cluster
+
+	if spec := dpl.Spec.Exposure; spec != nil && spec.Type == api.ExposureRoute && !er.capabilities.Has(platform.Routes) {
+		return kverrors.New("exposure with a Route requires the route.openshift.io API",
+			"cluster", dpl.Name,
+			"namespace", dpl.Namespace)
+	}
+
+	target, err := er.exposureTarget()
+	if err != nil {
+		return err
+	}
+
+	if err := exposure.Reconcile(er.client, er.apiReader, dpl.Spec.Exposure, target); err != nil {
+		return kverrors.Wrap(err, "failed to reconcile exposure",
+			"cluster", dpl.Name,
+			"namespace", dpl.Namespace)
+	}
+	return nil
+}
+
+func (er *ElasticsearchRequest) exposureTarget() (exposure.Target, error) {
+	dpl := er.cluster
+
+	owner := &metav1.ObjectMeta{}
+	dpl.AddOwnerRefTo(owner)
+
+	target := exposure.Target{
+		Name:        dpl.Name,
+		Namespace:   dpl.Namespace,
+		Labels:      appendDefaultLabel(dpl.Name, map[string]string{}),
+		ServiceName: dpl.Name,
+		ServicePort: restAPIPort,
+		Selector:    selectorForES("es-node-client", dpl.Name),
+		TargetPort:  intstr.FromString("restapi"),
+		Owner:       owner.OwnerReferences[0],
+	}
+
+	if dpl.Spec.Exposure == nil {
+		return target, nil
+	}
+
+	secret, err := getSecret(dpl.Name, dpl.Namespace, er.client)
+	if err != nil {
+		return target, kverrors.Wrap(err, "failed to get the CA of the cluster",
+			"secret", dpl.Name)
+	}
+	target.CACert = secret.Data[adminCAKey]
+	return target, nil
+}
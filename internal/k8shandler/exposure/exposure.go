@@ -0,0 +1,480 @@
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ViaQ/logerr/kverrors"
+	route "github.com/openshift/api/route/v1"
+	v1 "k8s.io/api/core/v1"
+	// Ingress is only served by networking.k8s.io/v1 from Kubernetes 1.19 on, the vendored
+	// API of 1.18 has it in v1beta1 which is removed in 1.22
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+const caCertKey = "ca.crt"
+
+// Target is the service of a component exposed outside of the cluster
+type Target struct {
+	// Name of the Route or Ingress, LoadBalancer services and the CA secret of the
+	// Ingress are named after it
+	Name      string
+	Namespace string
+	Labels    map[string]string
+
+	// The service in front of the component
+	ServiceName string
+	ServicePort int32
+
+	// The pods and their port served by a LoadBalancer service
+	Selector   map[string]string
+	TargetPort intstr.IntOrString
+
+	// The managed CA the router or ingress controller trusts when reencrypting
+	CACert []byte
+
+	Owner metav1.OwnerReference
+}
+
+// LoadBalancerName is the name of the LoadBalancer service of the target
+func (t Target) LoadBalancerName() string {
+	return fmt.Sprintf("%s-external", t.Name)
+}
+
+// IngressCAName is the name of the secret holding the CA the ingress controller trusts
+func (t Target) IngressCAName() string {
+	return fmt.Sprintf("%s-ingress-ca", t.Name)
+}
+
+func (t Target) serviceHost() string {
+	return fmt.Sprintf("%s.%s.svc", t.ServiceName, t.Namespace)
+}
+
+// Validate ensures the spec can be rendered for the target
+func Validate(spec *api.ExposureSpec) error {
+	if spec == nil {
+		return nil
+	}
+
+	switch spec.Type {
+	case api.ExposureRoute:
+	case api.ExposureIngress:
+		if spec.Host == "" {
+			return kverrors.New("exposure with an Ingress requires a host")
+		}
+	case api.ExposureLoadBalancer:
+		if spec.Termination == api.ExposureReencrypt {
+			return kverrors.New("exposure with a LoadBalancer only supports Passthrough termination")
+		}
+	default:
+		return kverrors.New("unknown exposure type", "type", spec.Type)
+	}
+	return nil
+}
+
+// Reconcile exposes the target as described by the spec and removes the resources of the
+// other exposure types. A nil spec removes all of them. The resources to remove are looked up
+// with the reader r, an uncached reader keeps the operator from watching the kinds of the
+// exposure types it never created.
+func Reconcile(c client.Client, r client.Reader, spec *api.ExposureSpec, target Target) error {
+	if err := Validate(spec); err != nil {
+		return err
+	}
+
+	var exposureType api.ExposureType
+	if spec != nil {
+		exposureType = spec.Type
+	}
+
+	if exposureType != api.ExposureRoute {
+		if err := remove(c, r, &route.Route{}, target.Name, target); err != nil {
+			return err
+		}
+	}
+	if exposureType != api.ExposureIngress {
+		if err := remove(c, r, &networking.Ingress{}, target.Name, target); err != nil {
+			return err
+		}
+	}
+	if exposureType != api.ExposureIngress || termination(spec) != api.ExposureReencrypt {
+		if err := remove(c, r, &v1.Secret{}, target.IngressCAName(), target); err != nil {
+			return err
+		}
+	}
+	if exposureType != api.ExposureLoadBalancer {
+		if err := remove(c, r, &v1.Service{}, target.LoadBalancerName(), target); err != nil {
+			return err
+		}
+	}
+
+	switch exposureType {
+	case api.ExposureRoute:
+		return createOrUpdateRoute(c, NewRoute(spec, target))
+	case api.ExposureIngress:
+		if termination(spec) == api.ExposureReencrypt {
+			if err := createOrUpdateSecret(c, newIngressCASecret(target)); err != nil {
+				return err
+			}
+		}
+		return createOrUpdateIngress(c, NewIngress(spec, target))
+	case api.ExposureLoadBalancer:
+		return createOrUpdateService(c, NewLoadBalancer(spec, target))
+	}
+	return nil
+}
+
+// URL returns the external URL of the target or an empty string while it is not known yet,
+// e.g. until the cloud provider assigned an address to the load balancer
+func URL(c client.Client, spec *api.ExposureSpec, target Target) (string, error) {
+	if spec == nil {
+		return "", nil
+	}
+
+	switch spec.Type {
+	case api.ExposureRoute:
+		current := &route.Route{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: target.Name, Namespace: target.Namespace}, current); err != nil {
+			return "", kverrors.Wrap(err, "failed to get route", "route", target.Name)
+		}
+		return fmt.Sprintf("https://%s", current.Spec.Host), nil
+	case api.ExposureIngress:
+		return fmt.Sprintf("https://%s", spec.Host), nil
+	case api.ExposureLoadBalancer:
+		current := &v1.Service{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: target.LoadBalancerName(), Namespace: target.Namespace}, current); err != nil {
+			return "", kverrors.Wrap(err, "failed to get load balancer service", "service", target.LoadBalancerName())
+		}
+		for _, ingress := range current.Status.LoadBalancer.Ingress {
+			host := ingress.Hostname
+			if host == "" {
+				host = ingress.IP
+			}
+			if host == "" {
+				continue
+			}
+			if target.ServicePort == 443 {
+				return fmt.Sprintf("https://%s", host), nil
+			}
+			return fmt.Sprintf("https://%s:%d", host, target.ServicePort), nil
+		}
+	}
+	return "", nil
+}
+
+func termination(spec *api.ExposureSpec) api.ExposureTermination {
+	if spec == nil || spec.Type == api.ExposureLoadBalancer {
+		return api.ExposurePassthrough
+	}
+	if spec.Termination == "" {
+		return api.ExposureReencrypt
+	}
+	return spec.Termination
+}
+
+func newObjectMeta(name string, target Target, annotations map[string]string) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   target.Namespace,
+		Labels:      target.Labels,
+		Annotations: annotations,
+	}
+	utils.AddOwnerRefToObject(&objectMeta, target.Owner)
+	return objectMeta
+}
+
+// newAnnotations returns the annotations of the spec on top of the defaults
+func newAnnotations(defaults map[string]string, spec *api.ExposureSpec) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range defaults {
+		annotations[k] = v
+	}
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+// NewRoute returns the Route exposing the target
+func NewRoute(spec *api.ExposureSpec, target Target) *route.Route {
+	tls := &route.TLSConfig{
+		Termination:                   route.TLSTerminationReencrypt,
+		InsecureEdgeTerminationPolicy: route.InsecureEdgeTerminationPolicyRedirect,
+		CACertificate:                 string(target.CACert),
+		DestinationCACertificate:      string(target.CACert),
+	}
+	if termination(spec) == api.ExposurePassthrough {
+		tls = &route.TLSConfig{
+			Termination:                   route.TLSTerminationPassthrough,
+			InsecureEdgeTerminationPolicy: route.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	return &route.Route{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Route",
+			APIVersion: route.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: newObjectMeta(target.Name, target, newAnnotations(nil, spec)),
+		Spec: route.RouteSpec{
+			Host: spec.Host,
+			To: route.RouteTargetReference{
+				Name: target.ServiceName,
+				Kind: "Service",
+			},
+			TLS: tls,
+		},
+	}
+}
+
+// NewIngress returns the Ingress exposing the target. Reencrypting and passing TLS through
+// isn't part of the Ingress API, the annotations of ingress-nginx are set by default.
+func NewIngress(spec *api.ExposureSpec, target Target) *networking.Ingress {
+	defaults := map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+	}
+	tls := []networking.IngressTLS{
+		{
+			Hosts:      []string{spec.Host},
+			SecretName: spec.TLSSecretName,
+		},
+	}
+
+	if termination(spec) == api.ExposurePassthrough {
+		defaults["nginx.ingress.kubernetes.io/ssl-passthrough"] = "true"
+		tls = nil
+	} else {
+		defaults["nginx.ingress.kubernetes.io/proxy-ssl-verify"] = "on"
+		defaults["nginx.ingress.kubernetes.io/proxy-ssl-secret"] = fmt.Sprintf("%s/%s", target.Namespace, target.IngressCAName())
+		defaults["nginx.ingress.kubernetes.io/proxy-ssl-name"] = target.serviceHost()
+	}
+
+	return &networking.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: newObjectMeta(target.Name, target, newAnnotations(defaults, spec)),
+		Spec: networking.IngressSpec{
+			IngressClassName: spec.IngressClassName,
+			TLS:              tls,
+			Rules: []networking.IngressRule{
+				{
+					Host: spec.Host,
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: networking.IngressBackend{
+										ServiceName: target.ServiceName,
+										ServicePort: intstr.FromInt(int(target.ServicePort)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewLoadBalancer returns the LoadBalancer service exposing the pods of the target, clients
+// connect to the pods directly
+func NewLoadBalancer(spec *api.ExposureSpec, target Target) *v1.Service {
+	return &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: newObjectMeta(target.LoadBalancerName(), target, newAnnotations(nil, spec)),
+		Spec: v1.ServiceSpec{
+			Type:     v1.ServiceTypeLoadBalancer,
+			Selector: target.Selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:       "https",
+					Protocol:   v1.ProtocolTCP,
+					Port:       target.ServicePort,
+					TargetPort: target.TargetPort,
+				},
+			},
+		},
+	}
+}
+
+func newIngressCASecret(target Target) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: newObjectMeta(target.IngressCAName(), target, nil),
+		Data: map[string][]byte{
+			caCertKey: target.CACert,
+		},
+	}
+}
+
+// mergeAnnotations adds the desired annotations to the object and reports whether it changed,
+// annotations added by others, e.g. the router or the cloud provider, are kept
+func mergeAnnotations(objectMeta *metav1.ObjectMeta, desired map[string]string) bool {
+	changed := false
+	for k, v := range desired {
+		if current, ok := objectMeta.Annotations[k]; ok && current == v {
+			continue
+		}
+		if objectMeta.Annotations == nil {
+			objectMeta.Annotations = map[string]string{}
+		}
+		objectMeta.Annotations[k] = v
+		changed = true
+	}
+	return changed
+}
+
+func createOrUpdateRoute(c client.Client, desired *route.Route) error {
+	return createOrUpdate(c, desired, &route.Route{}, func(current runtime.Object) bool {
+		cur := current.(*route.Route)
+		changed := mergeAnnotations(&cur.ObjectMeta, desired.Annotations)
+		if !reflect.DeepEqual(cur.Spec.TLS, desired.Spec.TLS) {
+			cur.Spec.TLS = desired.Spec.TLS
+			changed = true
+		}
+		if desired.Spec.Host != "" && cur.Spec.Host != desired.Spec.Host {
+			cur.Spec.Host = desired.Spec.Host
+			changed = true
+		}
+		return changed
+	})
+}
+
+func createOrUpdateIngress(c client.Client, desired *networking.Ingress) error {
+	return createOrUpdate(c, desired, &networking.Ingress{}, func(current runtime.Object) bool {
+		cur := current.(*networking.Ingress)
+		changed := mergeAnnotations(&cur.ObjectMeta, desired.Annotations)
+		if !reflect.DeepEqual(cur.Spec, desired.Spec) {
+			cur.Spec = desired.Spec
+			changed = true
+		}
+		return changed
+	})
+}
+
+func createOrUpdateService(c client.Client, desired *v1.Service) error {
+	return createOrUpdate(c, desired, &v1.Service{}, func(current runtime.Object) bool {
+		cur := current.(*v1.Service)
+		changed := mergeAnnotations(&cur.ObjectMeta, desired.Annotations)
+
+		// keep the node ports assigned to the service
+		ports := desired.Spec.Ports
+		for i := range ports {
+			for _, port := range cur.Spec.Ports {
+				if port.Name == ports[i].Name {
+					ports[i].NodePort = port.NodePort
+				}
+			}
+		}
+		if !reflect.DeepEqual(cur.Spec.Selector, desired.Spec.Selector) || !reflect.DeepEqual(cur.Spec.Ports, ports) {
+			cur.Spec.Selector = desired.Spec.Selector
+			cur.Spec.Ports = ports
+			changed = true
+		}
+		return changed
+	})
+}
+
+func createOrUpdateSecret(c client.Client, desired *v1.Secret) error {
+	return createOrUpdate(c, desired, &v1.Secret{}, func(current runtime.Object) bool {
+		cur := current.(*v1.Secret)
+		if reflect.DeepEqual(cur.Data, desired.Data) {
+			return false
+		}
+		cur.Data = desired.Data
+		return true
+	})
+}
+
+// createOrUpdate creates the desired object or lets mutate bring the current one in line
+// with it, mutate returns false if the current object is up to date
+func createOrUpdate(c client.Client, desired runtime.Object, current runtime.Object, mutate func(runtime.Object) bool) error {
+	desiredMeta, err := meta.Accessor(desired)
+	if err != nil {
+		return err
+	}
+	kind := desired.GetObjectKind().GroupVersionKind().Kind
+
+	err = c.Create(context.TODO(), desired)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create exposure",
+			"kind", kind,
+			"name", desiredMeta.GetName())
+	}
+
+	key := types.NamespacedName{Name: desiredMeta.GetName(), Namespace: desiredMeta.GetNamespace()}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+		if !mutate(current) {
+			return nil
+		}
+		return c.Update(context.TODO(), current)
+	})
+	if err != nil {
+		return kverrors.Wrap(err, "failed to update exposure",
+			"kind", kind,
+			"name", desiredMeta.GetName())
+	}
+	return nil
+}
+
+// remove deletes the object if it exists and is owned by the owner of the target. Clusters
+// without the API of the object, e.g. Routes on Kubernetes, have nothing to remove.
+func remove(c client.Client, r client.Reader, obj runtime.Object, name string, target Target) error {
+	err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: target.Namespace}, obj)
+	if err != nil {
+		root := kverrors.Root(err)
+		if apierrors.IsNotFound(root) || meta.IsNoMatchError(root) || runtime.IsNotRegisteredError(root) {
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to get exposure", "name", name)
+	}
+
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	owned := false
+	for _, ref := range objMeta.GetOwnerReferences() {
+		if ref.UID == target.Owner.UID {
+			owned = true
+		}
+	}
+	if !owned {
+		return nil
+	}
+
+	if err := c.Delete(context.TODO(), obj); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to delete exposure", "name", name)
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package exposure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ViaQ/logerr/kverrors"
+	routev1 "github.com/openshift/api/route/v1"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func newTarget() Target {
+	return Target{
+		Name:        "kibana",
+		Namespace:   "openshift-logging",
+		ServiceName: "kibana",
+		ServicePort: 443,
+		Selector:    map[string]string{"component": "kibana"},
+		TargetPort:  intstr.FromString("oaproxy"),
+		CACert:      []byte("ca"),
+		Owner: metav1.OwnerReference{
+			APIVersion: loggingv1.GroupVersion.String(),
+			Kind:       "Kibana",
+			Name:       "kibana",
+			UID:        "kibana-uid",
+		},
+	}
+}
+
+func newClient(objs ...runtime.Object) client.Client {
+	_ = routev1.AddToScheme(scheme.Scheme)
+	return fake.NewFakeClient(objs...)
+}
+
+func exists(t *testing.T, c client.Client, name string, obj runtime.Object) bool {
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "openshift-logging"}, obj)
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatalf("got err: %s", err)
+	}
+	return err == nil
+}
+
+func TestReconcileSwitchesExposureType(t *testing.T) {
+	c := newClient()
+	target := newTarget()
+
+	if err := Reconcile(c, c, &loggingv1.ExposureSpec{Type: loggingv1.ExposureRoute}, target); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	rt := &routev1.Route{}
+	if !exists(t, c, "kibana", rt) {
+		t.Fatalf("expected the route to be created")
+	}
+	if rt.Spec.TLS.Termination != routev1.TLSTerminationReencrypt || rt.Spec.TLS.DestinationCACertificate != "ca" {
+		t.Errorf("expected a reencrypt route trusting the CA, got %+v", rt.Spec.TLS)
+	}
+
+	spec := &loggingv1.ExposureSpec{Type: loggingv1.ExposureIngress, Host: "kibana.example.com"}
+	if err := Reconcile(c, c, spec, target); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if exists(t, c, "kibana", &routev1.Route{}) {
+		t.Errorf("expected the route to be removed")
+	}
+	if !exists(t, c, "kibana", &networking.Ingress{}) {
+		t.Errorf("expected the ingress to be created")
+	}
+	secret := &v1.Secret{}
+	if !exists(t, c, "kibana-ingress-ca", secret) || string(secret.Data["ca.crt"]) != "ca" {
+		t.Errorf("expected the CA secret of the ingress to be created, got %v", secret.Data)
+	}
+
+	if err := Reconcile(c, c, &loggingv1.ExposureSpec{Type: loggingv1.ExposureLoadBalancer}, target); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if exists(t, c, "kibana", &networking.Ingress{}) || exists(t, c, "kibana-ingress-ca", &v1.Secret{}) {
+		t.Errorf("expected the ingress and its CA secret to be removed")
+	}
+	svc := &v1.Service{}
+	if !exists(t, c, "kibana-external", svc) || svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+		t.Errorf("expected the load balancer service to be created, got %+v", svc.Spec)
+	}
+
+	if err := Reconcile(c, c, nil, target); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if exists(t, c, "kibana-external", &v1.Service{}) {
+		t.Errorf("expected the load balancer service to be removed")
+	}
+}
+
+func TestReconcileKeepsObjectsNotOwned(t *testing.T) {
+	rt := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "kibana", Namespace: "openshift-logging"},
+	}
+	c := newClient(rt)
+
+	if err := Reconcile(c, c, nil, newTarget()); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if !exists(t, c, "kibana", &routev1.Route{}) {
+		t.Errorf("expected the route not created by the operator to be kept")
+	}
+}
+
+// cachedClient fails every read as looking up the resources to remove must not go through the cache
+type cachedClient struct {
+	client.Client
+}
+
+func (c *cachedClient) Get(_ context.Context, key client.ObjectKey, _ runtime.Object) error {
+	return kverrors.New("unexpected read from the cache", "name", key.Name)
+}
+
+func TestReconcileRemovesWithReader(t *testing.T) {
+	owner := newTarget().Owner
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "kibana-external",
+			Namespace:       "openshift-logging",
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+	}
+	reader := newClient(svc)
+
+	if err := Reconcile(&cachedClient{Client: reader}, reader, nil, newTarget()); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if exists(t, reader, "kibana-external", &v1.Service{}) {
+		t.Errorf("expected the load balancer service to be removed")
+	}
+}
+
+func TestReconcileMergesAnnotations(t *testing.T) {
+	desired := NewRoute(&loggingv1.ExposureSpec{Type: loggingv1.ExposureRoute}, newTarget())
+	desired.Annotations = map[string]string{"openshift.io/host.generated": "true"}
+	c := newClient(desired)
+
+	spec := &loggingv1.ExposureSpec{
+		Type:        loggingv1.ExposureRoute,
+		Termination: loggingv1.ExposurePassthrough,
+		Annotations: map[string]string{"haproxy.router.openshift.io/timeout": "5m"},
+	}
+	if err := Reconcile(c, c, spec, newTarget()); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	rt := &routev1.Route{}
+	exists(t, c, "kibana", rt)
+	if rt.Spec.TLS.Termination != routev1.TLSTerminationPassthrough {
+		t.Errorf("expected the route to pass TLS through, got %+v", rt.Spec.TLS)
+	}
+	if len(rt.Annotations) != 2 {
+		t.Errorf("expected the annotations to be merged, got %v", rt.Annotations)
+	}
+}
+
+func TestNewIngress(t *testing.T) {
+	tests := []struct {
+		desc        string
+		spec        loggingv1.ExposureSpec
+		annotations map[string]string
+		tls         bool
+	}{
+		{
+			desc: "reencrypt",
+			spec: loggingv1.ExposureSpec{Type: loggingv1.ExposureIngress, Host: "kibana.example.com", TLSSecretName: "kibana-tls"},
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+				"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on",
+				"nginx.ingress.kubernetes.io/proxy-ssl-secret": "openshift-logging/kibana-ingress-ca",
+				"nginx.ingress.kubernetes.io/proxy-ssl-name":   "kibana.openshift-logging.svc",
+			},
+			tls: true,
+		},
+		{
+			desc: "passthrough",
+			spec: loggingv1.ExposureSpec{
+				Type:        loggingv1.ExposureIngress,
+				Host:        "kibana.example.com",
+				Termination: loggingv1.ExposurePassthrough,
+				Annotations: map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTP"},
+			},
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+				"nginx.ingress.kubernetes.io/ssl-passthrough":  "true",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		spec := test.spec
+		ingress := NewIngress(&spec, newTarget())
+
+		if len(ingress.Annotations) != len(test.annotations) {
+			t.Errorf("%s: got annotations %v, want %v", test.desc, ingress.Annotations, test.annotations)
+		}
+		for k, v := range test.annotations {
+			if ingress.Annotations[k] != v {
+				t.Errorf("%s: got annotation %s=%q, want %q", test.desc, k, ingress.Annotations[k], v)
+			}
+		}
+		if (len(ingress.Spec.TLS) > 0) != test.tls {
+			t.Errorf("%s: got TLS %v, want TLS %t", test.desc, ingress.Spec.TLS, test.tls)
+		}
+		backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend
+		if backend.ServiceName != "kibana" || backend.ServicePort.IntValue() != 443 {
+			t.Errorf("%s: got backend %+v", test.desc, backend)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		desc  string
+		spec  *loggingv1.ExposureSpec
+		valid bool
+	}{
+		{desc: "not exposed", valid: true},
+		{desc: "route", spec: &loggingv1.ExposureSpec{Type: loggingv1.ExposureRoute}, valid: true},
+		{desc: "ingress without host", spec: &loggingv1.ExposureSpec{Type: loggingv1.ExposureIngress}},
+		{desc: "load balancer", spec: &loggingv1.ExposureSpec{Type: loggingv1.ExposureLoadBalancer}, valid: true},
+		{
+			desc: "reencrypting load balancer",
+			spec: &loggingv1.ExposureSpec{Type: loggingv1.ExposureLoadBalancer, Termination: loggingv1.ExposureReencrypt},
+		},
+		{desc: "unknown type", spec: &loggingv1.ExposureSpec{Type: "NodePort"}},
+	}
+
+	for _, test := range tests {
+		if err := Validate(test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: got err %v, want valid %t", test.desc, err, test.valid)
+		}
+	}
+}
+
+func TestURL(t *testing.T) {
+	target := newTarget()
+	lb := NewLoadBalancer(&loggingv1.ExposureSpec{Type: loggingv1.ExposureLoadBalancer}, target)
+	c := newClient(lb)
+
+	spec := &loggingv1.ExposureSpec{Type: loggingv1.ExposureLoadBalancer}
+	if url, err := URL(c, spec, target); err != nil || url != "" {
+		t.Errorf("expected no URL until an address is assigned, got %q, %v", url, err)
+	}
+
+	lb.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "192.0.2.10"}}
+	if err := c.Status().Update(context.TODO(), lb); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if url, err := URL(c, spec, target); err != nil || url != "https://192.0.2.10" {
+		t.Errorf("got %q, %v, want https://192.0.2.10", url, err)
+	}
+
+	spec = &loggingv1.ExposureSpec{Type: loggingv1.ExposureIngress, Host: "kibana.example.com"}
+	if url, _ := URL(c, spec, target); url != "https://kibana.example.com" {
+		t.Errorf("got %q, want https://kibana.example.com", url)
+	}
+}
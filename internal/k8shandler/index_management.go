@@ -15,10 +15,18 @@ import (
 
 func (er *ElasticsearchRequest) CreateOrUpdateIndexManagement() error {
 	cluster := er.cluster
-	if cluster.Spec.IndexManagement == nil {
+	auditMapping := auditLogMapping(cluster.Spec.AuditLog)
+	if cluster.Spec.IndexManagement == nil && auditMapping == nil {
 		return nil
 	}
 	spec := indexmanagement.VerifyAndNormalize(cluster)
+	if spec == nil {
+		spec = &logging.IndexManagementSpec{}
+	}
+	// the audit index is rolled over and deleted like the indices of the other mappings
+	if auditMapping != nil {
+		spec.Mappings = append(spec.Mappings, *auditMapping)
+	}
 	policies := spec.PolicyMap()
 	if er.AnyNodeReady() {
 		er.cullIndexManagement(spec.Mappings, policies)
@@ -11,6 +11,7 @@ import (
 	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/test/helpers"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -149,7 +150,7 @@ var _ = Describe("Reconciling", func() {
 			})
 
 			It("should create one new console link for the Kibana route", func() {
-				Expect(Reconcile(cluster, client, esClient, proxy)).Should(Succeed())
+				Expect(Reconcile(cluster, client, client, esClient, proxy, platform.Capabilities{})).Should(Succeed())
 
 				key := types.NamespacedName{Name: KibanaConsoleLinkName}
 				got := &consolev1.ConsoleLink{}
@@ -160,6 +161,29 @@ var _ = Describe("Reconciling", func() {
 			})
 		})
 
+		Context("when the platform serves neither routes nor the console", func() {
+			BeforeEach(func() {
+				client = fake.NewFakeClient(
+					cluster,
+					kibanaCABundle,
+					kibanaSecret,
+					kibanaProxySecret,
+				)
+				esClient = newFakeEsClient(client, fakeResponses)
+			})
+
+			It("should neither create a route nor console links", func() {
+				capabilities := platform.Capabilities{}.Without(platform.Routes, platform.Console, platform.ClusterProxy)
+				Expect(Reconcile(cluster, client, client, esClient, nil, capabilities)).Should(Succeed())
+
+				key := types.NamespacedName{Name: "kibana", Namespace: cluster.GetNamespace()}
+				Expect(errors.IsNotFound(client.Get(context.TODO(), key, &routev1.Route{}))).To(BeTrue())
+
+				key = types.NamespacedName{Name: KibanaConsoleLinkName}
+				Expect(errors.IsNotFound(client.Get(context.TODO(), key, &consolev1.ConsoleLink{}))).To(BeTrue())
+			})
+		})
+
 		Context("when updating kibana on an existing cluster", func() {
 			var (
 				sharingConfigMap = NewConfigMap(
@@ -210,7 +234,7 @@ var _ = Describe("Reconciling", func() {
 			})
 
 			It("should replace existing sharing confimap links with one console link", func() {
-				Expect(Reconcile(cluster, client, esClient, nil)).Should(Succeed())
+				Expect(Reconcile(cluster, client, client, esClient, nil, platform.Capabilities{})).Should(Succeed())
 
 				key := types.NamespacedName{Name: KibanaConsoleLinkName}
 				got := &consolev1.ConsoleLink{}
@@ -281,7 +305,7 @@ var _ = Describe("Reconciling", func() {
 
 			It("should use the default CA bundle in kibana proxy", func() {
 				// Reconcile w/o custom CA bundle
-				Expect(Reconcile(cluster, client, esClient, proxy)).Should(Succeed())
+				Expect(Reconcile(cluster, client, client, esClient, proxy, platform.Capabilities{})).Should(Succeed())
 
 				key := types.NamespacedName{Name: constants.KibanaTrustedCAName, Namespace: cluster.GetNamespace()}
 				kibanaCaBundle := &corev1.ConfigMap{}
@@ -302,7 +326,7 @@ var _ = Describe("Reconciling", func() {
 
 			It("should use the injected custom CA bundle in kibana proxy", func() {
 				// Reconcile w/o custom CA bundle
-				Expect(Reconcile(cluster, client, esClient, proxy)).Should(Succeed())
+				Expect(Reconcile(cluster, client, client, esClient, proxy, platform.Capabilities{})).Should(Succeed())
 
 				// Inject custom CA bundle into kibana config map
 				injectedCABundle := kibanaCABundle.DeepCopy()
@@ -311,7 +335,7 @@ var _ = Describe("Reconciling", func() {
 
 				// Reconcile with injected custom CA bundle
 				esClient = newFakeEsClient(client, fakeResponses)
-				Expect(Reconcile(cluster, client, esClient, proxy)).Should(Succeed())
+				Expect(Reconcile(cluster, client, client, esClient, proxy, platform.Capabilities{})).Should(Succeed())
 
 				key := types.NamespacedName{Name: cluster.GetName(), Namespace: cluster.GetNamespace()}
 				dpl := &appsv1.Deployment{}
@@ -5,6 +5,7 @@ import (
 
 	kibana "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -12,9 +13,11 @@ import (
 )
 
 type KibanaRequest struct {
-	client   client.Client
-	cluster  *kibana.Kibana
-	esClient elasticsearch.Client
+	client       client.Client
+	apiReader    client.Reader
+	cluster      *kibana.Kibana
+	esClient     elasticsearch.Client
+	capabilities platform.Capabilities
 }
 
 // TODO: determine if this is even necessary
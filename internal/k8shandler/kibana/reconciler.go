@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/constants"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/internal/k8shandler/migrations"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
@@ -38,11 +39,13 @@ var kibanaServiceAccountAnnotations = map[string]string{
 	"serviceaccounts.openshift.io/oauth-redirectreference.first": kibanaOAuthRedirectReference,
 }
 
-func Reconcile(requestCluster *kibana.Kibana, requestClient client.Client, esClient elasticsearch.Client, proxyConfig *configv1.Proxy) error {
+func Reconcile(requestCluster *kibana.Kibana, requestClient client.Client, apiReader client.Reader, esClient elasticsearch.Client, proxyConfig *configv1.Proxy, capabilities platform.Capabilities) error {
 	clusterKibanaRequest := KibanaRequest{
-		client:   requestClient,
-		cluster:  requestCluster,
-		esClient: esClient,
+		client:       requestClient,
+		apiReader:    apiReader,
+		cluster:      requestCluster,
+		esClient:     esClient,
+		capabilities: capabilities,
 	}
 
 	migrationRequest := migrations.NewMigrationRequest(requestClient, esClient)
@@ -71,7 +74,7 @@ func Reconcile(requestCluster *kibana.Kibana, requestClient client.Client, esCli
 	// we only want to create these if the use case is the CLO one
 	// make sure our namespace is "openshift-logging" and our cr name is "kibana"
 	// or do we just check that our owner ref is from a cluster logging object?
-	if clusterKibanaRequest.isCLOUseCase() {
+	if clusterKibanaRequest.isCLOUseCase() && capabilities.Has(platform.Console) {
 		if err := clusterKibanaRequest.createOrUpdateKibanaConsoleExternalLogLink(); err != nil {
 			return err
 		}
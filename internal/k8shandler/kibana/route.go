@@ -3,13 +3,18 @@ package kibana
 import (
 	"fmt"
 	"io/ioutil"
+	"path"
 	"reflect"
 	"strings"
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
+	kibana "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler/exposure"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/util/retry"
 
 	consolev1 "github.com/openshift/api/console/v1"
@@ -122,41 +127,81 @@ func (clusterRequest *KibanaRequest) CreateOrUpdateRoute(newRoute *route.Route)
 	return nil
 }
 
-func (clusterRequest *KibanaRequest) createOrUpdateKibanaRoute() error {
+// kibanaExposure returns how Kibana is exposed, a reencrypt Route unless configured otherwise.
+// Kibana isn't exposed by default on platforms without routes.
+func (clusterRequest *KibanaRequest) kibanaExposure() *kibana.ExposureSpec {
+	if spec := clusterRequest.cluster.Spec.Exposure; spec != nil {
+		return spec
+	}
+	if !clusterRequest.capabilities.Has(platform.Routes) {
+		return nil
+	}
+	return &kibana.ExposureSpec{Type: kibana.ExposureRoute}
+}
+
+func (clusterRequest *KibanaRequest) kibanaExposureTarget() exposure.Target {
 	cluster := clusterRequest.cluster
 
-	var rt *route.Route
-	fp := utils.GetWorkingDirFilePath("ca.crt")
+	fp := utils.GetWorkingDirFilePath(path.Join(cluster.Namespace, "ca.crt"))
 	caCert, err := ioutil.ReadFile(fp)
 	if err != nil {
 		log.Info("could not read CA certificate for kibana route",
 			"filePath", fp,
 			"cause", err)
 	}
-	rt = NewRouteWithCert(
-		"kibana",
-		cluster.Namespace,
-		"kibana",
-		caCert,
-	)
 
-	utils.AddOwnerRefToObject(rt, getOwnerRef(cluster))
+	return exposure.Target{
+		Name:      "kibana",
+		Namespace: cluster.Namespace,
+		Labels: map[string]string{
+			"component":     "support",
+			"logging-infra": "support",
+			"provider":      "openshift",
+		},
+		ServiceName: "kibana",
+		ServicePort: 443,
+		Selector: map[string]string{
+			"component": "kibana",
+			"provider":  "openshift",
+		},
+		TargetPort: intstr.FromString("oaproxy"),
+		CACert:     caCert,
+		Owner:      getOwnerRef(cluster),
+	}
+}
+
+func (clusterRequest *KibanaRequest) createOrUpdateKibanaRoute() error {
+	cluster := clusterRequest.cluster
 
-	err = clusterRequest.CreateOrUpdateRoute(rt)
-	if err != nil && !apierrors.IsAlreadyExists(kverrors.Root(err)) {
-		return kverrors.Wrap(err, "failed to update Kibana route for cluster",
+	spec := clusterRequest.kibanaExposure()
+	if spec != nil && spec.Type == kibana.ExposureRoute && !clusterRequest.capabilities.Has(platform.Routes) {
+		return kverrors.New("exposure with a Route requires the route.openshift.io API",
+			"cluster", cluster.Name)
+	}
+
+	err := exposure.Reconcile(clusterRequest.client, clusterRequest.apiReader, spec, clusterRequest.kibanaExposureTarget())
+	if err != nil {
+		return kverrors.Wrap(err, "failed to update Kibana exposure for cluster",
 			"cluster", cluster.Name)
 	}
 
 	return nil
 }
 
+// getKibanaURL returns the external URL of Kibana or an empty string while it is not known yet
+func (clusterRequest *KibanaRequest) getKibanaURL() (string, error) {
+	return exposure.URL(clusterRequest.client, clusterRequest.kibanaExposure(), clusterRequest.kibanaExposureTarget())
+}
+
 func (clusterRequest *KibanaRequest) createOrUpdateKibanaConsoleLink() error {
 	cluster := clusterRequest.cluster
 
-	kibanaURL, err := clusterRequest.GetRouteURL("kibana")
+	kibanaURL, err := clusterRequest.getKibanaURL()
 	if err != nil {
-		return kverrors.Wrap(err, "failed to get route URL for kibana")
+		return kverrors.Wrap(err, "failed to get URL for kibana")
+	}
+	if kibanaURL == "" {
+		return nil
 	}
 
 	cl := NewConsoleLink(KibanaConsoleLinkName, kibanaURL)
@@ -210,9 +255,12 @@ func (clusterRequest *KibanaRequest) createOrUpdateKibanaConsoleExternalLogLink(
 	errCtx := kverrors.NewContext("cluster", clusterRequest.cluster.Name,
 		"namespace", clusterRequest.cluster.Namespace)
 
-	kibanaURL, err := clusterRequest.GetRouteURL("kibana")
+	kibanaURL, err := clusterRequest.getKibanaURL()
 	if err != nil {
-		return kverrors.Wrap(err, "failed to get route URL", errCtx...)
+		return kverrors.Wrap(err, "failed to get URL", errCtx...)
+	}
+	if kibanaURL == "" {
+		return nil
 	}
 	errCtx = append(errCtx, "kibana_url", kibanaURL)
 
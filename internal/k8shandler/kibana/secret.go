@@ -1,6 +1,7 @@
 package kibana
 
 import (
+	"path"
 	"sort"
 
 	"github.com/ViaQ/logerr/kverrors"
@@ -85,7 +86,8 @@ func (clusterRequest *KibanaRequest) extractSecretToFile(secretName string, key
 		return nil
 	}
 
-	return utils.WriteToWorkingDirFile(toFile, value)
+	// keep the files of kibanas in different namespaces apart
+	return utils.WriteToWorkingDirFile(path.Join(clusterRequest.cluster.Namespace, toFile), value)
 }
 
 func calcSecretHashValue(secret *core.Secret) (string, error) {
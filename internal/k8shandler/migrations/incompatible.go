@@ -0,0 +1,227 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+// FindIncompatibleIndices returns the indices and the version of Elasticsearch they were
+// created with that the target version can't read. Elasticsearch reads indices of the
+// previous major version only.
+func FindIncompatibleIndices(esClient elasticsearch.Client, targetVersion string) (map[string]string, error) {
+	targetMajor, err := strconv.Atoi(utils.GetMajorVersion(targetVersion))
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to parse target version",
+			"version", targetVersion)
+	}
+
+	versions, err := esClient.GetIndexVersions("*")
+	if err != nil {
+		return nil, err
+	}
+
+	incompatible := map[string]string{}
+	for index, version := range versions {
+		major, err := strconv.Atoi(utils.GetMajorVersion(version))
+		if err != nil {
+			return nil, kverrors.Wrap(err, "failed to parse index version",
+				"index", index,
+				"version", version)
+		}
+		if major < targetMajor-1 {
+			incompatible[index] = version
+		}
+	}
+	return incompatible, nil
+}
+
+// reindexableIndices are the indices incompatible with the packaged version of Elasticsearch
+// in alphabetical order, the indices of the operator and Kibana are migrated on their own
+func (mr *migrationRequest) reindexableIndices() ([]string, error) {
+	target := constants.PackagedElasticsearchVersion()
+	lowest, err := mr.esClient.GetLowestClusterVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// indices reindexed by nodes older than the previous major version stay incompatible,
+	// the nodes need to be upgraded to it first
+	if majorVersion(lowest)+1 < majorVersion(target) {
+		log.Info("Skipping reindex of incompatible indices until the nodes run the previous major version",
+			"current_version", lowest,
+			"target_version", target)
+		return nil, nil
+	}
+
+	incompatible, err := FindIncompatibleIndices(mr.esClient, target)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := []string{}
+	for index := range incompatible {
+		if !strings.HasPrefix(index, ".") {
+			indices = append(indices, index)
+		}
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+func (mr *migrationRequest) hasIncompatibleIndices() (bool, error) {
+	indices, err := mr.reindexableIndices()
+	return len(indices) > 0, err
+}
+
+// reindexIncompatibleIndices reindexes the incompatible indices one at a time, e.g. app-000001
+// into app-000001-reindexed-v7, which takes over the name and aliases of its source
+func (mr *migrationRequest) reindexIncompatibleIndices() error {
+	indices, err := mr.reindexableIndices()
+	if err != nil {
+		return err
+	}
+
+	suffix := fmt.Sprintf("-reindexed-v%s", utils.GetMajorVersion(constants.PackagedElasticsearchVersion()))
+	for _, index := range indices {
+		if err := mr.reindexIncompatibleIndex(index, index+suffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reindexIncompatibleIndex blocks writes to the index and reindexes it in the background. The
+// task is recorded in the migrations index to follow it on the next runs. Once every document
+// was copied the index is removed and its name and aliases point to the destination.
+func (mr *migrationRequest) reindexIncompatibleIndex(index, destination string) error {
+	id := "reindex-" + index
+	doc, err := mr.esClient.GetDocument(migrationsIndex, id)
+	if err != nil {
+		return err
+	}
+
+	if doc != nil {
+		taskID, _ := doc["task"].(string)
+		task, err := mr.esClient.GetReIndexTask(taskID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case task == nil:
+			// the task is lost when its node restarts, reindexing again overwrites the
+			// documents copied so far
+			log.Info("Restarting lost reindex of incompatible index", "index", index, "task", taskID)
+		case !task.Completed:
+			return kverrors.Wrap(ErrMigrationInProgress, "reindexing incompatible index",
+				"index", index,
+				"destination", destination,
+				"copied", task.Task.Status.Created+task.Task.Status.Updated,
+				"total", task.Task.Status.Total)
+		case task.Error != nil:
+			return mr.forgetReindexTask(id, kverrors.New("failed to reindex incompatible index",
+				"index", index,
+				"reason", task.Error["reason"]))
+		case task.Response != nil && len(task.Response.Failures) > 0:
+			return mr.forgetReindexTask(id, kverrors.New("failed to reindex documents of incompatible index",
+				"index", index,
+				"failures", len(task.Response.Failures)))
+		default:
+			return mr.replaceIncompatibleIndex(index, destination)
+		}
+	}
+
+	settings := &estypes.IndexSettings{
+		Index: &estypes.IndexingSettings{
+			Blocks: &estypes.IndexBlocksSettings{Write: true},
+		},
+	}
+	if err := mr.esClient.UpdateIndexSettings(index, settings); err != nil {
+		return kverrors.Wrap(err, "failed to block writes to incompatible index",
+			"index", index)
+	}
+
+	reIndex := &estypes.ReIndex{
+		Source: estypes.IndexRef{Index: index},
+		Dest:   estypes.IndexRef{Index: destination},
+	}
+	taskID, err := mr.esClient.StartReIndex(reIndex, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := mr.ensureMigrationsIndex(); err != nil {
+		return err
+	}
+	task := map[string]string{
+		"task":        taskID,
+		"destination": destination,
+	}
+	if err := mr.esClient.IndexDocument(migrationsIndex, id, task); err != nil {
+		return err
+	}
+
+	return kverrors.Wrap(ErrMigrationInProgress, "started reindex of incompatible index",
+		"index", index,
+		"destination", destination,
+		"task", taskID)
+}
+
+// forgetReindexTask deletes the record of the failed task so that the next run reindexes the
+// index again and returns the failure
+func (mr *migrationRequest) forgetReindexTask(id string, failure error) error {
+	if err := mr.esClient.DeleteDocument(migrationsIndex, id); err != nil {
+		log.Error(err, "Failed to delete record of failed reindex task", "id", id)
+	}
+	return failure
+}
+
+// replaceIncompatibleIndex removes the index and adds its name and aliases to the destination
+// in a single request, so searches never see both or neither. The aliases keep their filters,
+// routing and write index.
+func (mr *migrationRequest) replaceIncompatibleIndex(index, destination string) error {
+	aliases, err := mr.esClient.GetIndexAliases(index)
+	if err != nil {
+		return err
+	}
+
+	actions := estypes.AliasActions{
+		Actions: []estypes.AliasAction{
+			{RemoveIndex: &estypes.RemoveAliasAction{Index: index}},
+			{Add: &estypes.AddAliasAction{Index: destination, Alias: index}},
+		},
+	}
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		actions.Actions = append(actions.Actions, estypes.AliasAction{
+			Add: &estypes.AddAliasAction{Index: destination, Alias: alias, AliasDefinition: aliases[alias]},
+		})
+	}
+	if err := mr.esClient.UpdateAlias(actions); err != nil {
+		return kverrors.Wrap(err, "failed to replace incompatible index",
+			"index", index,
+			"destination", destination)
+	}
+
+	log.Info("Replaced incompatible index", "index", index, "destination", destination)
+	return nil
+}
+
+// majorVersion returns the major version of the version, zero if it can't be parsed
+func majorVersion(version string) int {
+	major, _ := strconv.Atoi(utils.GetMajorVersion(version))
+	return major
+}
@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/test/helpers"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Reindexing incompatible indices", func() {
+	defer GinkgoRecover()
+
+	var (
+		chatter   *helpers.FakeElasticsearchChatter
+		client    elasticsearch.Client
+		k8sClient = fake.NewFakeClient()
+	)
+
+	const (
+		esCluster   = "elasticsearch"
+		esNamespace = "openshift-logging"
+		statsURI    = "_cluster/stats/nodes/_all"
+		versionsURI = "*/_settings/index.version.created?flat_settings=true"
+		taskDocURI  = ".operator-migrations/_doc/reindex-app-old"
+		stats       = `{"nodes": {"versions": ["5.6.16"]}}`
+		versions    = `{
+			"app-old": {"settings": {"index.version.created": "2040699"}},
+			"app-new": {"settings": {"index.version.created": "5061699"}},
+			".kibana": {"settings": {"index.version.created": "2040699"}}
+		}`
+	)
+
+	reindexMigration := elasticsearchMigrations[1]
+
+	It("should find the indices created two major versions before the target", func() {
+		chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			versionsURI: {{StatusCode: http.StatusOK, Body: versions}},
+		})
+		client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+		incompatible, err := FindIncompatibleIndices(client, "6.8.1")
+		Expect(err).To(BeNil())
+		Expect(incompatible).To(Equal(map[string]string{"app-old": "2.4.6", ".kibana": "2.4.6"}))
+	})
+
+	It("should skip while the nodes are older than the previous major version", func() {
+		chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			statsURI: {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["2.4.6"]}}`}},
+		})
+		client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+		kr := migrationRequest{client: k8sClient, esClient: client}
+
+		status, err := kr.runMigration(reindexMigration, false)
+		Expect(err).To(BeNil())
+		Expect(status.Phase).To(Equal(api.MigrationSkipped))
+	})
+
+	It("should block writes and start the reindex in the background", func() {
+		chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			statsURI:                             {{StatusCode: http.StatusOK, Body: stats}, {StatusCode: http.StatusOK, Body: stats}},
+			versionsURI:                          {{StatusCode: http.StatusOK, Body: versions}, {StatusCode: http.StatusOK, Body: versions}},
+			taskDocURI:                           {{StatusCode: http.StatusNotFound, Body: `{"found": false}`}},
+			"app-old/_settings":                  {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+			"_reindex?wait_for_completion=false": {{StatusCode: http.StatusOK, Body: `{"task": "node:1"}`}},
+			".operator-migrations":               {{StatusCode: http.StatusOK, Body: `{}`}},
+			taskDocURI + "?refresh=true":         {{StatusCode: http.StatusCreated, Body: `{"result": "created"}`}},
+		})
+		client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+		kr := migrationRequest{client: k8sClient, esClient: client}
+
+		status, err := kr.runMigration(reindexMigration, false)
+		Expect(errors.Is(err, ErrMigrationInProgress)).To(BeTrue())
+		Expect(status.Phase).To(Equal(api.MigrationPending))
+
+		req, found := chatter.GetRequest("app-old/_settings")
+		Expect(found).To(BeTrue())
+		Expect(req.Body).To(MatchJSON(`{"index": {"blocks": {"write": true}}}`))
+
+		req, found = chatter.GetRequest("_reindex?wait_for_completion=false")
+		Expect(found).To(BeTrue())
+		Expect(req.Body).To(MatchJSON(`{"source": {"index": "app-old"}, "dest": {"index": "app-old-reindexed-v6"}}`))
+
+		req, found = chatter.GetRequest(taskDocURI + "?refresh=true")
+		Expect(found).To(BeTrue())
+		Expect(req.Body).To(MatchJSON(`{"task": "node:1", "destination": "app-old-reindexed-v6"}`))
+	})
+
+	It("should replace the index by the destination once the reindex completed", func() {
+		chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			statsURI:        {{StatusCode: http.StatusOK, Body: stats}, {StatusCode: http.StatusOK, Body: stats}},
+			versionsURI:     {{StatusCode: http.StatusOK, Body: versions}, {StatusCode: http.StatusOK, Body: versions}},
+			taskDocURI:      {{StatusCode: http.StatusOK, Body: `{"found": true, "_source": {"task": "node:1", "destination": "app-old-reindexed-v6"}}`}},
+			"_tasks/node:1": {{StatusCode: http.StatusOK, Body: `{"completed": true, "task": {"status": {"total": 10, "created": 10}}}`}},
+			"app-old/_alias": {{StatusCode: http.StatusOK, Body: `{"app-old": {"aliases": {
+				"app": {},
+				"app-write": {"is_write_index": true},
+				"app-team-a": {"filter": {"term": {"team": "a"}}, "index_routing": "a", "search_routing": "a"}
+			}}}`}},
+			"_aliases": {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+		})
+		client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+		kr := migrationRequest{client: k8sClient, esClient: client}
+
+		status, err := kr.runMigration(reindexMigration, false)
+		Expect(err).To(BeNil())
+		Expect(status.Phase).To(Equal(api.MigrationCompleted))
+
+		req, found := chatter.GetRequest("_aliases")
+		Expect(found).To(BeTrue())
+		Expect(req.Body).To(MatchJSON(`{"actions": [
+			{"remove_index": {"index": "app-old"}},
+			{"add": {"index": "app-old-reindexed-v6", "alias": "app-old"}},
+			{"add": {"index": "app-old-reindexed-v6", "alias": "app"}},
+			{"add": {"index": "app-old-reindexed-v6", "alias": "app-team-a", "filter": {"term": {"team": "a"}}, "index_routing": "a", "search_routing": "a"}},
+			{"add": {"index": "app-old-reindexed-v6", "alias": "app-write", "is_write_index": true}}
+		]}`))
+	})
+
+	It("should forget the failed task to reindex again on the next run", func() {
+		chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+			statsURI:                     {{StatusCode: http.StatusOK, Body: stats}, {StatusCode: http.StatusOK, Body: stats}},
+			versionsURI:                  {{StatusCode: http.StatusOK, Body: versions}, {StatusCode: http.StatusOK, Body: versions}},
+			taskDocURI:                   {{StatusCode: http.StatusOK, Body: `{"found": true, "_source": {"task": "node:1", "destination": "app-old-reindexed-v6"}}`}},
+			"_tasks/node:1":              {{StatusCode: http.StatusOK, Body: `{"completed": true, "error": {"reason": "node left"}}`}},
+			taskDocURI + "?refresh=true": {{StatusCode: http.StatusOK, Body: `{"result": "deleted"}`}},
+		})
+		client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+		kr := migrationRequest{client: k8sClient, esClient: client}
+
+		status, err := kr.runMigration(reindexMigration, false)
+		Expect(err).ToNot(BeNil())
+		Expect(status.Phase).To(Equal(api.MigrationFailed))
+
+		req, found := chatter.GetRequest(taskDocURI + "?refresh=true")
+		Expect(found).To(BeTrue())
+		Expect(req.Method).To(Equal(http.MethodDelete))
+	})
+})
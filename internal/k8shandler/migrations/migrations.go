@@ -1,16 +1,30 @@
 package migrations
 
 import (
+	"errors"
+	"time"
+
 	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// migrationsIndex holds a document per completed migration named by the ID of the migration
+const migrationsIndex = ".operator-migrations"
+
+// ErrMigrationInProgress is returned by migrations that continue in the background, e.g. while
+// a reindex task runs. The migration is run again to pick up where it left off.
+var ErrMigrationInProgress = kverrors.New("migration in progress")
+
 type MigrationRequest interface {
 	RunKibanaMigrations() error
-	RunElasticsearchMigrations() error
+	// RunElasticsearchMigrations runs the pending migrations of the cluster in order and
+	// returns their state, a dry run only checks which would run
+	RunElasticsearchMigrations(dryRun bool) ([]api.ElasticsearchMigrationStatus, error)
 }
 
 func NewMigrationRequest(client client.Client, esClient elasticsearch.Client) MigrationRequest {
@@ -26,42 +40,182 @@ type migrationRequest struct {
 }
 
 func (mr *migrationRequest) RunKibanaMigrations() error {
-	if index, _ := mr.esClient.GetIndex(kibanaIndex); index == nil {
-		return nil
+	_, err := mr.runMigrations(kibanaMigrations, false)
+	return err
+}
+
+func (mr *migrationRequest) RunElasticsearchMigrations(dryRun bool) ([]api.ElasticsearchMigrationStatus, error) {
+	return mr.runMigrations(elasticsearchMigrations, dryRun)
+}
+
+// runMigrations runs the migrations of the registry in order. A migration that is blocked or
+// fails holds back the ones after it, except during a dry run.
+func (mr *migrationRequest) runMigrations(registry []migration, dryRun bool) ([]api.ElasticsearchMigrationStatus, error) {
+	statuses := make([]api.ElasticsearchMigrationStatus, 0, len(registry))
+	for i, m := range registry {
+		status, err := mr.runMigration(m, dryRun)
+		statuses = append(statuses, status)
+		if err == nil || dryRun {
+			continue
+		}
+
+		for _, next := range registry[i+1:] {
+			statuses = append(statuses, api.ElasticsearchMigrationStatus{
+				ID:          next.id,
+				Description: next.description,
+				Phase:       api.MigrationPending,
+				Message:     "Waiting for migration " + m.id,
+			})
+		}
+		return statuses, err
+	}
+	return statuses, nil
+}
+
+func (mr *migrationRequest) runMigration(m migration, dryRun bool) (api.ElasticsearchMigrationStatus, error) {
+	status := api.ElasticsearchMigrationStatus{ID: m.id, Description: m.description}
+
+	if m.applies != nil {
+		applies, err := m.applies(mr)
+		if err != nil {
+			status.Phase = api.MigrationFailed
+			status.Message = err.Error()
+			return status, err
+		}
+		if !applies {
+			status.Phase = api.MigrationSkipped
+			status.Message = "Nothing to migrate"
+			return status, nil
+		}
 	}
 
-	indices, err := mr.esClient.GetAllIndices(kibanaIndex)
-	if err != nil {
-		return kverrors.Wrap(err, "failed to get indices before running migrations",
-			"alias", kibanaIndex,
-		)
+	if !m.rerun {
+		completed, err := mr.migrationRecorded(m.id)
+		if err != nil {
+			status.Phase = api.MigrationFailed
+			status.Message = err.Error()
+			return status, err
+		}
+		if completed {
+			status.Phase = api.MigrationCompleted
+			return status, nil
+		}
 	}
 
-	health, err := getIndexHealth(indices, kibanaIndex)
-	if err != nil {
-		return kverrors.Wrap(err, "failed to get index health before running migrations",
-			"index", kibanaIndex)
+	if err := mr.checkPreconditions(m); err != nil {
+		status.Phase = api.MigrationBlocked
+		status.Message = err.Error()
+		return status, err
 	}
 
-	if health != "green" && health != "yellow" {
-		return kverrors.New("waiting for index recovery before running migrations",
-			"current_status", health,
-			"desired_status", "green/yellow",
-			"index", kibanaIndex)
+	if dryRun {
+		status.Phase = api.MigrationPending
+		status.Message = "Runs once the dry run is turned off"
+		return status, nil
 	}
 
-	if err := mr.reIndexKibana5to6(); err != nil {
-		return kverrors.Wrap(err, "failed to reindex",
-			"from", kibanaIndex,
-			"to", kibana6Index)
+	log.Info("Running migration", "migration", m.id)
+	if err := m.run(mr); err != nil {
+		if errors.Is(err, ErrMigrationInProgress) {
+			status.Phase = api.MigrationPending
+			status.Message = kverrors.Message(err)
+			return status, err
+		}
+		err = kverrors.Wrap(err, "failed to run migration", "migration", m.id)
+		status.Phase = api.MigrationFailed
+		status.Message = err.Error()
+		return status, err
 	}
-	return nil
+
+	if m.rerun {
+		status.Phase = api.MigrationCompleted
+		return status, nil
+	}
+	if err := mr.recordMigration(m); err != nil {
+		status.Phase = api.MigrationFailed
+		status.Message = err.Error()
+		return status, err
+	}
+	status.Phase = api.MigrationCompleted
+	return status, nil
 }
 
-func (mr *migrationRequest) RunElasticsearchMigrations() error {
+// checkPreconditions returns why the migration can't run yet
+func (mr *migrationRequest) checkPreconditions(m migration) error {
+	for _, name := range m.indices {
+		indices, err := mr.esClient.GetAllIndices(name)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to get indices before running migrations",
+				"alias", name,
+			)
+		}
+
+		health, err := getIndexHealth(indices, name)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to get index health before running migrations",
+				"index", name)
+		}
+
+		if health != "green" && health != "yellow" {
+			return kverrors.New("waiting for index recovery before running migrations",
+				"current_status", health,
+				"desired_status", "green/yellow",
+				"index", name)
+		}
+	}
+
+	if m.majorVersion != "" {
+		ok, err := mr.matchRequiredMajorVersion(m.majorVersion)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return kverrors.New("waiting for all nodes to match the required version before running migrations",
+				"required_version", m.majorVersion)
+		}
+	}
 	return nil
 }
 
+// migrationRecorded tells whether the completion of the migration was recorded in the cluster
+func (mr *migrationRequest) migrationRecorded(id string) (bool, error) {
+	doc, err := mr.esClient.GetDocument(migrationsIndex, id)
+	if err != nil {
+		return false, kverrors.Wrap(err, "failed to get migration marker",
+			"migration", id)
+	}
+	return doc != nil, nil
+}
+
+// recordMigration records the completion of the migration in the cluster, so it doesn't run
+// again on the same data even if the custom resource is recreated
+func (mr *migrationRequest) recordMigration(m migration) error {
+	if err := mr.ensureMigrationsIndex(); err != nil {
+		return err
+	}
+
+	marker := map[string]string{
+		"description":  m.description,
+		"completed_at": time.Now().UTC().Format(time.RFC3339),
+	}
+	return mr.esClient.IndexDocument(migrationsIndex, m.id, marker)
+}
+
+// ensureMigrationsIndex creates the index holding the documents of the migrations
+func (mr *migrationRequest) ensureMigrationsIndex() error {
+	index, err := mr.esClient.GetIndex(migrationsIndex)
+	if err != nil {
+		return err
+	}
+	if index != nil {
+		return nil
+	}
+
+	index = estypes.NewIndex(migrationsIndex, 1, 0)
+	index.Settings.Index = &estypes.IndexingSettings{AutoExpandReplicas: "0-1"}
+	return mr.esClient.CreateIndex(migrationsIndex, index)
+}
+
 func (mr *migrationRequest) matchRequiredMajorVersion(version string) (bool, error) {
 	versions, err := mr.esClient.GetClusterNodeVersions()
 	if err != nil {
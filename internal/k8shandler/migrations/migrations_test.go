@@ -1,9 +1,12 @@
 package migrations
 
 import (
+	"net/http"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
 	"github.com/openshift/elasticsearch-operator/test/helpers"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -32,6 +35,12 @@ var _ = Describe("Running", func() {
 						Body:       `{}`,
 					},
 				},
+				".operator-migrations/_doc/kibana-0001-reindex-5-to-6": {
+					{
+						StatusCode: 404,
+						Body:       `{"found": false}`,
+					},
+				},
 				"_cat/indices/.kibana?format=json": {
 					{
 						StatusCode: 200,
@@ -76,6 +85,12 @@ var _ = Describe("Running", func() {
 						Body:       `{}`,
 					},
 				},
+				".operator-migrations/_doc/kibana-0001-reindex-5-to-6": {
+					{
+						StatusCode: 404,
+						Body:       `{"found": false}`,
+					},
+				},
 				"_cat/indices/.kibana?format=json": {
 					{
 						StatusCode: 404,
@@ -93,4 +108,109 @@ var _ = Describe("Running", func() {
 			Expect(kr.RunKibanaMigrations()).ShouldNot(Succeed())
 		})
 	})
+
+	Describe("Elasticsearch migrations", func() {
+		const (
+			markerURI   = ".operator-migrations/_doc/elasticsearch-0001-alias-old-indices"
+			aliasURI    = "project.*,.operations.*/_alias"
+			statsURI    = "_cluster/stats/nodes/_all"
+			versionsURI = "*/_settings/index.version.created?flat_settings=true"
+		)
+
+		It("should only report pending migrations during a dry run", func() {
+			chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				markerURI:   {{StatusCode: http.StatusNotFound, Body: `{"found": false}`}},
+				statsURI:    {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["6.8.1"]}}`}},
+				versionsURI: {{StatusCode: http.StatusOK, Body: `{}`}},
+			})
+			client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+			kr := migrationRequest{client: k8sClient, esClient: client}
+
+			statuses, err := kr.RunElasticsearchMigrations(true)
+			Expect(err).To(BeNil())
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Phase).To(Equal(api.MigrationPending))
+			Expect(statuses[1].Phase).To(Equal(api.MigrationSkipped))
+
+			_, found := chatter.GetRequest(aliasURI)
+			Expect(found).To(BeFalse())
+		})
+
+		It("should run and record pending migrations", func() {
+			chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				markerURI:   {{StatusCode: http.StatusNotFound, Body: `{"found": false}`}},
+				aliasURI:    {{StatusCode: http.StatusOK, Body: `{}`}},
+				statsURI:    {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["6.8.1"]}}`}},
+				versionsURI: {{StatusCode: http.StatusOK, Body: `{}`}},
+				".operator-migrations": {
+					{StatusCode: http.StatusNotFound, Body: `{}`},
+					{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+				},
+				markerURI + "?refresh=true": {{StatusCode: http.StatusCreated, Body: `{"result": "created"}`}},
+			})
+			client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+			kr := migrationRequest{client: k8sClient, esClient: client}
+
+			statuses, err := kr.RunElasticsearchMigrations(false)
+			Expect(err).To(BeNil())
+			Expect(statuses[0].Phase).To(Equal(api.MigrationCompleted))
+
+			_, found := chatter.GetRequest(aliasURI)
+			Expect(found).To(BeTrue())
+
+			_, _ = chatter.GetRequest(".operator-migrations")
+			req, found := chatter.GetRequest(".operator-migrations")
+			Expect(found).To(BeTrue())
+			Expect(req.Method).To(Equal(http.MethodPut))
+			Expect(req.Body).To(MatchJSON(`{"settings": {"number_of_shards": 1, "index": {"auto_expand_replicas": "0-1"}}}`))
+
+			req, found = chatter.GetRequest(markerURI + "?refresh=true")
+			Expect(found).To(BeTrue())
+			Expect(req.Body).To(ContainSubstring(`"description":"Add the app and infra aliases`))
+		})
+
+		It("should skip recorded migrations", func() {
+			chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				markerURI:   {{StatusCode: http.StatusOK, Body: `{"found": true, "_source": {"completed_at": "2021-01-01T00:00:00Z"}}`}},
+				statsURI:    {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["6.8.1"]}}`}},
+				versionsURI: {{StatusCode: http.StatusOK, Body: `{}`}},
+			})
+			client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+			kr := migrationRequest{client: k8sClient, esClient: client}
+
+			statuses, err := kr.RunElasticsearchMigrations(false)
+			Expect(err).To(BeNil())
+			Expect(statuses[0].Phase).To(Equal(api.MigrationCompleted))
+
+			_, found := chatter.GetRequest(aliasURI)
+			Expect(found).To(BeFalse())
+		})
+
+		It("should hold back later migrations while one is blocked", func() {
+			chatter = helpers.NewFakeElasticsearchChatter(map[string]helpers.FakeElasticsearchResponses{
+				".operator-migrations/_doc/first": {{StatusCode: http.StatusNotFound, Body: `{"found": false}`}},
+				"_cluster/stats":                  {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["5.6.16", "6.8.1"]}}`}},
+			})
+			client = helpers.NewFakeElasticsearchClient(esCluster, esNamespace, k8sClient, chatter)
+
+			kr := migrationRequest{client: k8sClient, esClient: client}
+
+			ran := false
+			registry := []migration{
+				{id: "first", majorVersion: "6", run: func(*migrationRequest) error { ran = true; return nil }},
+				{id: "second", run: func(*migrationRequest) error { ran = true; return nil }},
+			}
+
+			statuses, err := kr.runMigrations(registry, false)
+			Expect(err).ToNot(BeNil())
+			Expect(ran).To(BeFalse())
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Phase).To(Equal(api.MigrationBlocked))
+			Expect(statuses[1].Phase).To(Equal(api.MigrationPending))
+			Expect(statuses[1].Message).To(Equal("Waiting for migration first"))
+		})
+	})
 })
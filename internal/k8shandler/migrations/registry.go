@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"github.com/ViaQ/logerr/kverrors"
+)
+
+// migration changes the data or settings of a cluster once. Migrations run in the order of
+// their registry and must be idempotent, a migration runs again when the operator stops
+// before recording its completion.
+type migration struct {
+	// id names the document recording the completion of the migration, it must never change
+	id          string
+	description string
+
+	// majorVersion every node must run before the migration runs, any if empty
+	majorVersion string
+	// indices that must be green or yellow before the migration runs
+	indices []string
+	// rerun migrations aren't recorded, they run again whenever they apply, e.g. to migrate
+	// data written since their last run
+	rerun bool
+
+	// applies tells whether there is anything to migrate, the migration always applies if nil
+	applies func(mr *migrationRequest) (bool, error)
+	run     func(mr *migrationRequest) error
+}
+
+// kibanaMigrations run before the Kibana deployment is updated. Append new migrations to the
+// end of the registries and never reorder or rename them.
+var kibanaMigrations = []migration{
+	{
+		id:           "kibana-0001-reindex-5-to-6",
+		description:  "Reindex the .kibana index of Kibana 5 into .kibana-6 and alias it",
+		majorVersion: kibana5to6EsVersion,
+		indices:      []string{kibanaIndex},
+		applies:      (*migrationRequest).kibanaIndexExists,
+		run:          (*migrationRequest).reIndexKibana5to6,
+	},
+}
+
+// elasticsearchMigrations run once the cluster is ready, e.g. after an upgrade
+var elasticsearchMigrations = []migration{
+	{
+		id:          "elasticsearch-0001-alias-old-indices",
+		description: "Add the app and infra aliases to the project.* and .operations.* indices of releases before 4.5",
+		run:         (*migrationRequest).aliasOldIndices,
+	},
+	{
+		id:          "elasticsearch-0002-reindex-incompatible-indices",
+		description: "Reindex the indices the packaged version of Elasticsearch can't read and alias them by their old name",
+		rerun:       true,
+		applies:     (*migrationRequest).hasIncompatibleIndices,
+		run:         (*migrationRequest).reindexIncompatibleIndices,
+	},
+}
+
+func (mr *migrationRequest) kibanaIndexExists() (bool, error) {
+	index, _ := mr.esClient.GetIndex(kibanaIndex)
+	return index != nil, nil
+}
+
+func (mr *migrationRequest) aliasOldIndices() error {
+	if !mr.esClient.AddAliasForOldIndices() {
+		return kverrors.New("failed to add aliases to the indices of older releases")
+	}
+	return nil
+}
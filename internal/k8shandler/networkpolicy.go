@@ -0,0 +1,319 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/ViaQ/logerr/kverrors"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+const (
+	restAPIPort   = 9200
+	transportPort = 9300
+	proxyPort     = 60000
+	metricsPort   = 60001
+)
+
+// indexManagementPodSelector matches the pods of the index management cronjobs, they reach the
+// cluster through its service which forwards to the proxy
+var indexManagementPodSelector = map[string]string{
+	"component":     "indexManagement",
+	"logging-infra": "indexManagement",
+}
+
+// monitoringNamespaceSelector matches the namespaces of the cluster monitoring stack
+var monitoringNamespaceSelector = map[string]string{
+	"network.openshift.io/policy-group": "monitoring",
+}
+
+func networkPolicyName(clusterName string) string {
+	return fmt.Sprintf("%s-network-policy", clusterName)
+}
+
+// CreateOrUpdateNetworkPolicy ensures the network policy of the cluster matches the spec
+// and removes it once it was disabled
+func (er *ElasticsearchRequest) CreateOrUpdateNetworkPolicy() error {
+	name := networkPolicyName(er.cluster.Name)
+	namespace := er.cluster.Namespace
+
+	spec := er.cluster.Spec.NetworkPolicy
+	if spec == nil || !spec.Enabled {
+		return RelaxNetworkPolicy(name, namespace, er.cluster, er.client)
+	}
+
+	policy := newNetworkPolicy(er.cluster.Name, namespace, spec.Peers, remoteClusterPeers(er.cluster))
+	er.cluster.AddOwnerRefTo(&policy)
+
+	return EnforceNetworkPolicy(&policy, er.client)
+}
+
+// newNetworkPolicy admits the operator, the nodes of the cluster, the index management jobs, the
+// monitoring stack, the peers and the nodes of the remote clusters:
+//
+//	kind: NetworkPolicy
+//	apiVersion: networking.k8s.io/v1
+//	metadata:
+//	  name: elasticsearch-network-policy
+//	spec:
+//	  podSelector:
+//	    matchLabels:
+//	      cluster-name: elasticsearch
+//	      component: elasticsearch
+//	  ingress:
+//	  - from:
+//	    - podSelector:
+//	        matchLabels:
+//	          name: elasticsearch-operator
+//	      namespaceSelector: {}
+//	    ports:
+//	    - protocol: TCP
+//	      port: 9200
+//	    - protocol: TCP
+//	      port: 60000
+//	    - protocol: TCP
+//	      port: 60001
+//	  - from:
+//	    - podSelector:
+//	        matchLabels:
+//	          cluster-name: elasticsearch
+//	          component: elasticsearch
+//	    ports:
+//	    - protocol: TCP
+//	      port: 9200
+//	    - protocol: TCP
+//	      port: 9300
+//	    - protocol: TCP
+//	      port: 60000
+//	  - from:
+//	    - podSelector:
+//	        matchLabels:
+//	          component: indexManagement
+//	          logging-infra: indexManagement
+//	    ports:
+//	    - protocol: TCP
+//	      port: 60000
+//	  - from:
+//	    - namespaceSelector:
+//	        matchLabels:
+//	          network.openshift.io/policy-group: monitoring
+//	    ports:
+//	    - protocol: TCP
+//	      port: 60001
+//	  - from: <peers>
+//	    ports:
+//	    - protocol: TCP
+//	      port: 60000
+//	    - protocol: TCP
+//	      port: 60001
+//	  - from: <remote cluster peers>
+//	    ports:
+//	    - protocol: TCP
+//	      port: 9300
+func newNetworkPolicy(clusterName, namespace string, peers, remotePeers []networking.NetworkPolicyPeer) networking.NetworkPolicy {
+	clusterPods := map[string]string{
+		"cluster-name": clusterName,
+		"component":    "elasticsearch",
+	}
+
+	ingress := []networking.NetworkPolicyIngressRule{
+		{
+			From: []networking.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"name": "elasticsearch-operator",
+						},
+					},
+					// This needs to be present but empty so it will select all namespaces
+					// since we do not have a label for our operator namespace
+					NamespaceSelector: &metav1.LabelSelector{},
+				},
+			},
+			Ports: networkPolicyPorts(restAPIPort, proxyPort, metricsPort),
+		},
+		{
+			From: []networking.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: clusterPods,
+					},
+				},
+			},
+			Ports: networkPolicyPorts(restAPIPort, transportPort, proxyPort),
+		},
+		{
+			From: []networking.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: indexManagementPodSelector,
+					},
+				},
+			},
+			Ports: networkPolicyPorts(proxyPort),
+		},
+		{
+			From: []networking.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: monitoringNamespaceSelector,
+					},
+				},
+			},
+			Ports: networkPolicyPorts(metricsPort),
+		},
+	}
+
+	if len(peers) > 0 {
+		ingress = append(ingress, networking.NetworkPolicyIngressRule{
+			From:  peers,
+			Ports: networkPolicyPorts(proxyPort, metricsPort),
+		})
+	}
+
+	if len(remotePeers) > 0 {
+		ingress = append(ingress, networking.NetworkPolicyIngressRule{
+			From:  remotePeers,
+			Ports: networkPolicyPorts(transportPort),
+		})
+	}
+
+	return networking.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName(clusterName),
+			Namespace: namespace,
+			Labels:    appendDefaultLabel(clusterName, map[string]string{}),
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: clusterPods,
+			},
+			Ingress:     ingress,
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress},
+		},
+	}
+}
+
+// remoteClusterPeers returns the nodes of the remote clusters managed by the operator, they
+// connect to the transport port when the clusters search across each other
+func remoteClusterPeers(cluster *api.Elasticsearch) []networking.NetworkPolicyPeer {
+	peers := []networking.NetworkPolicyPeer{}
+	for _, remote := range cluster.Spec.RemoteClusters {
+		ref := remote.ElasticsearchRef
+		if ref == nil {
+			continue
+		}
+
+		peer := networking.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"cluster-name": ref.Name,
+					"component":    "elasticsearch",
+				},
+			},
+		}
+		if ref.Namespace != "" && ref.Namespace != cluster.Namespace {
+			// This needs to be present but empty so it will select all namespaces
+			// since there is no label for the namespace of the remote cluster
+			peer.NamespaceSelector = &metav1.LabelSelector{}
+		}
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+func networkPolicyPorts(ports ...int) []networking.NetworkPolicyPort {
+	protocol := v1.ProtocolTCP
+
+	policyPorts := make([]networking.NetworkPolicyPort, 0, len(ports))
+	for _, port := range ports {
+		port := intstr.FromInt(port)
+		policyPorts = append(policyPorts, networking.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &port,
+		})
+	}
+	return policyPorts
+}
+
+// EnforceNetworkPolicy creates the network policy or reverts changes made to it
+func EnforceNetworkPolicy(policy *networking.NetworkPolicy, client client.Client) error {
+	err := client.Create(context.TODO(), policy)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create network policy",
+			"network_policy", policy.Name)
+	}
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &networking.NetworkPolicy{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: policy.Name, Namespace: policy.Namespace}, current); err != nil {
+			return err
+		}
+
+		if reflect.DeepEqual(current.Spec, policy.Spec) {
+			return nil
+		}
+
+		current.Spec = policy.Spec
+		return client.Update(context.TODO(), current)
+	})
+	if retryErr != nil {
+		return kverrors.Wrap(retryErr, "failed to update network policy",
+			"network_policy", policy.Name)
+	}
+
+	return nil
+}
+
+// RelaxNetworkPolicy removes the network policy if it exists and is owned by the cluster,
+// a policy of the same name created by others is left alone
+func RelaxNetworkPolicy(name, namespace string, owner *api.Elasticsearch, client client.Client) error {
+	policy := &networking.NetworkPolicy{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, policy); err != nil {
+		if apierrors.IsNotFound(kverrors.Root(err)) {
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to get network policy",
+			"network_policy", name)
+	}
+
+	if !hasOwnerRef(policy, owner) {
+		return nil
+	}
+
+	if err := client.Delete(context.TODO(), policy); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to delete network policy",
+				"network_policy", name)
+		}
+	}
+
+	return nil
+}
+
+// hasOwnerRef returns true if the object is owned by the cluster
+func hasOwnerRef(obj metav1.Object, cluster *api.Elasticsearch) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == cluster.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package k8shandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func getNetworkPolicy(er *ElasticsearchRequest) (*networking.NetworkPolicy, error) {
+	policy := &networking.NetworkPolicy{}
+	key := types.NamespacedName{Name: "elasticsearch-network-policy", Namespace: "openshift-logging"}
+	err := er.client.Get(context.TODO(), key, policy)
+	return policy, err
+}
+
+func containsIngressRule(rules []networking.NetworkPolicyIngressRule, rule networking.NetworkPolicyIngressRule) bool {
+	for _, r := range rules {
+		if reflect.DeepEqual(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCreateOrUpdateNetworkPolicy(t *testing.T) {
+	kibana := networking.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"component": "kibana"},
+		},
+	}
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{
+		NetworkPolicy: &loggingv1.ElasticsearchNetworkPolicySpec{
+			Enabled: true,
+			Peers:   []networking.NetworkPolicyPeer{kibana},
+		},
+	}), nil)
+
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	policy, err := getNetworkPolicy(er)
+	if err != nil {
+		t.Fatalf("expected network policy: %s", err)
+	}
+	if len(policy.OwnerReferences) != 1 {
+		t.Errorf("expected network policy to be owned by the cluster, got %v", policy.OwnerReferences)
+	}
+
+	peers := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+	if !reflect.DeepEqual(peers.From, []networking.NetworkPolicyPeer{kibana}) {
+		t.Errorf("expected peers to be admitted, got %v", peers.From)
+	}
+	if !reflect.DeepEqual(peers.Ports, networkPolicyPorts(proxyPort, metricsPort)) {
+		t.Errorf("expected peers to be limited to the proxy ports, got %v", peers.Ports)
+	}
+
+	indexManagement := networking.NetworkPolicyIngressRule{
+		From: []networking.NetworkPolicyPeer{
+			{
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"component": "indexManagement", "logging-infra": "indexManagement"},
+				},
+			},
+		},
+		Ports: networkPolicyPorts(proxyPort),
+	}
+	if !containsIngressRule(policy.Spec.Ingress, indexManagement) {
+		t.Errorf("expected the index management jobs to be admitted to the proxy, got %v", policy.Spec.Ingress)
+	}
+
+	// revert changes made to the policy
+	policy.Spec.Ingress = nil
+	if err := er.client.Update(context.TODO(), policy); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	policy, _ = getNetworkPolicy(er)
+	if len(policy.Spec.Ingress) != 5 {
+		t.Errorf("expected the ingress rules to be restored, got %v", policy.Spec.Ingress)
+	}
+
+	er.cluster.Spec.NetworkPolicy.Enabled = false
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := getNetworkPolicy(er); !apierrors.IsNotFound(err) {
+		t.Errorf("expected network policy to be removed, got %v", err)
+	}
+}
+
+func TestCreateOrUpdateNetworkPolicyRemoteClusters(t *testing.T) {
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{
+		NetworkPolicy: &loggingv1.ElasticsearchNetworkPolicySpec{Enabled: true},
+		RemoteClusters: []loggingv1.ElasticsearchRemoteCluster{
+			{Name: "east", ElasticsearchRef: &loggingv1.ElasticsearchRemoteClusterReference{Name: "elasticsearch", Namespace: "openshift-logging-east"}},
+			{Name: "local", ElasticsearchRef: &loggingv1.ElasticsearchRemoteClusterReference{Name: "elasticsearch-local"}},
+			{Name: "external", Seeds: []string{"es.example.com:9300"}},
+		},
+	}), nil)
+
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	policy, err := getNetworkPolicy(er)
+	if err != nil {
+		t.Fatalf("expected network policy: %s", err)
+	}
+
+	remotes := policy.Spec.Ingress[len(policy.Spec.Ingress)-1]
+	want := []networking.NetworkPolicyPeer{
+		{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"cluster-name": "elasticsearch", "component": "elasticsearch"}},
+			NamespaceSelector: &metav1.LabelSelector{},
+		},
+		{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"cluster-name": "elasticsearch-local", "component": "elasticsearch"}},
+		},
+	}
+	if !reflect.DeepEqual(remotes.From, want) {
+		t.Errorf("expected the nodes of the referenced clusters to be admitted, got %v", remotes.From)
+	}
+	if !reflect.DeepEqual(remotes.Ports, networkPolicyPorts(transportPort)) {
+		t.Errorf("expected the remote clusters to be limited to the transport port, got %v", remotes.Ports)
+	}
+}
+
+func TestCreateOrUpdateNetworkPolicyDisabled(t *testing.T) {
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), nil)
+
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := getNetworkPolicy(er); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no network policy, got %v", err)
+	}
+}
+
+func TestCreateOrUpdateNetworkPolicyDisabledKeepsForeignPolicy(t *testing.T) {
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), nil)
+	foreign := &networking.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-network-policy",
+			Namespace: "openshift-logging",
+		},
+	}
+	if err := er.client.Create(context.TODO(), foreign); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if err := er.CreateOrUpdateNetworkPolicy(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := getNetworkPolicy(er); err != nil {
+		t.Errorf("expected the network policy not owned by the cluster to be kept, got %v", err)
+	}
+}
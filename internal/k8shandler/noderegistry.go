@@ -0,0 +1,160 @@
+package k8shandler
+
+import (
+	"context"
+	"sync"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/metrics"
+)
+
+// clusterState is the in-memory state kept between reconciles of a single cluster.
+// Everything in here can be rebuilt from the API server, so losing it on operator
+// restart only costs a few extra requests. The embedded mutex is held for the whole
+// reconcile so that concurrent reconcilers never work on the same cluster at once.
+type clusterState struct {
+	sync.Mutex
+
+	// flushed is set once the state was dropped from the registry, a reconcile that
+	// waited for it must start over with the state registered in its place
+	flushed bool
+
+	nodes []NodeTypeInterface
+
+	// wrongConfig is set once an invalid configuration was reported so that
+	// we don't flood the operator log with the same error every reconcile
+	wrongConfig bool
+
+	// migrationsCompleted is set once every migration of the data and settings completed
+	migrationsCompleted bool
+
+	// certificateKeys are the certificates exported as metrics, their series are
+	// removed when the cluster is flushed
+	certificateKeys []string
+
+	// certificates are the certificates last parsed from the secret of the cluster, they
+	// are parsed again once the data of the secret no longer matches certificatesSum
+	certificates    []api.ElasticsearchCertificateStatus
+	certificatesSum [32]byte
+
+	// secretKeyHashes are the hashes of the secret keys every node was last started with,
+	// they tell which keys changed when the secret is updated
+	secretKeyHashes map[string][32]byte
+}
+
+// nodeRegistry keeps the clusterState of every cluster managed by the operator
+type nodeRegistry struct {
+	mu       sync.Mutex
+	clusters map[string]*clusterState
+}
+
+var registry = &nodeRegistry{
+	clusters: map[string]*clusterState{},
+}
+
+// get returns the state of the cluster, creating an empty one if it is unknown
+func (r *nodeRegistry) get(clusterName, namespace string) *clusterState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := nodeMapKey(clusterName, namespace)
+	state, ok := r.clusters[key]
+	if !ok {
+		state = &clusterState{}
+		r.clusters[key] = state
+	}
+	return state
+}
+
+// lock returns the state of the cluster once no other reconcile holds it. States flushed
+// while waiting for them are skipped so that two reconciles never work on the same cluster
+// with different states.
+func (r *nodeRegistry) lock(clusterName, namespace string) *clusterState {
+	for {
+		state := r.get(clusterName, namespace)
+		state.Lock()
+		if !state.flushed {
+			return state
+		}
+		state.Unlock()
+	}
+}
+
+// remove forgets the state of the cluster
+func (r *nodeRegistry) remove(clusterName, namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.clusters, nodeMapKey(clusterName, namespace))
+}
+
+// FlushNodes drops the state kept for a cluster, e.g. after it was deleted
+func FlushNodes(clusterName, namespace string) {
+	state := registry.lock(clusterName, namespace)
+	defer state.Unlock()
+
+	for _, key := range state.certificateKeys {
+		metrics.DeleteCertificateExpiry(namespace, clusterName, key)
+	}
+
+	state.flushed = true
+	registry.remove(clusterName, namespace)
+}
+
+// lockCluster blocks until no other reconcile works on the cluster and
+// returns the function to release it again
+func (er *ElasticsearchRequest) lockCluster() func() {
+	state := registry.lock(er.cluster.Name, er.cluster.Namespace)
+	return state.Unlock
+}
+
+func (er *ElasticsearchRequest) state() *clusterState {
+	return registry.get(er.cluster.Name, er.cluster.Namespace)
+}
+
+func (er *ElasticsearchRequest) getNodes() []NodeTypeInterface {
+	return er.state().nodes
+}
+
+// getOwnedNodes returns the deployments and statefulsets on the API server that belong
+// to the cluster keyed by their name. It is used instead of the in-memory node list when
+// looking for nodes to remove so that nodes dropped from the spec while the operator was
+// not running are cleaned up as well.
+func (er *ElasticsearchRequest) getOwnedNodes() (map[string]runtime.Object, error) {
+	opts := []client.ListOption{
+		client.InNamespace(er.cluster.Namespace),
+		client.MatchingLabels{
+			"cluster-name": er.cluster.Name,
+			"component":    "elasticsearch",
+		},
+	}
+
+	owned := map[string]runtime.Object{}
+
+	deployments := &apps.DeploymentList{}
+	if err := er.client.List(context.TODO(), deployments, opts...); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		if metav1.IsControlledBy(&deployments.Items[i], er.cluster) {
+			owned[deployments.Items[i].Name] = &deployments.Items[i]
+		}
+	}
+
+	statefulSets := &apps.StatefulSetList{}
+	if err := er.client.List(context.TODO(), statefulSets, opts...); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		if metav1.IsControlledBy(&statefulSets.Items[i], er.cluster) {
+			owned[statefulSets.Items[i].Name] = &statefulSets.Items[i]
+		}
+	}
+
+	return owned, nil
+}
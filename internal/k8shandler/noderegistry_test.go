@@ -0,0 +1,114 @@
+package k8shandler
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestNodeRegistryKeepsStatePerCluster(t *testing.T) {
+	first := registry.get("registry-first", "openshift-logging")
+	first.wrongConfig = true
+
+	if got := registry.get("registry-first", "openshift-logging"); got != first {
+		t.Errorf("expected the same state for the same cluster")
+	}
+
+	if got := registry.get("registry-second", "openshift-logging"); got.wrongConfig {
+		t.Errorf("expected state of another cluster not to be shared")
+	}
+
+	FlushNodes("registry-first", "openshift-logging")
+
+	if got := registry.get("registry-first", "openshift-logging"); got == first || got.wrongConfig {
+		t.Errorf("expected state to be dropped after flushing nodes")
+	}
+}
+
+func TestNodeRegistryLockSkipsFlushedState(t *testing.T) {
+	flushed := registry.lock("registry-flushed", "openshift-logging")
+
+	locked := make(chan *clusterState)
+	go func() {
+		state := registry.lock("registry-flushed", "openshift-logging")
+		defer state.Unlock()
+		locked <- state
+	}()
+
+	// drop the state while the other reconcile waits for it like FlushNodes does
+	flushed.flushed = true
+	registry.remove("registry-flushed", "openshift-logging")
+	flushed.Unlock()
+
+	if got := <-locked; got == flushed || got.flushed {
+		t.Errorf("expected the state registered after flushing to be locked")
+	}
+}
+
+func TestGetOwnedNodes(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch",
+			Namespace: "openshift-logging",
+			UID:       "cluster-uid",
+		},
+	}
+
+	labels := map[string]string{
+		"cluster-name": "elasticsearch",
+		"component":    "elasticsearch",
+	}
+
+	owned := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1-deadbeef-1",
+			Namespace: "openshift-logging",
+			Labels:    labels,
+		},
+	}
+	cluster.AddOwnerRefTo(owned)
+
+	ownedSts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cm-deadbeef",
+			Namespace: "openshift-logging",
+			Labels:    labels,
+		},
+	}
+	cluster.AddOwnerRefTo(ownedSts)
+
+	notOwned := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-cdm-1-deadbeef-2",
+			Namespace: "openshift-logging",
+			Labels:    labels,
+		},
+	}
+
+	er := &ElasticsearchRequest{
+		client:  fake.NewFakeClient(owned, ownedSts, notOwned),
+		cluster: cluster,
+	}
+
+	nodes, err := er.getOwnedNodes()
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	got := []string{}
+	for name := range nodes {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+
+	want := []string{"elasticsearch-cdm-1-deadbeef-1", "elasticsearch-cm-deadbeef"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("diff: %s", diff)
+	}
+}
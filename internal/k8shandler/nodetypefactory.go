@@ -24,9 +24,24 @@ type NodeTypeInterface interface {
 	refreshHashes()
 	scaleDown() error
 	scaleUp() error
-	progressNodeChanges() error              // this function is used to tell the node to push out its changes
-	waitForNodeRejoinCluster() (bool, error) // this function is used to determine if a node has rejoined the cluster
-	waitForNodeLeaveCluster() (bool, error)  // this function is used to determine if a node has left the cluster
+	waitForNodeRejoinCluster() (bool, error) // this function is used to determine if a node has rejoined the cluster, it returns ErrWaitingForCluster otherwise
+	waitForNodeLeaveCluster() (bool, error)  // this function is used to determine if a node has left the cluster, it returns ErrWaitingForCluster otherwise
+
+	// rollout primitives used by progressNodeChanges so that every backend
+	// shares the same rollout semantics
+	isChanged() bool                               // the desired pod template differs from the one on the k8s resource
+	isProxyOnlyChange() bool                       // the desired pod template only differs in the proxy container
+	storageChange() (string, string)               // the kinds of storage the current and the desired pod template run on
+	imageChange() (string, string)                 // the images of Elasticsearch the current and the desired pod template run
+	isRolledOut() bool                             // all pods of the node run the current pod template
+	holdsData() bool                               // the node holds shards that can be relocated before it restarts
+	replicaCount() (int32, error)                  // the number of pods currently running for the node
+	executeUpdate() error                          // push the desired pod template to the k8s resource
+	holdRollout(replicas int32) error              // prevent pods from picking up a new pod template
+	releaseRollout(ordinal int32) error            // allow the pod with the given ordinal to pick up the new pod template
+	releasedOrdinal(replicas int32) (int32, error) // the lowest ordinal released so far, replicas if none was released
+	waitForPodRollout() error                      // returns ErrWaitingForCluster until the released pods picked up the new pod template
+	completeRollout() error                        // called once every pod of the node has been released
 }
 
 // NodeTypeFactory is a factory to construct either statefulset or deployment
@@ -38,24 +53,37 @@ func (er *ElasticsearchRequest) GetNodeTypeInterface(uuid string, node api.Elast
 
 	roleMap := getNodeRoleMap(node)
 
+	for _, nodeName := range er.nodeNames(uuid, node) {
+		if isDataNode(node) {
+			nodes = append(nodes, newDeploymentNode(nodeName, node, er.cluster, roleMap, er.client, er.esClient))
+		} else {
+			nodes = append(nodes, newStatefulSetNode(nodeName, node, er.cluster, roleMap, er.client, er.esClient))
+		}
+	}
+
+	return nodes
+}
+
+// nodeNames returns the names of the deployments or the statefulset backing a node of the spec
+func (er *ElasticsearchRequest) nodeNames(uuid string, node api.ElasticsearchNode) []string {
+	roleMap := getNodeRoleMap(node)
+
 	// common spec => cluster.Spec.Spec
 	nodeName := fmt.Sprintf("%s-%s", er.cluster.Name, getNodeSuffix(uuid, roleMap))
 
 	// if we have a data node then we need to create one deployment per replica
-	if isDataNode(node) {
-		// for loop from 1 to replica as replicaIndex
-		//   it is 1 instead of 0 because of legacy code
-		for replicaIndex := int32(1); replicaIndex <= node.NodeCount; replicaIndex++ {
-			dataNodeName := addDataNodeSuffix(nodeName, replicaIndex)
-			node := newDeploymentNode(dataNodeName, node, er.cluster, roleMap, er.client, er.esClient)
-			nodes = append(nodes, node)
-		}
-	} else {
-		node := newStatefulSetNode(nodeName, node, er.cluster, roleMap, er.client, er.esClient)
-		nodes = append(nodes, node)
+	if !isDataNode(node) {
+		return []string{nodeName}
 	}
 
-	return nodes
+	names := []string{}
+	// for loop from 1 to replica as replicaIndex
+	//   it is 1 instead of 0 because of legacy code
+	for replicaIndex := int32(1); replicaIndex <= node.NodeCount; replicaIndex++ {
+		names = append(names, addDataNodeSuffix(nodeName, replicaIndex))
+	}
+
+	return names
 }
 
 func getNodeSuffix(uuid string, roleMap map[api.ElasticsearchNodeRole]bool) string {
@@ -107,6 +135,16 @@ func containsNodeTypeInterface(node NodeTypeInterface, list []NodeTypeInterface)
 	return -1, false
 }
 
+func containsNodeName(nodeName string, list []NodeTypeInterface) bool {
+	for _, nodeTypeInterface := range list {
+		if nodeTypeInterface.name() == nodeName {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (er *ElasticsearchRequest) getNodeState(node NodeTypeInterface) *api.ElasticsearchNodeStatus {
 	index, status := getNodeStatus(node.name(), &er.cluster.Status)
 
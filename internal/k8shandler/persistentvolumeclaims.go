@@ -2,11 +2,14 @@ package k8shandler
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -76,6 +79,117 @@ func updatePersistentVolumeClaim(claim *v1.PersistentVolumeClaim, client client.
 	return nil
 }
 
+// fileSystemResizeGracePeriod is how long the kubelet gets to grow the filesystem of a
+// mounted volume before the node is restarted to grow it while the volume is mounted again
+const fileSystemResizeGracePeriod = 5 * time.Minute
+
+// claimResize is the outcome of reconciling the size of an existing claim
+type claimResize struct {
+	// rejected explains why the claim can't be changed as requested
+	rejected string
+	// remount is true when the filesystem resize waits for the volume to be mounted again
+	remount bool
+}
+
+// resizePersistentVolumeClaim raises the storage request of an existing claim when the
+// desired size grew and its storage class allows volume expansion. Shrinking a claim or
+// changing its storage class is rejected since Kubernetes doesn't support either.
+func resizePersistentVolumeClaim(claimName, namespace string, desired v1.PersistentVolumeClaimSpec, client client.Client) (claimResize, error) {
+	result := claimResize{}
+	current := &v1.PersistentVolumeClaim{}
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		result = claimResize{}
+
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: claimName, Namespace: namespace}, current); err != nil {
+			if apierrors.IsNotFound(err) {
+				// the claim is created along with the node
+				return nil
+			}
+			return kverrors.Wrap(err, "failed to get PVC",
+				"claim", claimName,
+			)
+		}
+
+		currentClass := storageClassName(current.Spec.StorageClassName)
+		if desired.StorageClassName != nil && *desired.StorageClassName != currentClass {
+			result.rejected = fmt.Sprintf("claim %s can't change its storage class from %q to %q",
+				claimName, currentClass, *desired.StorageClassName)
+			return nil
+		}
+
+		desiredSize := desired.Resources.Requests[v1.ResourceStorage]
+		currentSize := current.Spec.Resources.Requests[v1.ResourceStorage]
+
+		switch desiredSize.Cmp(currentSize) {
+		case -1:
+			result.rejected = fmt.Sprintf("claim %s can't shrink from %s to %s",
+				claimName, currentSize.String(), desiredSize.String())
+			return nil
+		case 0:
+			result.remount = isFileSystemResizeOverdue(current)
+			return nil
+		}
+
+		allowed, err := allowsVolumeExpansion(currentClass, client)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			result.rejected = fmt.Sprintf("claim %s can't grow from %s to %s as storage class %q doesn't allow volume expansion",
+				claimName, currentSize.String(), desiredSize.String(), currentClass)
+			return nil
+		}
+
+		log.Info("Expanding PVC", "claim", claimName, "from", currentSize.String(), "to", desiredSize.String())
+		current.Spec.Resources.Requests[v1.ResourceStorage] = desiredSize
+		return client.Update(context.TODO(), current)
+	})
+	if retryErr != nil {
+		return result, kverrors.Wrap(retryErr, "failed to resize PVC",
+			"claim", claimName,
+		)
+	}
+
+	return result, nil
+}
+
+func storageClassName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+func allowsVolumeExpansion(className string, c client.Client) (bool, error) {
+	if className == "" {
+		return false, nil
+	}
+
+	class := &storagev1.StorageClass{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: className}, class); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, kverrors.Wrap(err, "failed to get storage class",
+			"storageClass", className,
+		)
+	}
+
+	return class.AllowVolumeExpansion != nil && *class.AllowVolumeExpansion, nil
+}
+
+// isFileSystemResizeOverdue returns true if the volume of the claim was expanded but the
+// kubelet didn't grow its filesystem while it is mounted within the grace period
+func isFileSystemResizeOverdue(claim *v1.PersistentVolumeClaim) bool {
+	for _, condition := range claim.Status.Conditions {
+		if condition.Type == v1.PersistentVolumeClaimFileSystemResizePending && condition.Status == v1.ConditionTrue {
+			return time.Since(condition.LastTransitionTime.Time) > fileSystemResizeGracePeriod
+		}
+	}
+	return false
+}
+
 func createPersistentVolumeClaim(pvcName, namespace, clusterName string, volSpec v1.PersistentVolumeClaimSpec) *v1.PersistentVolumeClaim {
 	pvc := persistentVolumeClaim(pvcName, namespace, clusterName)
 	pvc.Spec = volSpec
@@ -99,3 +213,56 @@ func persistentVolumeClaim(pvcName, namespace, clusterName string) *v1.Persisten
 		},
 	}
 }
+
+// reconcileVolumeExpansion resizes the claims of the nodes to the storage size of the spec
+// and schedules a restart of the nodes whose filesystem only grows once the volume is
+// mounted again
+func (er *ElasticsearchRequest) reconcileVolumeExpansion() error {
+	cluster := er.cluster
+	rejected := []string{}
+
+	for _, node := range cluster.Spec.Nodes {
+		// nodes without a size run on ephemeral storage, the replacement nodes of a storage
+		// migration are provisioned with the size of the spec
+		if node.GenUUID == nil || node.Storage.Size == nil || er.isMigratingStorage(node) {
+			continue
+		}
+		desired := newPersistentVolumeClaimSpec(node.Storage)
+
+		for _, nodeName := range er.nodeNames(*node.GenUUID, node) {
+			claimName := fmt.Sprintf("%s-%s", cluster.Name, nodeName)
+
+			result, err := resizePersistentVolumeClaim(claimName, cluster.Namespace, desired, er.client)
+			if err != nil {
+				return err
+			}
+
+			if result.rejected != "" {
+				rejected = append(rejected, result.rejected)
+			}
+
+			if result.remount {
+				if err := er.scheduleNodeRedeploy(nodeName); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return updateStorageResizeRejectedCondition(cluster, rejected, er.client)
+}
+
+func (er *ElasticsearchRequest) scheduleNodeRedeploy(nodeName string) error {
+	clusterStatus := er.cluster.Status.DeepCopy()
+
+	index, nodeStatus := getNodeStatus(nodeName, clusterStatus)
+	if index == NotFoundIndex || nodeStatus.UpgradeStatus.ScheduledForRedeploy == v1.ConditionTrue {
+		return nil
+	}
+
+	log.Info("Scheduling node restart to finish resizing its filesystem", "node", nodeName)
+	nodeStatus.UpgradeStatus.ScheduledForRedeploy = v1.ConditionTrue
+	clusterStatus.Nodes[index] = *nodeStatus
+
+	return er.updateNodeStatus(*clusterStatus)
+}
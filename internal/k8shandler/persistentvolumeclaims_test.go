@@ -0,0 +1,193 @@
+package k8shandler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func newStorageClass(name string, allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func newClaim(name, size, class string, conditions ...v1.PersistentVolumeClaimCondition) *v1.PersistentVolumeClaim {
+	claim := createPersistentVolumeClaim(name, "openshift-logging", "elasticsearch", newPersistentVolumeClaimSpec(
+		loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity(size), StorageClassName: &class},
+	))
+	claim.Status.Conditions = conditions
+	return claim
+}
+
+func resourceQuantity(size string) *resource.Quantity {
+	q := resource.MustParse(size)
+	return &q
+}
+
+func TestResizePersistentVolumeClaim(t *testing.T) {
+	pending := v1.PersistentVolumeClaimCondition{
+		Type:               v1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * fileSystemResizeGracePeriod)),
+	}
+	justPending := pending
+	justPending.LastTransitionTime = metav1.Now()
+
+	tests := []struct {
+		desc     string
+		objs     []runtime.Object
+		size     string
+		class    string
+		wantSize string
+		rejected string
+		remount  bool
+	}{
+		{
+			desc:     "expansion allowed",
+			objs:     []runtime.Object{newClaim("claim", "10Gi", "gp2", pending), newStorageClass("gp2", true)},
+			size:     "20Gi",
+			class:    "gp2",
+			wantSize: "20Gi",
+		},
+		{
+			desc:     "expansion not allowed",
+			objs:     []runtime.Object{newClaim("claim", "10Gi", "gp2"), newStorageClass("gp2", false)},
+			size:     "20Gi",
+			class:    "gp2",
+			wantSize: "10Gi",
+			rejected: "doesn't allow volume expansion",
+		},
+		{
+			desc:     "shrink",
+			objs:     []runtime.Object{newClaim("claim", "10Gi", "gp2"), newStorageClass("gp2", true)},
+			size:     "5Gi",
+			class:    "gp2",
+			wantSize: "10Gi",
+			rejected: "can't shrink from 10Gi to 5Gi",
+		},
+		{
+			desc:     "storage class change",
+			objs:     []runtime.Object{newClaim("claim", "10Gi", "gp2"), newStorageClass("gp3", true)},
+			size:     "20Gi",
+			class:    "gp3",
+			wantSize: "10Gi",
+			rejected: `can't change its storage class from "gp2" to "gp3"`,
+		},
+		{
+			desc:     "filesystem resize overdue",
+			objs:     []runtime.Object{newClaim("claim", "20Gi", "gp2", pending)},
+			size:     "20Gi",
+			class:    "gp2",
+			wantSize: "20Gi",
+			remount:  true,
+		},
+		{
+			desc:     "filesystem resize pending",
+			objs:     []runtime.Object{newClaim("claim", "20Gi", "gp2", justPending)},
+			size:     "20Gi",
+			class:    "gp2",
+			wantSize: "20Gi",
+		},
+	}
+
+	for _, test := range tests {
+		client := fake.NewFakeClient(test.objs...)
+		desired := newPersistentVolumeClaimSpec(loggingv1.ElasticsearchStorageSpec{
+			Size:             resourceQuantity(test.size),
+			StorageClassName: &test.class,
+		})
+
+		result, err := resizePersistentVolumeClaim("claim", "openshift-logging", desired, client)
+		if err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+		if !strings.Contains(result.rejected, test.rejected) || (test.rejected == "") != (result.rejected == "") {
+			t.Errorf("%s: got rejected %q, want %q", test.desc, result.rejected, test.rejected)
+		}
+		if result.remount != test.remount {
+			t.Errorf("%s: got remount %t, want %t", test.desc, result.remount, test.remount)
+		}
+
+		claim := &v1.PersistentVolumeClaim{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: "claim", Namespace: "openshift-logging"}, claim); err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+		size := claim.Spec.Resources.Requests[v1.ResourceStorage]
+		if size.Cmp(resource.MustParse(test.wantSize)) != 0 {
+			t.Errorf("%s: got size %s, want %s", test.desc, size.String(), test.wantSize)
+		}
+	}
+}
+
+func TestReconcileVolumeExpansion(t *testing.T) {
+	_ = loggingv1.SchemeBuilder.AddToScheme(scheme.Scheme)
+
+	uuid := "abcd1234"
+	node := loggingv1.ElasticsearchNode{
+		Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+		NodeCount: 2,
+		GenUUID:   &uuid,
+		Storage: loggingv1.ElasticsearchStorageSpec{
+			Size:             resourceQuantity("20Gi"),
+			StorageClassName: &[]string{"gp2"}[0],
+		},
+	}
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "openshift-logging"},
+		Spec:       loggingv1.ElasticsearchSpec{Nodes: []loggingv1.ElasticsearchNode{node}},
+	}
+
+	er := &ElasticsearchRequest{cluster: cluster}
+	names := er.nodeNames(uuid, node)
+	for _, name := range names {
+		cluster.Status.Nodes = append(cluster.Status.Nodes, loggingv1.ElasticsearchNodeStatus{DeploymentName: name})
+	}
+
+	pending := v1.PersistentVolumeClaimCondition{
+		Type:               v1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * fileSystemResizeGracePeriod)),
+	}
+	er.client = fake.NewFakeClient(
+		cluster,
+		newClaim("elasticsearch-"+names[0], "20Gi", "gp2", pending),
+		newClaim("elasticsearch-"+names[1], "30Gi", "gp2"),
+	)
+
+	if err := er.reconcileVolumeExpansion(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	_, condition := getESNodeCondition(cluster.Status.Conditions, loggingv1.StorageResizeRejected)
+	if condition == nil || !strings.Contains(condition.Message, names[1]+" can't shrink") {
+		t.Errorf("expected the shrink to be rejected, got %v", condition)
+	}
+
+	if got := scheduledRedeployNodeNames(&cluster.Status); len(got) != 1 || got[0] != names[0] {
+		t.Errorf("expected %s to be scheduled for a restart, got %v", names[0], got)
+	}
+}
+
+func scheduledRedeployNodeNames(status *loggingv1.ElasticsearchStatus) []string {
+	names := []string{}
+	for _, node := range status.Nodes {
+		if node.UpgradeStatus.ScheduledForRedeploy == v1.ConditionTrue {
+			names = append(names, node.DeploymentName)
+		}
+	}
+	return names
+}
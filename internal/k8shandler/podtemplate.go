@@ -2,6 +2,8 @@ package k8shandler
 
 import (
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
@@ -11,14 +13,129 @@ import (
 // ArePodTemplateSpecDifferent compares two v1.PodTemplateSpecs
 // and returns True or False
 func ArePodTemplateSpecDifferent(lhs, rhs v1.PodTemplateSpec) bool {
+	if !areRolloutAnnotationsSame(lhs.Annotations, rhs.Annotations) {
+		return true
+	}
+
+	// the annotations and labels of the spec are added to the ones of the operator
+	managedAnnotations := append(managedKeys(lhs.Annotations, podAnnotationKeysAnnotation), podAnnotationKeysAnnotation, podLabelKeysAnnotation)
+	if !containsSameEntries(lhs.Annotations, rhs.Annotations, managedAnnotations) ||
+		!containsSameEntries(lhs.Labels, rhs.Labels, managedKeys(lhs.Annotations, podLabelKeysAnnotation)) {
+		return true
+	}
+
 	return ArePodSpecDifferent(lhs.Spec, rhs.Spec, true)
 }
 
+// isProxyOnlyChange returns true if the pod templates differ in the proxy container only
+func isProxyOnlyChange(current, desired v1.PodTemplateSpec) bool {
+	if !ArePodTemplateSpecDifferent(current, desired) {
+		return false
+	}
+
+	return !ArePodTemplateSpecDifferent(withoutContainer(current, proxyContainerName), withoutContainer(desired, proxyContainerName))
+}
+
+func withoutContainer(template v1.PodTemplateSpec, name string) v1.PodTemplateSpec {
+	template = *template.DeepCopy()
+
+	containers := []v1.Container{}
+	for _, container := range template.Spec.Containers {
+		if container.Name != name {
+			containers = append(containers, container)
+		}
+	}
+	template.Spec.Containers = containers
+
+	return template
+}
+
+const (
+	// podAnnotationKeysAnnotation and podLabelKeysAnnotation list the pod annotations and labels
+	// of the spec
+	podAnnotationKeysAnnotation = "elasticsearch.openshift.io/pod-annotation-keys"
+	podLabelKeysAnnotation      = "elasticsearch.openshift.io/pod-label-keys"
+)
+
+// rolloutAnnotations are the pod annotations set by the operator whose changes need new pods
+var rolloutAnnotations = []string{httpCertHashAnnotation, reindexRemoteHostsAnnotation, remoteTrustHashAnnotation}
+
+func areRolloutAnnotationsSame(lhs, rhs map[string]string) bool {
+	for _, key := range rolloutAnnotations {
+		if lhs[key] != rhs[key] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// check that all entries of rhs (desired) are contained within lhs (current) and that the
+// managed entries of lhs removed from the spec since are gone from rhs as well
+func containsSameEntries(lhs, rhs map[string]string, managed []string) bool {
+	for k, v := range rhs {
+		if current, ok := lhs[k]; !ok || current != v {
+			return false
+		}
+	}
+	for _, k := range managed {
+		_, current := lhs[k]
+		_, desired := rhs[k]
+		if current != desired {
+			return false
+		}
+	}
+	return true
+}
+
+// setManagedKeys records the keys of the entries taken from the spec in the annotation, others
+// add annotations and labels to pods too and only these may be removed again
+func setManagedKeys(annotations map[string]string, annotation string, entries map[string]string) {
+	if len(entries) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	annotations[annotation] = strings.Join(keys, ",")
+}
+
+// managedKeys returns the keys recorded in the annotation
+func managedKeys(annotations map[string]string, annotation string) []string {
+	if annotations[annotation] == "" {
+		return nil
+	}
+	return strings.Split(annotations[annotation], ",")
+}
+
 // Abstracted logic into comparing pod specs so that we can check if our change has been rolled out
 // yet or not
 func ArePodSpecDifferent(lhs, rhs v1.PodSpec, strictTolerations bool) bool {
 	changed := false
 
+	if lhs.ServiceAccountName != rhs.ServiceAccountName {
+		changed = true
+	}
+
+	if lhs.PriorityClassName != rhs.PriorityClassName {
+		changed = true
+	}
+
+	// pods may be given the pull secrets of their service account
+	if !containsSameImagePullSecrets(lhs.ImagePullSecrets, rhs.ImagePullSecrets) ||
+		(strictTolerations && len(lhs.ImagePullSecrets) != len(rhs.ImagePullSecrets)) {
+		changed = true
+	}
+
+	// admission plugins like the security context constraints of OpenShift fill in the security
+	// settings of pods, they can only be compared for the deployments and statefulsets
+	if strictTolerations && !isPodSecurityContextSame(lhs.SecurityContext, rhs.SecurityContext) {
+		changed = true
+	}
+
 	if len(lhs.Containers) != len(rhs.Containers) {
 		changed = true
 	}
@@ -85,6 +202,10 @@ func ArePodSpecDifferent(lhs, rhs v1.PodSpec, strictTolerations bool) bool {
 			if different, _ := utils.CompareResources(lContainer.Resources, rContainer.Resources); different {
 				changed = true
 			}
+
+			if strictTolerations && !reflect.DeepEqual(lContainer.SecurityContext, rContainer.SecurityContext) {
+				changed = true
+			}
 		}
 
 		if !found {
@@ -94,6 +215,33 @@ func ArePodSpecDifferent(lhs, rhs v1.PodSpec, strictTolerations bool) bool {
 	return changed
 }
 
+// check that all of rhs (desired) are contained within lhs (current)
+func containsSameImagePullSecrets(lhs, rhs []v1.LocalObjectReference) bool {
+	for _, rSecret := range rhs {
+		found := false
+		for _, lSecret := range lhs {
+			if lSecret.Name == rSecret.Name {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// the api server defaults the security context of pod templates to an empty one
+func isPodSecurityContextSame(lhs, rhs *v1.PodSecurityContext) bool {
+	if lhs == nil {
+		lhs = &v1.PodSecurityContext{}
+	}
+	if rhs == nil {
+		rhs = &v1.PodSecurityContext{}
+	}
+	return reflect.DeepEqual(lhs, rhs)
+}
+
 // check that all of rhs (desired) are contained within lhs (current)
 func containsSameVolumeMounts(lhs, rhs []v1.VolumeMount) bool {
 	for _, rVolumeMount := range rhs {
@@ -757,4 +757,134 @@ var _ = Describe("podtemplate", func() {
 			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
 		})
 	})
+
+	Context("http certificate change", func() {
+		BeforeEach(func() {
+			lhs.Annotations = map[string]string{httpCertHashAnnotation: "old"}
+
+			rhs = *lhs.DeepCopy()
+		})
+
+		It("should recognize a changed certificate hash", func() {
+			rhs.Annotations[httpCertHashAnnotation] = "new"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should ignore annotations added by others", func() {
+			lhs.Annotations["kubectl.kubernetes.io/restartedAt"] = "now"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeFalse())
+		})
+
+		It("should recognize added pod annotations", func() {
+			rhs.Annotations["other"] = "value"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+	})
+
+	Context("pod settings change", func() {
+		BeforeEach(func() {
+			lhs.Labels = map[string]string{"component": "elasticsearch"}
+			lhs.Spec.ServiceAccountName = "elasticsearch"
+
+			rhs = *lhs.DeepCopy()
+		})
+
+		It("should ignore the defaulted pod security context", func() {
+			lhs.Spec.SecurityContext = &v1.PodSecurityContext{}
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeFalse())
+		})
+
+		It("should recognize a changed service account", func() {
+			rhs.Spec.ServiceAccountName = "custom"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize a changed priority class", func() {
+			rhs.Spec.PriorityClassName = "high"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize added image pull secrets", func() {
+			rhs.Spec.ImagePullSecrets = []v1.LocalObjectReference{{Name: "registry"}}
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize a changed pod security context", func() {
+			nonRoot := true
+			rhs.Spec.SecurityContext = &v1.PodSecurityContext{RunAsNonRoot: &nonRoot}
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize a changed container security context", func() {
+			privileged := false
+			rhs.Spec.Containers[0].SecurityContext = &v1.SecurityContext{AllowPrivilegeEscalation: &privileged}
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize added pod labels", func() {
+			rhs.Labels["team"] = "a"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should ignore security settings and pull secrets admission adds to pods", func() {
+			runAsUser := int64(1000)
+			lhs.Spec.SecurityContext = &v1.PodSecurityContext{RunAsUser: &runAsUser}
+			lhs.Spec.Containers[0].SecurityContext = &v1.SecurityContext{RunAsUser: &runAsUser}
+			lhs.Spec.ImagePullSecrets = []v1.LocalObjectReference{{Name: "elasticsearch-dockercfg"}}
+			Expect(ArePodSpecDifferent(lhs.Spec, rhs.Spec, false)).To(BeFalse())
+		})
+	})
+
+	Context("pod annotations and labels removed from the spec", func() {
+		BeforeEach(func() {
+			lhs.Annotations = map[string]string{"team": "a", podAnnotationKeysAnnotation: "team", podLabelKeysAnnotation: "tier"}
+			lhs.Labels = map[string]string{"component": "elasticsearch", "tier": "storage"}
+
+			rhs = *lhs.DeepCopy()
+		})
+
+		It("should recognize a removed pod annotation", func() {
+			delete(rhs.Annotations, "team")
+			delete(rhs.Annotations, podAnnotationKeysAnnotation)
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should recognize a removed pod label", func() {
+			delete(rhs.Labels, "tier")
+			delete(rhs.Annotations, podLabelKeysAnnotation)
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should ignore annotations and labels added by others", func() {
+			lhs.Annotations["kubectl.kubernetes.io/restartedAt"] = "now"
+			lhs.Labels["pod-template-hash"] = "abcd"
+			Expect(ArePodTemplateSpecDifferent(lhs, rhs)).To(BeFalse())
+		})
+	})
+
+	Context("proxy only change", func() {
+		BeforeEach(func() {
+			lhs.Spec.Containers = append(lhs.Spec.Containers, v1.Container{
+				Name: proxyContainerName,
+				Args: []string{"--auth-default-role=project_user"},
+			})
+
+			rhs = *lhs.DeepCopy()
+		})
+
+		It("should recognize a change of the proxy args", func() {
+			rhs.Spec.Containers[1].Args = append(rhs.Spec.Containers[1].Args, "--auth-backend-role=audit={}")
+			Expect(isProxyOnlyChange(lhs, rhs)).To(BeTrue())
+		})
+
+		It("should not consider a change of the other containers", func() {
+			rhs.Spec.Containers[1].Args = append(rhs.Spec.Containers[1].Args, "--auth-backend-role=audit={}")
+			rhs.Spec.Containers[0].Image = "new-image"
+			Expect(isProxyOnlyChange(lhs, rhs)).To(BeFalse())
+		})
+
+		It("should not consider unchanged templates", func() {
+			Expect(isProxyOnlyChange(lhs, rhs)).To(BeFalse())
+		})
+	})
 })
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/ViaQ/logerr/kverrors"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	"github.com/openshift/elasticsearch-operator/internal/utils"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,6 +23,10 @@ const (
 )
 
 func (er *ElasticsearchRequest) CreateOrUpdatePrometheusRules() error {
+	if !er.capabilities.Has(platform.Monitoring) {
+		return nil
+	}
+
 	ctx := context.TODO()
 	dpl := er.cluster
 
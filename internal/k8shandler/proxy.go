@@ -0,0 +1,98 @@
+package k8shandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/ViaQ/logerr/kverrors"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+const proxyContainerName = "proxy"
+
+// defaultProxyAuthRoles are the backend roles the operator and the rest of the logging
+// stack rely on, they can't be redefined by the spec
+var defaultProxyAuthRoles = []string{
+	"admin_reader",
+	"prometheus",
+	"jaeger",
+	"elasticsearch-operator",
+	"index-management",
+}
+
+var proxyAuthRoleName = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// proxyAuthRoleReview is the SubjectAccessReview the proxy performs to grant a backend role
+type proxyAuthRoleReview struct {
+	Namespace        string `json:"namespace,omitempty"`
+	Verb             string `json:"verb"`
+	Resource         string `json:"resource"`
+	ResourceAPIGroup string `json:"resourceAPIGroup,omitempty"`
+}
+
+// newProxyAuthRoleArgs returns the --auth-backend-role arguments of the proxy, the
+// roles of the spec follow the default ones
+func newProxyAuthRoleArgs(namespace string, proxy *api.ElasticsearchProxySpec) []string {
+	args := []string{
+		`--auth-backend-role=admin_reader={"namespace": "default", "verb": "get", "resource": "pods/log"}`,
+		`--auth-backend-role=prometheus={"verb": "get", "resource": "/metrics"}`,
+		`--auth-backend-role=jaeger={"verb": "get", "resource": "/jaeger", "resourceAPIGroup": "elasticsearch.jaegertracing.io"}`,
+		`--auth-backend-role=elasticsearch-operator={"namespace": "*", "verb": "*", "resource": "*", "resourceAPIGroup": "logging.openshift.io"}`,
+		fmt.Sprintf("--auth-backend-role=index-management={\"namespace\":\"%s\", \"verb\": \"*\", \"resource\": \"indices\", \"resourceAPIGroup\": \"elasticsearch.openshift.io\"}", namespace),
+	}
+
+	if proxy == nil {
+		return args
+	}
+
+	for _, role := range proxy.AuthRoles {
+		review, err := json.Marshal(proxyAuthRoleReview{
+			Namespace:        role.Namespace,
+			Verb:             role.Verb,
+			Resource:         role.Resource,
+			ResourceAPIGroup: role.ResourceAPIGroup,
+		})
+		if err != nil {
+			// can't happen for a struct of strings
+			continue
+		}
+		args = append(args, fmt.Sprintf("--auth-backend-role=%s=%s", role.Name, review))
+	}
+
+	return args
+}
+
+// validateProxyAuthRoles ensures the roles of the spec can be passed to the proxy
+// and don't replace any of the default roles
+func validateProxyAuthRoles(dpl *api.Elasticsearch) error {
+	proxy := dpl.Spec.Proxy
+	if proxy == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for _, role := range proxy.AuthRoles {
+		if !proxyAuthRoleName.MatchString(role.Name) {
+			return kverrors.New("invalid proxy auth role name, only letters, digits, '_', '.' and '-' are allowed",
+				"role", role.Name)
+		}
+		if sliceContainsString(defaultProxyAuthRoles, role.Name) {
+			return kverrors.New("proxy auth role redefines a default role",
+				"role", role.Name)
+		}
+		if seen[role.Name] {
+			return kverrors.New("proxy auth role is defined more than once",
+				"role", role.Name)
+		}
+		seen[role.Name] = true
+
+		if role.Verb == "" || role.Resource == "" {
+			return kverrors.New("proxy auth role requires a verb and a resource",
+				"role", role.Name)
+		}
+	}
+
+	return nil
+}
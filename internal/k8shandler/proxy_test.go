@@ -0,0 +1,83 @@
+package k8shandler
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestNewProxyAuthRoleArgs(t *testing.T) {
+	defaults := newProxyAuthRoleArgs("openshift-logging", nil)
+	if len(defaults) != len(defaultProxyAuthRoles) {
+		t.Fatalf("expected %d default roles, got %v", len(defaultProxyAuthRoles), defaults)
+	}
+
+	args := newProxyAuthRoleArgs("openshift-logging", &api.ElasticsearchProxySpec{
+		AuthRoles: []api.ElasticsearchProxyAuthRole{
+			{
+				Name:     "audit_reader",
+				Verb:     "get",
+				Resource: "/audit",
+			},
+		},
+	})
+
+	if strings.Join(args[:len(defaults)], "\n") != strings.Join(defaults, "\n") {
+		t.Errorf("expected the default roles to be kept, got %v", args)
+	}
+
+	want := `--auth-backend-role=audit_reader={"verb":"get","resource":"/audit"}`
+	if got := args[len(args)-1]; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestValidateProxyAuthRoles(t *testing.T) {
+	tests := []struct {
+		desc  string
+		roles []api.ElasticsearchProxyAuthRole
+		valid bool
+	}{
+		{
+			desc:  "valid role",
+			roles: []api.ElasticsearchProxyAuthRole{{Name: "audit_reader", Verb: "get", Resource: "pods/log"}},
+			valid: true,
+		},
+		{
+			desc:  "invalid name",
+			roles: []api.ElasticsearchProxyAuthRole{{Name: "audit=reader", Verb: "get", Resource: "pods/log"}},
+		},
+		{
+			desc:  "default role",
+			roles: []api.ElasticsearchProxyAuthRole{{Name: "prometheus", Verb: "get", Resource: "/metrics"}},
+		},
+		{
+			desc: "duplicate role",
+			roles: []api.ElasticsearchProxyAuthRole{
+				{Name: "audit_reader", Verb: "get", Resource: "pods/log"},
+				{Name: "audit_reader", Verb: "list", Resource: "pods/log"},
+			},
+		},
+		{
+			desc:  "missing resource",
+			roles: []api.ElasticsearchProxyAuthRole{{Name: "audit_reader", Verb: "get"}},
+		},
+	}
+
+	for _, test := range tests {
+		cluster := &api.Elasticsearch{
+			Spec: api.ElasticsearchSpec{
+				Proxy: &api.ElasticsearchProxySpec{AuthRoles: test.roles},
+			},
+		}
+
+		err := validateProxyAuthRoles(cluster)
+		if test.valid && err != nil {
+			t.Errorf("%s: got err: %s", test.desc, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.desc)
+		}
+	}
+}
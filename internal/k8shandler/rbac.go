@@ -7,6 +7,7 @@ import (
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/ViaQ/logerr/log"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -98,13 +99,15 @@ func (er *ElasticsearchRequest) CreateOrUpdateRBAC() error {
 
 	subjects := []rbac.Subject{}
 	for _, es := range esList.Items {
-		subject = newSubject(
-			"ServiceAccount",
-			es.Name,
-			es.Namespace,
-		)
-		subject.APIGroup = ""
-		subjects = append(subjects, subject)
+		for _, name := range podServiceAccountNames(&es) {
+			subject = newSubject(
+				"ServiceAccount",
+				name,
+				es.Namespace,
+			)
+			subject.APIGroup = ""
+			subjects = append(subjects, subject)
+		}
 	}
 
 	proxyRoleBinding := newClusterRoleBinding(
@@ -119,6 +122,21 @@ func (er *ElasticsearchRequest) CreateOrUpdateRBAC() error {
 	return reconcileIndexManagmentRbac(dpl, er.client)
 }
 
+// podServiceAccountNames returns the service account created for the cluster and the ones
+// the nodes run as instead
+func podServiceAccountNames(cluster *v1.Elasticsearch) []string {
+	names := sets.NewString(cluster.Name)
+	if name := cluster.Spec.Spec.ServiceAccountName; name != "" {
+		names.Insert(name)
+	}
+	for _, node := range cluster.Spec.Nodes {
+		if node.ServiceAccountName != "" {
+			names.Insert(node.ServiceAccountName)
+		}
+	}
+	return names.List()
+}
+
 func createOrUpdateClusterRole(role *rbac.ClusterRole, client client.Client) error {
 	if err := client.Create(context.TODO(), role); err != nil {
 		if !apierrors.IsAlreadyExists(kverrors.Root(err)) {
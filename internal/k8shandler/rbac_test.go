@@ -0,0 +1,31 @@
+package k8shandler
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func TestPodServiceAccountNames(t *testing.T) {
+	cluster := &loggingv1.Elasticsearch{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch"},
+		Spec: loggingv1.ElasticsearchSpec{
+			Spec: loggingv1.ElasticsearchNodeSpec{
+				ElasticsearchPodSpec: loggingv1.ElasticsearchPodSpec{ServiceAccountName: "logging"},
+			},
+			Nodes: []loggingv1.ElasticsearchNode{
+				{},
+				{ElasticsearchPodSpec: loggingv1.ElasticsearchPodSpec{ServiceAccountName: "restricted"}},
+				{ElasticsearchPodSpec: loggingv1.ElasticsearchPodSpec{ServiceAccountName: "logging"}},
+			},
+		},
+	}
+
+	want := []string{"elasticsearch", "logging", "restricted"}
+	if got := podServiceAccountNames(cluster); !reflect.DeepEqual(got, want) {
+		t.Errorf("got service accounts %v, want %v", got, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	elasticsearchv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
@@ -15,10 +16,12 @@ import (
 )
 
 type ElasticsearchRequest struct {
-	client   client.Client
-	cluster  *elasticsearchv1.Elasticsearch
-	esClient elasticsearch.Client
-	ll       logr.Logger
+	client       client.Client
+	apiReader    client.Reader
+	cluster      *elasticsearchv1.Elasticsearch
+	esClient     elasticsearch.Client
+	capabilities platform.Capabilities
+	ll           logr.Logger
 }
 
 // L is the logger used for this request.
@@ -39,11 +42,16 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 		ll:      log.WithValues("cluster", requestCluster.Name, "namespace", requestCluster.Namespace),
 	}
 
+	defer elasticsearchRequest.lockCluster()()
+
 	// evaluate if we are missing the required secret/certs
 	if ok, missing := elasticsearchRequest.hasRequiredSecrets(); !ok {
 		elasticsearchRequest.UpdateDegradedCondition(true, "Missing Required Secrets", missing)
 	}
 
+	// nothing to compare against yet if the nodes still run with the current secret
+	elasticsearchRequest.observeSecretKeys()
+
 	newSecretHash := getSecretDataHash(requestCluster.Name, requestCluster.Namespace, requestClient)
 
 	nretries := -1
@@ -56,8 +64,10 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 		}
 
 		// compare the new secret with current one in the nodes
-		for _, node := range nodes[nodeMapKey(requestCluster.Name, requestCluster.Namespace)] {
+		outdated := false
+		for _, node := range elasticsearchRequest.getNodes() {
 			if node.getSecretHash() != "" && newSecretHash != node.getSecretHash() {
+				outdated = true
 
 				// Cluster's secret has been updated, update the cluster status to be redeployed
 				_, nodeStatus := getNodeStatus(node.name(), &cluster.Status)
@@ -68,6 +78,11 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 			}
 		}
 
+		// diff the secret keys to choose between a rolling and a full cluster restart
+		if outdated && elasticsearchRequest.planCertRedeploy(&cluster.Status) {
+			secretChanged = true
+		}
+
 		if secretChanged {
 			if err := requestClient.Status().Update(context.TODO(), cluster); err != nil {
 				return err
@@ -85,14 +100,22 @@ func SecretReconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClien
 	return nil
 }
 
-func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client) error {
+func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient client.Client, apiReader client.Reader, capabilities platform.Capabilities) error {
 	esClient := elasticsearch.NewClient(requestCluster.Name, requestCluster.Namespace, requestClient)
 
 	elasticsearchRequest := ElasticsearchRequest{
-		client:   requestClient,
-		cluster:  requestCluster,
-		esClient: esClient,
-		ll:       log.WithValues("cluster", requestCluster.Name, "namespace", requestCluster.Namespace),
+		client:       requestClient,
+		apiReader:    apiReader,
+		cluster:      requestCluster,
+		esClient:     esClient,
+		capabilities: capabilities,
+		ll:           log.WithValues("cluster", requestCluster.Name, "namespace", requestCluster.Namespace),
+	}
+
+	defer elasticsearchRequest.lockCluster()()
+
+	if err := updateIntegrationsDisabledCondition(requestCluster, capabilities.Disabled(), requestClient); err != nil {
+		return err
 	}
 
 	degradedCondition := false
@@ -107,6 +130,16 @@ func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient clie
 		return kverrors.Wrap(err, "Failed to reconcile Roles and RoleBindings for Elasticsearch cluster")
 	}
 
+	// Ensure existence of the certificates when the operator generates them
+	if err := elasticsearchRequest.CreateOrUpdateCertificates(); err != nil {
+		return kverrors.Wrap(err, "Failed to reconcile Certificates for Elasticsearch cluster")
+	}
+
+	// Ensure the nodes trust the certificate authorities of the remote clusters
+	if err := elasticsearchRequest.CreateOrUpdateRemoteTrust(); err != nil {
+		elasticsearchRequest.L().Error(err, "Failed to reconcile trust of remote clusters")
+	}
+
 	// Ensure existence of config maps
 	if err := elasticsearchRequest.CreateOrUpdateConfigMaps(); err != nil {
 		return kverrors.Wrap(err, "Failed to reconcile ConfigMaps for Elasticsearch cluster")
@@ -116,6 +149,14 @@ func Reconcile(requestCluster *elasticsearchv1.Elasticsearch, requestClient clie
 		return kverrors.Wrap(err, "Failed to reconcile Services for Elasticsearch cluster")
 	}
 
+	if err := elasticsearchRequest.CreateOrUpdateNetworkPolicy(); err != nil {
+		return kverrors.Wrap(err, "Failed to reconcile NetworkPolicy for Elasticsearch cluster")
+	}
+
+	if err := elasticsearchRequest.CreateOrUpdateExposure(); err != nil {
+		return kverrors.Wrap(err, "Failed to reconcile exposure for Elasticsearch cluster")
+	}
+
 	if err := elasticsearchRequest.CreateOrUpdateDashboards(); err != nil {
 		return kverrors.Wrap(err, "Failed to reconcile Dashboards for Elasticsearch cluster")
 	}
@@ -0,0 +1,267 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+// reindexFinalizer keeps the reindex around until its task was cancelled
+const reindexFinalizer = "logging.openshift.io/reindex"
+
+func isReindexDone(phase api.ElasticsearchReindexPhase) bool {
+	return phase == api.ReindexCompleted || phase == api.ReindexFailed || phase == api.ReindexCancelled
+}
+
+// newReIndex is the body of the reindex request, the source lists the indices and patterns
+// separated by commas
+func newReIndex(reindex *api.ElasticsearchReindex) *estypes.ReIndex {
+	body := &estypes.ReIndex{
+		Source: estypes.IndexRef{Index: strings.Join(reindex.Spec.Source, ",")},
+		Dest:   estypes.IndexRef{Index: reindex.Spec.Destination},
+	}
+	if script := reindex.Spec.Script; script != nil {
+		lang := script.Lang
+		if lang == "" {
+			lang = "painless"
+		}
+		body.Script = &estypes.ReIndexScript{Inline: script.Source, Lang: lang}
+	}
+	return body
+}
+
+// ReconcileReindex starts the reindex task of the reindex, follows its progress and moves
+// the alias to the destination index once it completes
+func ReconcileReindex(reindex *api.ElasticsearchReindex, c client.Client, esClient elasticsearch.Client) error {
+	if reindex.GetDeletionTimestamp() != nil {
+		return removeReindex(reindex, c, esClient)
+	}
+
+	if !utils.ContainsString(reindex.GetFinalizers(), reindexFinalizer) {
+		reindex.SetFinalizers(append(reindex.GetFinalizers(), reindexFinalizer))
+		if err := c.Update(context.TODO(), reindex); err != nil {
+			return kverrors.Wrap(err, "failed to add finalizer to reindex",
+				"reindex", reindex.Name)
+		}
+	}
+
+	if isReindexDone(reindex.Status.Phase) {
+		return nil
+	}
+
+	status := *reindex.Status.DeepCopy()
+
+	cluster := &api.Elasticsearch{}
+	key := types.NamespacedName{Name: reindex.Spec.ClusterName, Namespace: reindex.Namespace}
+	if err := c.Get(context.TODO(), key, cluster); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to get elasticsearch cluster",
+				"cluster", reindex.Spec.ClusterName)
+		}
+		status.Phase = api.ReindexPending
+		status.Message = fmt.Sprintf("Elasticsearch cluster %q not found", reindex.Spec.ClusterName)
+		return updateReindexStatus(reindex, status, c)
+	}
+
+	var err error
+	if status.Phase == api.ReindexRunning {
+		err = followReindexTask(reindex, &status, esClient)
+	} else {
+		err = startReindexTask(reindex, &status, esClient)
+	}
+
+	if updateErr := updateReindexStatus(reindex, status, c); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// startReindexTask starts the reindex in the background once the cluster is reachable
+func startReindexTask(reindex *api.ElasticsearchReindex, status *api.ElasticsearchReindexStatus, esClient elasticsearch.Client) error {
+	if reindex.Spec.Cancel {
+		status.Phase = api.ReindexCancelled
+		status.Message = "Cancelled before the reindex started"
+		return nil
+	}
+
+	for _, source := range reindex.Spec.Source {
+		if matched, _ := path.Match(source, reindex.Spec.Destination); matched {
+			status.Phase = api.ReindexFailed
+			status.Message = fmt.Sprintf("The destination index %s matches the source %s", reindex.Spec.Destination, source)
+			return nil
+		}
+	}
+
+	health, err := esClient.GetClusterHealthStatus()
+	if err != nil || (health != "green" && health != "yellow") {
+		status.Phase = api.ReindexPending
+		status.Message = fmt.Sprintf("Waiting for cluster %q to be available", reindex.Spec.ClusterName)
+		return nil
+	}
+
+	indices, err := esClient.GetAllIndices(strings.Join(reindex.Spec.Source, ","))
+	if err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		status.Phase = api.ReindexFailed
+		status.Message = fmt.Sprintf("No indices match the source %s", strings.Join(reindex.Spec.Source, ","))
+		return nil
+	}
+
+	taskID, err := esClient.StartReIndex(newReIndex(reindex), reindex.Spec.Slices, reindex.Spec.RequestsPerSecond)
+	if err != nil {
+		return err
+	}
+	status.Phase = api.ReindexRunning
+	status.Task = taskID
+	status.RequestsPerSecond = reindex.Spec.RequestsPerSecond
+	status.Message = fmt.Sprintf("Reindexing %d indices into %s", len(indices), reindex.Spec.Destination)
+	return nil
+}
+
+// followReindexTask applies cancellation and throttle changes to the task while it is still
+// running and records its progress
+func followReindexTask(reindex *api.ElasticsearchReindex, status *api.ElasticsearchReindexStatus, esClient elasticsearch.Client) error {
+	task, err := esClient.GetReIndexTask(status.Task)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		// the task is lost when its node restarts, reindexing again overwrites the documents
+		// copied so far
+		status.Phase = api.ReindexPending
+		status.Task = ""
+		status.Message = "The reindex task was lost, restarting it"
+		return nil
+	}
+
+	if !task.Completed && reindex.Spec.Cancel {
+		if err := esClient.CancelTask(status.Task); err != nil {
+			return err
+		}
+		status.Phase = api.ReindexCancelled
+		status.Message = fmt.Sprintf("Cancelled after copying %d of %d documents", task.Task.Status.Created+task.Task.Status.Updated, task.Task.Status.Total)
+		return nil
+	}
+
+	if !task.Completed && reindex.Spec.RequestsPerSecond != status.RequestsPerSecond {
+		if err := esClient.RethrottleReIndex(status.Task, reindex.Spec.RequestsPerSecond); err != nil {
+			return err
+		}
+		status.RequestsPerSecond = reindex.Spec.RequestsPerSecond
+	}
+
+	status.Total = task.Task.Status.Total
+	status.Created = task.Task.Status.Created
+	status.Updated = task.Task.Status.Updated
+	if !task.Completed {
+		status.Message = fmt.Sprintf("Copied %d of %d documents", status.Created+status.Updated, status.Total)
+		return nil
+	}
+
+	if task.Error != nil {
+		status.Phase = api.ReindexFailed
+		status.Message = fmt.Sprintf("%v", task.Error["reason"])
+		return nil
+	}
+	if task.Response != nil && len(task.Response.Failures) > 0 {
+		status.Phase = api.ReindexFailed
+		status.Message = fmt.Sprintf("%d documents failed to reindex", len(task.Response.Failures))
+		return nil
+	}
+
+	if reindex.Spec.Alias != "" {
+		if err := swapReindexAlias(reindex.Spec.Alias, reindex.Spec.Destination, esClient); err != nil {
+			return err
+		}
+	}
+	status.Phase = api.ReindexCompleted
+	status.Message = fmt.Sprintf("Copied %d documents", status.Created+status.Updated)
+	return nil
+}
+
+// swapReindexAlias moves the alias from the indices holding it to the destination index in
+// a single request, so searches never see both or neither
+func swapReindexAlias(alias, destination string, esClient elasticsearch.Client) error {
+	holders, err := esClient.ListIndicesForAlias(alias)
+	if err != nil {
+		return err
+	}
+	sort.Strings(holders)
+
+	actions := estypes.AliasActions{}
+	for _, index := range holders {
+		if index == destination {
+			continue
+		}
+		actions.Actions = append(actions.Actions, estypes.AliasAction{
+			Remove: &estypes.RemoveAliasAction{Index: index, Alias: alias},
+		})
+	}
+	actions.Actions = append(actions.Actions, estypes.AliasAction{
+		Add: &estypes.AddAliasAction{Index: destination, Alias: alias},
+	})
+	return esClient.UpdateAlias(actions)
+}
+
+// removeReindex cancels the running reindex task and releases the reindex
+func removeReindex(reindex *api.ElasticsearchReindex, c client.Client, esClient elasticsearch.Client) error {
+	if !utils.ContainsString(reindex.GetFinalizers(), reindexFinalizer) {
+		return nil
+	}
+
+	if reindex.Status.Phase == api.ReindexRunning {
+		if err := esClient.CancelTask(reindex.Status.Task); err != nil {
+			log.Error(err, "failed to cancel reindex task",
+				"reindex", reindex.Name,
+				"task", reindex.Status.Task)
+		}
+	}
+
+	reindex.SetFinalizers(utils.RemoveString(reindex.GetFinalizers(), reindexFinalizer))
+	if err := c.Update(context.TODO(), reindex); err != nil {
+		return kverrors.Wrap(err, "failed to remove finalizer from reindex",
+			"reindex", reindex.Name)
+	}
+	return nil
+}
+
+func updateReindexStatus(reindex *api.ElasticsearchReindex, status api.ElasticsearchReindexStatus, c client.Client) error {
+	if reflect.DeepEqual(reindex.Status, status) {
+		return nil
+	}
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &api.ElasticsearchReindex{}
+		key := types.NamespacedName{Name: reindex.Name, Namespace: reindex.Namespace}
+		if err := c.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+
+		current.Status = status
+		return c.Status().Update(context.TODO(), current)
+	})
+	if retryErr != nil {
+		return kverrors.Wrap(retryErr, "failed to update reindex status",
+			"reindex", reindex.Name)
+	}
+
+	reindex.Status = status
+	return nil
+}
@@ -0,0 +1,180 @@
+package k8shandler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newReindex() *loggingv1.ElasticsearchReindex {
+	return &loggingv1.ElasticsearchReindex{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "fix-mappings",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchReindexSpec{
+			ClusterName:       "elasticsearch",
+			Source:            []string{"app-000001"},
+			Destination:       "app-000001-fixed",
+			Script:            &loggingv1.ElasticsearchReindexScript{Source: `ctx._source.remove("message")`},
+			Slices:            3,
+			RequestsPerSecond: 1000,
+			Alias:             "app",
+		},
+	}
+}
+
+func getReindex(t *testing.T, c client.Client) *loggingv1.ElasticsearchReindex {
+	reindex := &loggingv1.ElasticsearchReindex{}
+	key := types.NamespacedName{Name: "fix-mappings", Namespace: "openshift-logging"}
+	if err := c.Get(context.TODO(), key, reindex); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	return reindex
+}
+
+func TestReconcileReindex(t *testing.T) {
+	reindex := newReindex()
+
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/health":                     {{StatusCode: http.StatusOK, Body: `{"status": "green"}`}},
+		"_cat/indices/app-000001?format=json": {{StatusCode: http.StatusOK, Body: `[{"index": "app-000001"}]`}},
+		"_reindex?wait_for_completion=false&slices=3&requests_per_second=1000": {
+			{StatusCode: http.StatusOK, Body: `{"task": "node:1"}`},
+		},
+		"_tasks/node:1": {
+			{StatusCode: http.StatusOK, Body: `{"completed": false, "task": {"status": {"total": 10, "created": 4}}}`},
+			{StatusCode: http.StatusOK, Body: `{"completed": false, "task": {"status": {"total": 10, "created": 6}}}`},
+			{StatusCode: http.StatusOK, Body: `{"completed": true, "task": {"status": {"total": 10, "created": 10}}, "response": {"failures": []}}`},
+		},
+		"_reindex/node:1/_rethrottle?requests_per_second=-1": {{StatusCode: http.StatusOK, Body: `{"nodes": {}}`}},
+		"_alias/app": {{StatusCode: http.StatusOK, Body: `{"app-000001": {"aliases": {"app": {}}}}`}},
+		"_aliases":   {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+	})
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, reindex)
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	reindex = getReindex(t, er.client)
+	if reindex.Status.Phase != loggingv1.ReindexRunning || reindex.Status.Task != "node:1" {
+		t.Fatalf("expected the reindex to run, got %s: %s", reindex.Status.Phase, reindex.Status.Message)
+	}
+	if !reflect.DeepEqual(reindex.Finalizers, []string{reindexFinalizer}) {
+		t.Errorf("expected the finalizer to be added, got %v", reindex.Finalizers)
+	}
+	request, _ := chatter.GetRequest("_reindex?wait_for_completion=false&slices=3&requests_per_second=1000")
+	want := `{"source":{"index":"app-000001"},"dest":{"index":"app-000001-fixed"},"script":{"inline":"ctx._source.remove(\"message\")","lang":"painless"}}`
+	if request.Body != want {
+		t.Errorf("got body %s, want %s", request.Body, want)
+	}
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if reindex = getReindex(t, er.client); reindex.Status.Created != 4 || reindex.Status.Total != 10 {
+		t.Errorf("expected the progress to be reported, got %v", reindex.Status)
+	}
+
+	reindex.Spec.RequestsPerSecond = 0
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, found := chatter.GetRequest("_reindex/node:1/_rethrottle?requests_per_second=-1"); !found {
+		t.Errorf("expected the running reindex to be unthrottled")
+	}
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	reindex = getReindex(t, er.client)
+	if reindex.Status.Phase != loggingv1.ReindexCompleted || reindex.Status.Created != 10 {
+		t.Errorf("expected the reindex to complete, got %s: %s", reindex.Status.Phase, reindex.Status.Message)
+	}
+	request, _ = chatter.GetRequest("_aliases")
+	want = `{"actions":[{"remove":{"index":"app-000001","alias":"app"}},{"add":{"index":"app-000001-fixed","alias":"app"}}]}`
+	if request == nil || request.Body != want {
+		t.Errorf("expected the alias to be swapped with %s, got %v", want, request)
+	}
+}
+
+func TestReconcileReindexCancel(t *testing.T) {
+	reindex := newReindex()
+	reindex.Spec.Cancel = true
+	reindex.Status = loggingv1.ElasticsearchReindexStatus{
+		Phase:             loggingv1.ReindexRunning,
+		Task:              "node:1",
+		RequestsPerSecond: 1000,
+		Total:             10,
+		Created:           4,
+	}
+
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_tasks/node:1":         {{StatusCode: http.StatusOK, Body: `{"completed": false, "task": {"status": {"total": 10, "created": 4}}}`}},
+		"_tasks/node:1/_cancel": {{StatusCode: http.StatusOK, Body: `{"nodes": {}}`}},
+	})
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, reindex)
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if _, found := chatter.GetRequest("_tasks/node:1/_cancel"); !found {
+		t.Errorf("expected the reindex task to be cancelled")
+	}
+	reindex = getReindex(t, er.client)
+	if reindex.Status.Phase != loggingv1.ReindexCancelled || reindex.Status.Message != "Cancelled after copying 4 of 10 documents" {
+		t.Errorf("expected the reindex to be cancelled, got %s: %s", reindex.Status.Phase, reindex.Status.Message)
+	}
+}
+
+func TestReconcileReindexCompletedBeforeChanges(t *testing.T) {
+	reindex := newReindex()
+	reindex.Spec.Alias = ""
+	reindex.Spec.Cancel = true
+	reindex.Spec.RequestsPerSecond = 0
+	reindex.Status = loggingv1.ElasticsearchReindexStatus{
+		Phase:             loggingv1.ReindexRunning,
+		Task:              "node:1",
+		RequestsPerSecond: 1000,
+	}
+
+	// rethrottling or cancelling the completed task would fail with a missing task
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_tasks/node:1": {{StatusCode: http.StatusOK, Body: `{"completed": true, "task": {"status": {"total": 10, "created": 10}}, "response": {"failures": []}}`}},
+	})
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, reindex)
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	reindex = getReindex(t, er.client)
+	if reindex.Status.Phase != loggingv1.ReindexCompleted || reindex.Status.Created != 10 {
+		t.Errorf("expected the reindex to complete, got %s: %s", reindex.Status.Phase, reindex.Status.Message)
+	}
+}
+
+func TestReconcileReindexRejectsDestinationInSource(t *testing.T) {
+	reindex := newReindex()
+	reindex.Spec.Source = []string{"app-*"}
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), nil, reindex)
+
+	if err := ReconcileReindex(reindex, er.client, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	reindex = getReindex(t, er.client)
+	if reindex.Status.Phase != loggingv1.ReindexFailed {
+		t.Errorf("expected the reindex to fail, got %s: %s", reindex.Status.Phase, reindex.Status.Message)
+	}
+}
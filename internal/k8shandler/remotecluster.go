@@ -0,0 +1,342 @@
+package k8shandler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ViaQ/logerr/kverrors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/certificates"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+)
+
+const (
+	// remoteTrustPath is where the nodes read their transport certificate from while they
+	// trust the certificate authorities of remote clusters
+	remoteTrustPath = "/etc/elasticsearch/remote-trust"
+
+	// remoteTrustCAKey is the key of the certificate authorities the nodes trust on the
+	// transport layer, the own one first
+	remoteTrustCAKey = "ca-bundle.crt"
+
+	// remoteCAKey is the key of the certificate authority in the secret named by caSecretName
+	remoteCAKey = "ca.crt"
+
+	// remoteTrustHashAnnotation is the pod template annotation that rolls out changes to the
+	// certificate authorities the nodes trust
+	remoteTrustHashAnnotation = "elasticsearch.openshift.io/remote-trust-hash"
+
+	// remoteTrustRetiredAnnotation marks the remote trust secret once no remote cluster is listed,
+	// the pod templates no longer mount it and it is deleted once the nodes rolled out
+	remoteTrustRetiredAnnotation = "elasticsearch.openshift.io/remote-trust-retired"
+)
+
+// remoteTrustSecretName is the secret holding the transport certificate of the nodes in the
+// PEM formats of the security plugin and the certificate authorities they trust
+func remoteTrustSecretName(clusterName string) string {
+	return fmt.Sprintf("%s-remote-trust", clusterName)
+}
+
+// remoteClusterSeeds returns the transport endpoints the nodes connect to the remote cluster
+// with, a referenced cluster is reached through its cluster service
+func remoteClusterSeeds(cluster *api.Elasticsearch, remote api.ElasticsearchRemoteCluster) []string {
+	ref := remote.ElasticsearchRef
+	if ref == nil {
+		return remote.Seeds
+	}
+
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = cluster.Namespace
+	}
+	return []string{fmt.Sprintf("%s-cluster.%s.svc:%d", ref.Name, namespace, transportPort)}
+}
+
+// remoteClusterCA returns the certificate authority of the remote cluster or nil if none was named
+func (er *ElasticsearchRequest) remoteClusterCA(remote api.ElasticsearchRemoteCluster) ([]byte, error) {
+	secretName, namespace, key := remote.CASecretName, er.cluster.Namespace, remoteCAKey
+	if ref := remote.ElasticsearchRef; ref != nil {
+		secretName, key = ref.Name, adminCAKey
+		if ref.Namespace != "" {
+			namespace = ref.Namespace
+		}
+	}
+	if secretName == "" {
+		return nil, nil
+	}
+
+	secret, err := getSecret(secretName, namespace, er.client)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to get certificate authority of remote cluster",
+			"remote", remote.Name,
+			"secret", secretName,
+			"namespace", namespace)
+	}
+	if len(secret.Data[key]) == 0 {
+		return nil, kverrors.New("secret is missing the certificate authority of remote cluster",
+			"remote", remote.Name,
+			"secret", secretName,
+			"key", key)
+	}
+	return secret.Data[key], nil
+}
+
+// CreateOrUpdateRemoteTrust ensures the nodes trust the certificate authorities of the remote
+// clusters. The JKS stores the nodes build from their secret only hold the own certificate
+// authority, so the nodes switch to PEM files while remote clusters are configured.
+func (er *ElasticsearchRequest) CreateOrUpdateRemoteTrust() error {
+	cluster := er.cluster
+	name := remoteTrustSecretName(cluster.Name)
+
+	if len(cluster.Spec.RemoteClusters) == 0 {
+		return er.retireRemoteTrust()
+	}
+
+	secret, err := getSecret(cluster.Name, cluster.Namespace, er.client)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to get secret of the cluster", "secret", cluster.Name)
+	}
+
+	kp, err := certificates.ParseKeyPair(secret.Data["elasticsearch.crt"], secret.Data["elasticsearch.key"])
+	if err != nil {
+		return kverrors.Wrap(err, "failed to read transport certificate", "secret", cluster.Name)
+	}
+	key, err := kp.KeyPKCS8PEM()
+	if err != nil {
+		return err
+	}
+
+	// a remote cluster whose certificate authority can't be read yet, e.g. a referenced cluster
+	// that is still being created, doesn't hold back the others
+	bundle := appendPEM(nil, secret.Data[adminCAKey])
+	for _, remote := range cluster.Spec.RemoteClusters {
+		ca, err := er.remoteClusterCA(remote)
+		if err != nil {
+			er.L().Error(err, "Skipping certificate authority of remote cluster", "remote", remote.Name)
+			continue
+		}
+		bundle = appendPEM(bundle, ca)
+	}
+
+	data := map[string][]byte{
+		"elasticsearch.crt": secret.Data["elasticsearch.crt"],
+		"elasticsearch.key": key,
+		remoteTrustCAKey:    bundle,
+	}
+
+	trust := newSecret(name, cluster.Namespace, data)
+	cluster.AddOwnerRefTo(trust)
+
+	err = er.client.Create(context.TODO(), trust)
+	if err == nil || !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to create remote trust secret", "secret", name)
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := getSecret(name, cluster.Namespace, er.client)
+		if err != nil {
+			return err
+		}
+		_, retired := current.Annotations[remoteTrustRetiredAnnotation]
+		if reflect.DeepEqual(current.Data, data) && !retired {
+			return nil
+		}
+
+		current.Data = data
+		delete(current.Annotations, remoteTrustRetiredAnnotation)
+		return er.client.Update(context.TODO(), current)
+	})
+	return kverrors.Wrap(err, "failed to update remote trust secret", "secret", name)
+}
+
+// retireRemoteTrust marks the remote trust secret retired so that the nodes roll out without it
+// and deletes it once no pod of the cluster mounts it anymore. Nodes restarting before their
+// turn still find it.
+func (er *ElasticsearchRequest) retireRemoteTrust() error {
+	cluster := er.cluster
+	name := remoteTrustSecretName(cluster.Name)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := getSecret(name, cluster.Namespace, er.client)
+		if err != nil {
+			return err
+		}
+		if _, retired := current.Annotations[remoteTrustRetiredAnnotation]; retired {
+			return nil
+		}
+
+		if current.Annotations == nil {
+			current.Annotations = map[string]string{}
+		}
+		current.Annotations[remoteTrustRetiredAnnotation] = "true"
+		return er.client.Update(context.TODO(), current)
+	})
+	if apierrors.IsNotFound(kverrors.Root(err)) {
+		return nil
+	}
+	if err != nil {
+		return kverrors.Wrap(err, "failed to retire remote trust secret", "secret", name)
+	}
+
+	pods, err := er.getRemoteTrustPods()
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if mountsRemoteTrust(pod, cluster.Name) {
+			er.L().Info("Keeping remote trust secret until the nodes rolled out", "secret", name, "pod", pod.Name)
+			return nil
+		}
+	}
+
+	secret := newSecret(name, cluster.Namespace, nil)
+	if err := er.client.Delete(context.TODO(), secret); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to delete remote trust secret", "secret", name)
+	}
+	return nil
+}
+
+// isRemoteTrustMounted returns true once every node mounts the remote trust secret. Until then
+// elasticsearch.yml keeps the JKS stores so that nodes restarting before their turn still start.
+func (er *ElasticsearchRequest) isRemoteTrustMounted() (bool, error) {
+	pods, err := er.getRemoteTrustPods()
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if !mountsRemoteTrust(pod, er.cluster.Name) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (er *ElasticsearchRequest) getRemoteTrustPods() (*v1.PodList, error) {
+	selector := map[string]string{
+		"cluster-name": er.cluster.Name,
+		"component":    "elasticsearch",
+	}
+	pods, err := GetPodList(er.cluster.Namespace, selector, er.client)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to list the pods of the cluster", "cluster", er.cluster.Name)
+	}
+	return pods, nil
+}
+
+func mountsRemoteTrust(pod v1.Pod, clusterName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == remoteTrustSecretName(clusterName) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendPEM(bundle, block []byte) []byte {
+	block = bytes.TrimSpace(block)
+	if len(block) == 0 {
+		return bundle
+	}
+	return append(append(bundle, block...), '\n')
+}
+
+// getRemoteTrustHash returns the hash of the remote trust secret or an empty string if the
+// nodes don't trust any remote cluster
+func getRemoteTrustHash(clusterName, namespace string, client client.Client) string {
+	secret, err := getSecret(remoteTrustSecretName(clusterName), namespace, client)
+	if err != nil {
+		return ""
+	}
+	if _, retired := secret.Annotations[remoteTrustRetiredAnnotation]; retired {
+		return ""
+	}
+
+	keys := []string{}
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		h.Write([]byte(key))
+		h.Write(secret.Data[key])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// mountRemoteTrust mounts the remote trust secret into the elasticsearch container
+func mountRemoteTrust(template *v1.PodTemplateSpec, clusterName string) {
+	volumeName := "remote-trust"
+	template.Spec.Volumes = append(template.Spec.Volumes, v1.Volume{
+		Name: volumeName,
+		VolumeSource: v1.VolumeSource{
+			Secret: &v1.SecretVolumeSource{
+				SecretName: remoteTrustSecretName(clusterName),
+			},
+		},
+	})
+
+	for i, container := range template.Spec.Containers {
+		if container.Name != "elasticsearch" {
+			continue
+		}
+		template.Spec.Containers[i].VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      volumeName,
+			MountPath: remoteTrustPath,
+		})
+	}
+}
+
+// reconcileRemoteClusters applies the remote clusters of the spec as cluster settings, removes
+// the ones no longer listed and reports the connections of the nodes to them
+func (er *ElasticsearchRequest) reconcileRemoteClusters() error {
+	cluster := er.cluster
+	if len(cluster.Spec.RemoteClusters) == 0 && len(cluster.Status.RemoteClusters) == 0 {
+		return nil
+	}
+
+	remotes := map[string]*estypes.RemoteClusterSettings{}
+	for _, remote := range cluster.Status.RemoteClusters {
+		remotes[remote.Name] = nil
+	}
+	for _, remote := range cluster.Spec.RemoteClusters {
+		skipUnavailable := remote.SkipUnavailable
+		remotes[remote.Name] = &estypes.RemoteClusterSettings{
+			Seeds:           remoteClusterSeeds(cluster, remote),
+			SkipUnavailable: &skipUnavailable,
+		}
+	}
+
+	if err := er.esClient.SetRemoteClusters(remotes); err != nil {
+		return err
+	}
+
+	info, err := er.esClient.GetRemoteClusterInfo()
+	if err != nil {
+		return err
+	}
+
+	var status []api.ElasticsearchRemoteClusterStatus
+	for _, remote := range cluster.Spec.RemoteClusters {
+		connection := info[remote.Name]
+		status = append(status, api.ElasticsearchRemoteClusterStatus{
+			Name:              remote.Name,
+			Seeds:             remotes[remote.Name].Seeds,
+			Connected:         connection.Connected,
+			NumNodesConnected: connection.NumNodesConnected,
+		})
+	}
+	cluster.Status.RemoteClusters = status
+
+	return nil
+}
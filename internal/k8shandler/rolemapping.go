@@ -0,0 +1,306 @@
+package k8shandler
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/elasticsearch"
+	estypes "github.com/openshift/elasticsearch-operator/internal/types/elasticsearch"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+// roleMappingFinalizer keeps the role mapping around until its roles were removed from the cluster
+const roleMappingFinalizer = "logging.openshift.io/role-mapping"
+
+// roleMappingActionGroups are the index action groups of the security plugin a role may grant
+var roleMappingActionGroups = sets.NewString(
+	"CREATE_INDEX",
+	"CRUD",
+	"DATA_ACCESS",
+	"DELETE",
+	"GET",
+	"INDEX",
+	"INDICES_ALL",
+	"INDICES_MONITOR",
+	"MANAGE",
+	"MANAGE_ALIASES",
+	"READ",
+	"SEARCH",
+	"WRITE",
+)
+
+// roleMappingRoleName is the name of the role in the security config of the cluster. Both names
+// may contain '-', the hash tells e.g. the role b-c of the mapping a from the role c of the
+// mapping a-b. Neither name may contain '/'.
+func roleMappingRoleName(mapping *api.ElasticsearchRoleMapping, role string) string {
+	sum := sha256.Sum256([]byte(mapping.Name + "/" + role))
+	return fmt.Sprintf("%s-%s-%x", mapping.Name, role, sum[:4])
+}
+
+// ReconcileRoleMapping applies the roles of the role mapping to its cluster, removes the roles
+// that were dropped from the spec and records the outcome in the status. The appliedClient
+// talks to the cluster the roles were applied to so far, the roles are removed from it when
+// the role mapping moves to another cluster.
+func ReconcileRoleMapping(mapping *api.ElasticsearchRoleMapping, c client.Client, esClient, appliedClient elasticsearch.Client) error {
+	if mapping.GetDeletionTimestamp() != nil {
+		return removeRoleMapping(mapping, c, appliedClient)
+	}
+
+	if !utils.ContainsString(mapping.GetFinalizers(), roleMappingFinalizer) {
+		mapping.SetFinalizers(append(mapping.GetFinalizers(), roleMappingFinalizer))
+		if err := c.Update(context.TODO(), mapping); err != nil {
+			return kverrors.Wrap(err, "failed to add finalizer to role mapping",
+				"role_mapping", mapping.Name)
+		}
+	}
+
+	applied := mapping.Status.Applied
+	if mapping.AppliedClusterName() != mapping.Spec.ClusterName {
+		if err := deleteAppliedRoles(mapping, c, appliedClient); err != nil {
+			return err
+		}
+		applied = nil
+	}
+
+	status := api.ElasticsearchRoleMappingStatus{
+		ClusterName:        mapping.Spec.ClusterName,
+		Applied:            []string{},
+		Rejected:           []api.ElasticsearchRoleRejection{},
+		ObservedGeneration: mapping.Generation,
+	}
+
+	cluster := &api.Elasticsearch{}
+	key := types.NamespacedName{Name: mapping.Spec.ClusterName, Namespace: mapping.Namespace}
+	if err := c.Get(context.TODO(), key, cluster); err != nil {
+		if !apierrors.IsNotFound(kverrors.Root(err)) {
+			return kverrors.Wrap(err, "failed to get elasticsearch cluster",
+				"cluster", mapping.Spec.ClusterName)
+		}
+		for _, role := range mapping.Spec.Roles {
+			status.Rejected = append(status.Rejected, api.ElasticsearchRoleRejection{
+				Name:   role.Name,
+				Reason: fmt.Sprintf("Elasticsearch cluster %q not found", mapping.Spec.ClusterName),
+			})
+		}
+		// nothing can be applied or removed without the cluster, keep what was applied
+		status.Applied = applied
+		return updateRoleMappingStatus(mapping, status, c)
+	}
+
+	var applyErr error
+	seen := map[string]bool{}
+	for _, role := range mapping.Spec.Roles {
+		if reason := roleRejectionReason(role, seen); reason != "" {
+			status.Rejected = append(status.Rejected, api.ElasticsearchRoleRejection{
+				Name:   role.Name,
+				Reason: reason,
+			})
+			continue
+		}
+
+		name := roleMappingRoleName(mapping, role.Name)
+		if err := applyRole(name, role, mapping.Namespace, esClient); err != nil {
+			applyErr = err
+			status.Rejected = append(status.Rejected, api.ElasticsearchRoleRejection{
+				Name:   role.Name,
+				Reason: "Failed to apply the role to the cluster",
+			})
+			continue
+		}
+		status.Applied = append(status.Applied, name)
+	}
+
+	// remove the roles that are no longer part of the spec, roles that failed to be
+	// removed are still applied
+	for _, name := range applied {
+		if utils.ContainsString(status.Applied, name) {
+			continue
+		}
+		if err := deleteRole(name, esClient); err != nil {
+			applyErr = err
+			status.Applied = append(status.Applied, name)
+		}
+	}
+	sort.Strings(status.Applied)
+
+	if err := updateRoleMappingStatus(mapping, status, c); err != nil {
+		return err
+	}
+	return applyErr
+}
+
+// removeRoleMapping deletes the applied roles from the cluster and releases the role mapping
+func removeRoleMapping(mapping *api.ElasticsearchRoleMapping, c client.Client, esClient elasticsearch.Client) error {
+	if !utils.ContainsString(mapping.GetFinalizers(), roleMappingFinalizer) {
+		return nil
+	}
+
+	if err := deleteAppliedRoles(mapping, c, esClient); err != nil {
+		return err
+	}
+
+	mapping.SetFinalizers(utils.RemoveString(mapping.GetFinalizers(), roleMappingFinalizer))
+	if err := c.Update(context.TODO(), mapping); err != nil {
+		return kverrors.Wrap(err, "failed to remove finalizer from role mapping",
+			"role_mapping", mapping.Name)
+	}
+	return nil
+}
+
+// deleteAppliedRoles deletes the applied roles from the cluster they were applied to
+func deleteAppliedRoles(mapping *api.ElasticsearchRoleMapping, c client.Client, esClient elasticsearch.Client) error {
+	clusterName := mapping.AppliedClusterName()
+	cluster := &api.Elasticsearch{}
+	key := types.NamespacedName{Name: clusterName, Namespace: mapping.Namespace}
+	err := c.Get(context.TODO(), key, cluster)
+	switch {
+	case apierrors.IsNotFound(kverrors.Root(err)):
+		// the roles went away with the cluster
+		return nil
+	case err != nil:
+		return kverrors.Wrap(err, "failed to get elasticsearch cluster",
+			"cluster", clusterName)
+	}
+
+	for _, name := range mapping.Status.Applied {
+		if err := deleteRole(name, esClient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// roleRejectionReason returns why the role can't be rendered into the security config, roles
+// must not grant access to the indices of the stack itself
+func roleRejectionReason(role api.ElasticsearchRole, seen map[string]bool) string {
+	if !proxyAuthRoleName.MatchString(role.Name) {
+		return "Only letters, digits, '_', '.' and '-' are allowed in the name"
+	}
+	if seen[role.Name] {
+		return "The role is defined more than once"
+	}
+	seen[role.Name] = true
+
+	if len(role.Indices) == 0 {
+		return "The role grants no index privileges"
+	}
+	for _, indices := range role.Indices {
+		if len(indices.IndexPatterns) == 0 || len(indices.Privileges) == 0 {
+			return "Index privileges require index patterns and privileges"
+		}
+		for _, pattern := range indices.IndexPatterns {
+			if pattern == "" || strings.HasPrefix(pattern, ".") {
+				return fmt.Sprintf("Index pattern %q is not allowed, hidden indices are managed by the logging stack", pattern)
+			}
+		}
+		for _, privilege := range indices.Privileges {
+			if !roleMappingActionGroups.Has(privilege) && !strings.HasPrefix(privilege, "indices:") {
+				return fmt.Sprintf("Privilege %q is neither an index action group nor an index action", privilege)
+			}
+		}
+	}
+
+	if len(role.Users) == 0 && len(role.BackendRoles) == 0 && len(role.ServiceAccounts) == 0 {
+		return "The role is not granted to any user, backend role or service account"
+	}
+	for _, sa := range role.ServiceAccounts {
+		if sa.Name == "" {
+			return "Service accounts require a name"
+		}
+	}
+
+	return ""
+}
+
+func applyRole(name string, role api.ElasticsearchRole, namespace string, esClient elasticsearch.Client) error {
+	if err := esClient.PutSecurityRole(name, newSecurityRole(role)); err != nil {
+		log.Error(err, "failed to apply role", "role", name)
+		return err
+	}
+	if err := esClient.PutSecurityRoleMapping(name, newSecurityRoleMapping(role, namespace)); err != nil {
+		log.Error(err, "failed to apply role mapping", "role", name)
+		return err
+	}
+	return nil
+}
+
+func deleteRole(name string, esClient elasticsearch.Client) error {
+	if err := esClient.DeleteSecurityRoleMapping(name); err != nil {
+		return err
+	}
+	return esClient.DeleteSecurityRole(name)
+}
+
+// newSecurityRole grants the privileges on all document types of the index patterns
+func newSecurityRole(role api.ElasticsearchRole) *estypes.SecurityRole {
+	indices := map[string]map[string][]string{}
+	for _, permission := range role.Indices {
+		for _, pattern := range permission.IndexPatterns {
+			if _, ok := indices[pattern]; !ok {
+				indices[pattern] = map[string][]string{"*": {}}
+			}
+			privileges := sets.NewString(indices[pattern]["*"]...).Insert(permission.Privileges...)
+			indices[pattern]["*"] = privileges.List()
+		}
+	}
+
+	return &estypes.SecurityRole{
+		Cluster: []string{},
+		Indices: indices,
+	}
+}
+
+// newSecurityRoleMapping maps the users and service accounts by the name the proxy passes
+// along and the backend roles the proxy grants
+func newSecurityRoleMapping(role api.ElasticsearchRole, namespace string) *estypes.SecurityRoleMapping {
+	users := append([]string{}, role.Users...)
+	for _, sa := range role.ServiceAccounts {
+		ns := sa.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		users = append(users, fmt.Sprintf("system:serviceaccount:%s:%s", ns, sa.Name))
+	}
+
+	return &estypes.SecurityRoleMapping{
+		Users:        users,
+		BackendRoles: role.BackendRoles,
+	}
+}
+
+func updateRoleMappingStatus(mapping *api.ElasticsearchRoleMapping, status api.ElasticsearchRoleMappingStatus, c client.Client) error {
+	if reflect.DeepEqual(mapping.Status, status) {
+		return nil
+	}
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &api.ElasticsearchRoleMapping{}
+		key := types.NamespacedName{Name: mapping.Name, Namespace: mapping.Namespace}
+		if err := c.Get(context.TODO(), key, current); err != nil {
+			return err
+		}
+
+		current.Status = status
+		return c.Status().Update(context.TODO(), current)
+	})
+	if retryErr != nil {
+		return kverrors.Wrap(retryErr, "failed to update role mapping status",
+			"role_mapping", mapping.Name)
+	}
+
+	mapping.Status = status
+	return nil
+}
@@ -0,0 +1,246 @@
+package k8shandler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newRoleMapping(roles ...loggingv1.ElasticsearchRole) *loggingv1.ElasticsearchRoleMapping {
+	return &loggingv1.ElasticsearchRoleMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "team-a",
+			Namespace: "openshift-logging",
+		},
+		Spec: loggingv1.ElasticsearchRoleMappingSpec{
+			ClusterName: "elasticsearch",
+			Roles:       roles,
+		},
+	}
+}
+
+func newReaderRole(name string) loggingv1.ElasticsearchRole {
+	return loggingv1.ElasticsearchRole{
+		Name: name,
+		Indices: []loggingv1.ElasticsearchIndexPermission{
+			{IndexPatterns: []string{"app-team-a-*"}, Privileges: []string{"READ"}},
+		},
+		BackendRoles: []string{"team-a"},
+	}
+}
+
+func newSecurityChatter(statusCode int, names ...string) *testhelpers.FakeElasticsearchChatter {
+	responses := map[string]testhelpers.FakeElasticsearchResponses{}
+	for _, name := range names {
+		for _, kind := range []string{"roles", "rolesmapping"} {
+			responses["_opendistro/_security/api/"+kind+"/"+name] = testhelpers.FakeElasticsearchResponses{
+				{StatusCode: statusCode, Body: "{}"},
+			}
+		}
+	}
+	return testhelpers.NewFakeElasticsearchChatter(responses)
+}
+
+func getRoleMapping(t *testing.T, c client.Client) *loggingv1.ElasticsearchRoleMapping {
+	mapping := &loggingv1.ElasticsearchRoleMapping{}
+	key := types.NamespacedName{Name: "team-a", Namespace: "openshift-logging"}
+	if err := c.Get(context.TODO(), key, mapping); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	return mapping
+}
+
+func TestReconcileRoleMapping(t *testing.T) {
+	invalid := newReaderRole("writer")
+	invalid.Indices[0].Privileges = []string{"cluster:admin/settings/update"}
+	hidden := newReaderRole("security")
+	hidden.Indices[0].IndexPatterns = []string{".security"}
+
+	mapping := newRoleMapping(newReaderRole("reader"), invalid, hidden)
+	chatter := newSecurityChatter(http.StatusCreated, "team-a-reader-86a4e07e")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, mapping)
+
+	if err := ReconcileRoleMapping(mapping, er.client, er.esClient, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	mapping = getRoleMapping(t, er.client)
+	if !reflect.DeepEqual(mapping.Finalizers, []string{roleMappingFinalizer}) {
+		t.Errorf("expected the finalizer to be added, got %v", mapping.Finalizers)
+	}
+	if !reflect.DeepEqual(mapping.Status.Applied, []string{"team-a-reader-86a4e07e"}) {
+		t.Errorf("got applied %v, want [team-a-reader-86a4e07e]", mapping.Status.Applied)
+	}
+	if len(mapping.Status.Rejected) != 2 {
+		t.Errorf("expected the writer and security roles to be rejected, got %v", mapping.Status.Rejected)
+	}
+
+	req, _ := chatter.GetRequest("_opendistro/_security/api/rolesmapping/team-a-reader-86a4e07e")
+	if want := testhelpers.NormalizeJSON(`{"backendroles":["team-a"]}`); testhelpers.NormalizeJSON(req.Body) != want {
+		t.Errorf("got role mapping %s, want %s", req.Body, want)
+	}
+}
+
+func TestReconcileRoleMappingRemovesDroppedRoles(t *testing.T) {
+	mapping := newRoleMapping(newReaderRole("reader"))
+	mapping.Finalizers = []string{roleMappingFinalizer}
+	mapping.Status.Applied = []string{"team-a-old", "team-a-reader-86a4e07e"}
+
+	chatter := newSecurityChatter(http.StatusOK, "team-a-reader-86a4e07e", "team-a-old")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, mapping)
+	if err := ReconcileRoleMapping(mapping, er.client, er.esClient, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	for _, uri := range []string{
+		"_opendistro/_security/api/roles/team-a-old",
+		"_opendistro/_security/api/rolesmapping/team-a-old",
+	} {
+		if req, found := chatter.GetRequest(uri); !found || req.Method != http.MethodDelete {
+			t.Errorf("expected %s to be deleted, got %v", uri, req)
+		}
+	}
+
+	mapping = getRoleMapping(t, er.client)
+	if !reflect.DeepEqual(mapping.Status.Applied, []string{"team-a-reader-86a4e07e"}) {
+		t.Errorf("got applied %v, want [team-a-reader-86a4e07e]", mapping.Status.Applied)
+	}
+}
+
+func TestReconcileRoleMappingMovesToAnotherCluster(t *testing.T) {
+	mapping := newRoleMapping(newReaderRole("reader"))
+	mapping.Finalizers = []string{roleMappingFinalizer}
+	mapping.Status.ClusterName = "elasticsearch-old"
+	mapping.Status.Applied = []string{"team-a-reader-86a4e07e"}
+	oldCluster := newTestCluster(loggingv1.ElasticsearchSpec{})
+	oldCluster.Name = "elasticsearch-old"
+	chatter := newSecurityChatter(http.StatusOK, "team-a-reader-86a4e07e")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, mapping, oldCluster)
+
+	oldChatter := newSecurityChatter(http.StatusOK, "team-a-reader-86a4e07e")
+	oldClient := testhelpers.NewFakeElasticsearchClient("elasticsearch-old", "openshift-logging", er.client, oldChatter)
+	if err := ReconcileRoleMapping(mapping, er.client, er.esClient, oldClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if req, found := oldChatter.GetRequest("_opendistro/_security/api/roles/team-a-reader-86a4e07e"); !found || req.Method != http.MethodDelete {
+		t.Errorf("expected the role to be deleted from the old cluster, got %v", req)
+	}
+	if req, found := chatter.GetRequest("_opendistro/_security/api/roles/team-a-reader-86a4e07e"); !found || req.Method != http.MethodPut {
+		t.Errorf("expected the role to be applied to the new cluster, got %v", req)
+	}
+
+	mapping = getRoleMapping(t, er.client)
+	if mapping.Status.ClusterName != "elasticsearch" || !reflect.DeepEqual(mapping.Status.Applied, []string{"team-a-reader-86a4e07e"}) {
+		t.Errorf("expected the role to be applied to cluster elasticsearch, got %v", mapping.Status)
+	}
+}
+
+func TestReconcileRoleMappingKeepsRolesFailingToApply(t *testing.T) {
+	mapping := newRoleMapping(newReaderRole("reader"))
+
+	chatter := newSecurityChatter(http.StatusInternalServerError, "team-a-reader-86a4e07e")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, mapping)
+	if err := ReconcileRoleMapping(mapping, er.client, er.esClient, er.esClient); err == nil {
+		t.Errorf("expected an error to retry applying the role")
+	}
+
+	mapping = getRoleMapping(t, er.client)
+	if len(mapping.Status.Applied) != 0 || len(mapping.Status.Rejected) != 1 {
+		t.Errorf("expected the role to be rejected, got %v", mapping.Status)
+	}
+}
+
+func TestReconcileRoleMappingDeleted(t *testing.T) {
+	now := metav1.Now()
+	mapping := newRoleMapping(newReaderRole("reader"))
+	mapping.Finalizers = []string{roleMappingFinalizer}
+	mapping.DeletionTimestamp = &now
+	mapping.Status.Applied = []string{"team-a-reader-86a4e07e"}
+
+	chatter := newSecurityChatter(http.StatusOK, "team-a-reader-86a4e07e")
+	er := newTestRequest(newTestCluster(loggingv1.ElasticsearchSpec{}), chatter, mapping)
+	if err := ReconcileRoleMapping(mapping, er.client, er.esClient, er.esClient); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if req, found := chatter.GetRequest("_opendistro/_security/api/roles/team-a-reader-86a4e07e"); !found || req.Method != http.MethodDelete {
+		t.Errorf("expected the role to be deleted, got %v", req)
+	}
+	if mapping = getRoleMapping(t, er.client); len(mapping.Finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", mapping.Finalizers)
+	}
+}
+
+func TestRoleRejectionReason(t *testing.T) {
+	tests := []struct {
+		desc   string
+		modify func(*loggingv1.ElasticsearchRole)
+		valid  bool
+	}{
+		{desc: "valid", modify: func(r *loggingv1.ElasticsearchRole) {}, valid: true},
+		{desc: "index action", modify: func(r *loggingv1.ElasticsearchRole) {
+			r.Indices[0].Privileges = []string{"indices:data/read/search"}
+		}, valid: true},
+		{desc: "invalid name", modify: func(r *loggingv1.ElasticsearchRole) { r.Name = "a/b" }},
+		{desc: "no indices", modify: func(r *loggingv1.ElasticsearchRole) { r.Indices = nil }},
+		{desc: "hidden index", modify: func(r *loggingv1.ElasticsearchRole) {
+			r.Indices[0].IndexPatterns = []string{".kibana"}
+		}},
+		{desc: "cluster action", modify: func(r *loggingv1.ElasticsearchRole) {
+			r.Indices[0].Privileges = []string{"cluster:monitor/health"}
+		}},
+		{desc: "no subjects", modify: func(r *loggingv1.ElasticsearchRole) { r.BackendRoles = nil }},
+		{desc: "unnamed service account", modify: func(r *loggingv1.ElasticsearchRole) {
+			r.ServiceAccounts = []loggingv1.ElasticsearchServiceAccountSubject{{Namespace: "team-a"}}
+		}},
+	}
+
+	for _, test := range tests {
+		role := newReaderRole("reader")
+		test.modify(&role)
+
+		reason := roleRejectionReason(role, map[string]bool{})
+		if (reason == "") != test.valid {
+			t.Errorf("%s: got reason %q, want valid %t", test.desc, reason, test.valid)
+		}
+	}
+}
+
+func TestNewSecurityRoleMapping(t *testing.T) {
+	role := newReaderRole("reader")
+	role.Users = []string{"alice"}
+	role.ServiceAccounts = []loggingv1.ElasticsearchServiceAccountSubject{
+		{Name: "collector"},
+		{Namespace: "team-a", Name: "exporter"},
+	}
+
+	mapping := newSecurityRoleMapping(role, "openshift-logging")
+	want := []string{
+		"alice",
+		"system:serviceaccount:openshift-logging:collector",
+		"system:serviceaccount:team-a:exporter",
+	}
+	if !reflect.DeepEqual(mapping.Users, want) {
+		t.Errorf("got users %v, want %v", mapping.Users, want)
+	}
+	if !reflect.DeepEqual(mapping.BackendRoles, []string{"team-a"}) {
+		t.Errorf("got backend roles %v, want [team-a]", mapping.BackendRoles)
+	}
+}
+
+func TestRoleMappingRoleNameIsUnique(t *testing.T) {
+	first := roleMappingRoleName(&loggingv1.ElasticsearchRoleMapping{ObjectMeta: metav1.ObjectMeta{Name: "a-b"}}, "c")
+	second := roleMappingRoleName(&loggingv1.ElasticsearchRoleMapping{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, "b-c")
+	if first == second {
+		t.Errorf("expected different names for the role c of a-b and the role b-c of a, got %s", first)
+	}
+}
@@ -0,0 +1,277 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	apps "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+const surgeNodeSuffix = "surge"
+
+// progressNodeChanges pushes the desired pod template of a node out to its pods.
+// Pods are released one at a time starting with the highest ordinal and every pod
+// has to rejoin the cluster before the next one is released. Deployment and StatefulSet
+// backed nodes only differ in how they hold back and release their pods.
+//
+// The function never blocks on the cluster. While a released pod has not rolled out or
+// rejoined yet it returns ErrWaitingForCluster and continues with the same pod on the next
+// reconcile, the progress is kept on the k8s resource itself (partition or paused state).
+func progressNodeChanges(node NodeTypeInterface) error {
+	changed := node.isChanged()
+	if !changed && node.isRolledOut() {
+		return nil
+	}
+
+	replicas, err := node.replicaCount()
+	if err != nil {
+		return kverrors.Wrap(err, "unable to get number of replicas prior to restart for node",
+			"node", node.name(),
+		)
+	}
+
+	if changed {
+		if err := node.holdRollout(replicas); err != nil {
+			return kverrors.Wrap(err, "unable to hold rollout for node",
+				"node", node.name(),
+			)
+		}
+
+		if err := node.executeUpdate(); err != nil {
+			return err
+		}
+	}
+
+	ordinal, err := node.releasedOrdinal(replicas)
+	if err != nil {
+		return kverrors.Wrap(err, "unable to get rollout progress for node",
+			"node", node.name(),
+		)
+	}
+
+	for {
+		// make sure the released pod is back in the cluster before we take down the
+		// next one. Nothing was released yet for the first pod so that crashlooping or
+		// unschedulable nodes can still be fixed.
+		if ordinal < replicas {
+			if err := node.waitForPodRollout(); err != nil {
+				return err
+			}
+
+			if _, err := node.waitForNodeRejoinCluster(); err != nil {
+				return err
+			}
+		}
+
+		if ordinal <= 0 {
+			break
+		}
+		ordinal--
+
+		if err := node.releaseRollout(ordinal); err != nil {
+			return kverrors.Wrap(err, "unable to release rollout for node",
+				"node", node.name(),
+				"ordinal", ordinal,
+			)
+		}
+	}
+
+	if err := node.completeRollout(); err != nil {
+		return kverrors.Wrap(err, "unable to complete rollout for node",
+			"node", node.name(),
+		)
+	}
+
+	node.refreshHashes()
+	return nil
+}
+
+func (er *ElasticsearchRequest) isSurgeEnabled() bool {
+	return er.cluster.Spec.RolloutStrategy != nil && er.cluster.Spec.RolloutStrategy.Surge
+}
+
+func surgeNodeName(clusterName string) string {
+	return fmt.Sprintf("%s-%s", clusterName, surgeNodeSuffix)
+}
+
+// newSurgeNode builds the temporary data only node that takes over the shards of
+// the data node being restarted. It is modeled after the first data node group.
+func (er *ElasticsearchRequest) newSurgeNode() NodeTypeInterface {
+	roleMap := map[api.ElasticsearchNodeRole]bool{
+		api.ElasticsearchRoleData: true,
+	}
+
+	for _, node := range er.cluster.Spec.Nodes {
+		if isDataNode(node) {
+			// the surge node starts empty, it is not restored from the data source of the group
+			node.Storage.DataSource = nil
+			return newDeploymentNode(surgeNodeName(er.cluster.Name), node, er.cluster, roleMap, er.client, er.esClient)
+		}
+	}
+
+	return nil
+}
+
+// ensureSurgeNode creates the surge node if it is missing and returns whether it joined the cluster
+func (er *ElasticsearchRequest) ensureSurgeNode() (bool, error) {
+	surge := er.newSurgeNode()
+	if surge == nil {
+		return false, kverrors.New("no data node found to model the surge node after",
+			"cluster", er.cluster.Name)
+	}
+
+	if surge.isMissing() {
+		er.L().Info("Creating surge node", "node", surge.name())
+		if err := surge.create(); err != nil {
+			return false, err
+		}
+	}
+
+	return er.esClient.IsNodeInCluster(surge.name())
+}
+
+// relocateShardsFunc returns the preparation step used when surge is enabled. Instead of
+// setting the shard allocation to primaries it brings up the surge node and excludes the
+// data node being restarted from allocation until all of its shards have been relocated.
+// Note that this replaces any node exclusion that was set on the cluster beforehand.
+func (er *ElasticsearchRequest) relocateShardsFunc(clusterRestart ClusterRestart, node NodeTypeInterface) func() error {
+	return func() error {
+		joined, err := er.ensureSurgeNode()
+		if err != nil {
+			return err
+		}
+		if !joined {
+			return kverrors.Wrap(ErrWaitingForCluster, "waiting for surge node to join the cluster",
+				"node", surgeNodeName(er.cluster.Name))
+		}
+
+		return clusterRestart.relocateShards(node)
+	}
+}
+
+// relocateShards excludes the node being restarted from allocation until all of its shards
+// have been relocated onto the other nodes. The other scheduled nodes keep their shards until
+// it is their turn.
+func (cr ClusterRestart) relocateShards(node NodeTypeInterface) error {
+	if !node.holdsData() {
+		return nil
+	}
+
+	if err := cr.setAllocationExclusion([]string{node.name()}); err != nil {
+		return err
+	}
+
+	count, err := cr.client.GetNodeShardCount(node.name())
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return kverrors.Wrap(ErrWaitingForCluster, "waiting for shards to relocate off node",
+			"node", node.name(),
+			"shards", count)
+	}
+
+	return nil
+}
+
+func (cr ClusterRestart) setAllocationExclusion(nodeNames []string) error {
+	if ok, err := cr.client.SetShardAllocationExclusion(nodeNames); !ok {
+		if err == nil {
+			err = kverrors.New("request was not acknowledged")
+		}
+		return kverrors.Wrap(err, "unable to set shard allocation exclusion",
+			"namespace", cr.clusterNamespace,
+			"cluster", cr.clusterName,
+			"nodes", nodeNames)
+	}
+
+	return nil
+}
+
+func (cr ClusterRestart) waitAllNodesRejoinAndClearExclusion() error {
+	if err := cr.waitAllNodesRejoin(); err != nil {
+		return err
+	}
+
+	if err := cr.setAllocationExclusion(nil); err != nil {
+		return err
+	}
+
+	return cr.setAllShards()
+}
+
+// retireSurgeNode relocates the shards off the surge node and removes it together with
+// its storage once no node is being updated anymore. Each step is picked up again on the
+// next reconcile, the surge deployment is kept around until the node left the cluster.
+func (er *ElasticsearchRequest) retireSurgeNode() error {
+	name := surgeNodeName(er.cluster.Name)
+	key := types.NamespacedName{Name: name, Namespace: er.cluster.Namespace}
+
+	surge := &apps.Deployment{}
+	if err := er.client.Get(context.TODO(), key, surge); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	r := ClusterRestart{
+		client:           er.esClient,
+		clusterName:      er.cluster.Name,
+		clusterNamespace: er.cluster.Namespace,
+	}
+
+	inCluster, err := er.esClient.IsNodeInCluster(name)
+	if err != nil {
+		return err
+	}
+
+	if inCluster {
+		if surge.Spec.Replicas != nil && *surge.Spec.Replicas == 0 {
+			er.L().Info("Waiting for surge node to leave the cluster", "node", name)
+			return nil
+		}
+
+		if err := r.setAllocationExclusion([]string{name}); err != nil {
+			return err
+		}
+
+		count, err := er.esClient.GetNodeShardCount(name)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			er.L().Info("Waiting for shards to relocate off surge node", "node", name, "shards", count)
+			return nil
+		}
+
+		er.L().Info("Stopping surge node", "node", name)
+		replicas := int32(0)
+		surge.Spec.Replicas = &replicas
+		if err := er.client.Update(context.TODO(), surge); err != nil {
+			return kverrors.Wrap(err, "failed to scale down surge node", "node", name)
+		}
+		return nil
+	}
+
+	if err := r.setAllocationExclusion(nil); err != nil {
+		return err
+	}
+
+	er.L().Info("Removing surge node", "node", name)
+	if err := er.client.Delete(context.TODO(), surge); err != nil && !apierrors.IsNotFound(err) {
+		return kverrors.Wrap(err, "failed to delete surge node", "node", name)
+	}
+
+	claim := persistentVolumeClaim(fmt.Sprintf("%s-%s", er.cluster.Name, name), er.cluster.Namespace, er.cluster.Name)
+	if err := er.client.Delete(context.TODO(), claim); err != nil && !apierrors.IsNotFound(err) {
+		return kverrors.Wrap(err, "failed to delete surge node storage", "claim", claim.Name)
+	}
+
+	return nil
+}
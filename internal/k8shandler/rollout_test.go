@@ -0,0 +1,176 @@
+package k8shandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+// relocatingNode is a data node whose shards are relocated before it restarts
+type relocatingNode struct {
+	NodeTypeInterface
+	nodeName string
+}
+
+func (n *relocatingNode) name() string    { return n.nodeName }
+func (n *relocatingNode) holdsData() bool { return true }
+
+// rolloutNode records the rollout primitives called by progressNodeChanges
+type rolloutNode struct {
+	NodeTypeInterface
+
+	changed   bool
+	rolledOut bool
+	replicas  int32
+	partition int32
+	waiting   int
+	calls     []string
+}
+
+func (n *rolloutNode) name() string      { return "rollout-node" }
+func (n *rolloutNode) isChanged() bool   { return n.changed }
+func (n *rolloutNode) isRolledOut() bool { return n.rolledOut }
+func (n *rolloutNode) refreshHashes()    { n.calls = append(n.calls, "refresh") }
+
+func (n *rolloutNode) replicaCount() (int32, error) {
+	return n.replicas, nil
+}
+
+func (n *rolloutNode) holdRollout(replicas int32) error {
+	n.partition = replicas
+	n.calls = append(n.calls, fmt.Sprintf("hold %d", replicas))
+	return nil
+}
+
+func (n *rolloutNode) releasedOrdinal(replicas int32) (int32, error) {
+	return n.partition, nil
+}
+
+func (n *rolloutNode) executeUpdate() error {
+	n.calls = append(n.calls, "update")
+	return nil
+}
+
+func (n *rolloutNode) releaseRollout(ordinal int32) error {
+	n.partition = ordinal
+	n.calls = append(n.calls, fmt.Sprintf("release %d", ordinal))
+	return nil
+}
+
+func (n *rolloutNode) waitForPodRollout() error {
+	n.calls = append(n.calls, "rollout")
+	return nil
+}
+
+func (n *rolloutNode) waitForNodeRejoinCluster() (bool, error) {
+	if n.waiting > 0 {
+		n.waiting--
+		return false, ErrWaitingForCluster
+	}
+	n.calls = append(n.calls, "rejoin")
+	return true, nil
+}
+
+func (n *rolloutNode) completeRollout() error {
+	n.calls = append(n.calls, "complete")
+	return nil
+}
+
+var _ = Describe("rollout", func() {
+	defer GinkgoRecover()
+
+	Context("progressNodeChanges", func() {
+		It("should do nothing when the node is unchanged and rolled out", func() {
+			node := &rolloutNode{rolledOut: true, replicas: 3}
+
+			Expect(progressNodeChanges(node)).To(Succeed())
+			Expect(node.calls).To(BeEmpty())
+		})
+
+		It("should release pods one at a time waiting for each to rejoin", func() {
+			node := &rolloutNode{changed: true, replicas: 3}
+
+			Expect(progressNodeChanges(node)).To(Succeed())
+			Expect(node.calls).To(Equal([]string{
+				"hold 3",
+				"update",
+				"release 2",
+				"rollout",
+				"rejoin",
+				"release 1",
+				"rollout",
+				"rejoin",
+				"release 0",
+				"rollout",
+				"rejoin",
+				"complete",
+				"refresh",
+			}))
+		})
+
+		It("should continue with the released pod after waiting for the cluster", func() {
+			node := &rolloutNode{changed: true, replicas: 2, waiting: 1}
+
+			err := progressNodeChanges(node)
+			Expect(errors.Is(err, ErrWaitingForCluster)).To(BeTrue())
+			Expect(node.calls).To(Equal([]string{
+				"hold 2",
+				"update",
+				"release 1",
+				"rollout",
+			}))
+
+			node.changed = false
+			node.calls = nil
+
+			Expect(progressNodeChanges(node)).To(Succeed())
+			Expect(node.calls).To(Equal([]string{
+				"rollout",
+				"rejoin",
+				"release 0",
+				"rollout",
+				"rejoin",
+				"complete",
+				"refresh",
+			}))
+		})
+
+		It("should finish a rollout that was interrupted", func() {
+			node := &rolloutNode{replicas: 1, partition: 0}
+
+			Expect(progressNodeChanges(node)).To(Succeed())
+			Expect(node.calls).To(Equal([]string{
+				"rollout",
+				"rejoin",
+				"complete",
+				"refresh",
+			}))
+		})
+	})
+
+	Context("relocateShards", func() {
+		It("should only exclude the node being restarted", func() {
+			chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+				"_cluster/settings":              {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+				"_cat/shards?h=node&format=json": {{StatusCode: http.StatusOK, Body: `[{"node": "elasticsearch-cdm-2"}]`}},
+			})
+			first := &relocatingNode{nodeName: "elasticsearch-cdm-1"}
+			r := ClusterRestart{
+				client:         testhelpers.NewFakeElasticsearchClient("elasticsearch", "openshift-logging", fake.NewFakeClient(), chatter),
+				scheduledNodes: []NodeTypeInterface{first, &relocatingNode{nodeName: "elasticsearch-cdm-2"}},
+			}
+
+			Expect(r.relocateShards(first)).To(Succeed())
+
+			req, found := chatter.GetRequest("_cluster/settings")
+			Expect(found).To(BeTrue())
+			Expect(req.Body).To(MatchJSON(`{"persistent": {"cluster.routing.allocation.exclude._name": "elasticsearch-cdm-1"}}`))
+		})
+	})
+})
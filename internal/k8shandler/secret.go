@@ -21,20 +21,24 @@ func getSecret(secretName, namespace string, client client.Client) (*v1.Secret,
 	return &secret, err
 }
 
+// httpCertKeys are the keys of the certificate serving the REST API of the cluster. The nodes
+// don't have to trust each other's HTTP certificates, so changes to them are rolled out one node
+// at a time through the pod template instead of restarting the whole cluster.
+var httpCertKeys = []string{"logging-es.crt", "logging-es.key"}
+
+// httpCertHashAnnotation is the pod template annotation that rolls out changes to the HTTP certificates
+const httpCertHashAnnotation = "elasticsearch.openshift.io/http-cert-hash"
+
+// getSecretDataHash returns the hash of the secret data that requires a full cluster restart
+// when changed, this is all of it except the HTTP certificates
 func getSecretDataHash(secretName, namespace string, client client.Client) string {
 	hash := ""
 
-	secret, err := getSecret(secretName, namespace, client)
-	if err != nil {
+	dataHashes := getSecretKeyHashes(secretName, namespace, client)
+	if dataHashes == nil {
 		return hash
 	}
 
-	dataHashes := make(map[string][32]byte)
-
-	for key, data := range secret.Data {
-		dataHashes[key] = sha256.Sum256([]byte(data))
-	}
-
 	sortedKeys := sortDataHashKeys(dataHashes)
 
 	for _, key := range sortedKeys {
@@ -44,6 +48,26 @@ func getSecretDataHash(secretName, namespace string, client client.Client) strin
 	return hash
 }
 
+// getSecretKeyHashes returns the hash of every key of the secret that is part of
+// getSecretDataHash or nil if the secret can't be read
+func getSecretKeyHashes(secretName, namespace string, client client.Client) map[string][32]byte {
+	secret, err := getSecret(secretName, namespace, client)
+	if err != nil {
+		return nil
+	}
+
+	dataHashes := make(map[string][32]byte)
+
+	for key, data := range secret.Data {
+		if sliceContainsString(httpCertKeys, key) {
+			continue
+		}
+		dataHashes[key] = sha256.Sum256([]byte(data))
+	}
+
+	return dataHashes
+}
+
 // hasRequiredSecrets will check that all secrets that we expect for EO to be able to communicate
 // with the ES cluster it manages exist.
 // It will return true if all required secrets/keys exist.
@@ -85,3 +109,27 @@ func (er ElasticsearchRequest) hasRequiredSecrets() (bool, string) {
 
 	return hasRequired, message
 }
+
+// getHTTPCertHash returns the hash of the HTTP certificates in the secret or an empty
+// string if the secret doesn't contain them
+func getHTTPCertHash(secretName, namespace string, client client.Client) string {
+	secret, err := getSecret(secretName, namespace, client)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	found := false
+	for _, key := range httpCertKeys {
+		if data, ok := secret.Data[key]; ok {
+			found = true
+			h.Write([]byte(key))
+			h.Write(data)
+		}
+	}
+
+	if !found {
+		return ""
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
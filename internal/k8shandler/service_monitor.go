@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ViaQ/logerr/kverrors"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
@@ -17,6 +18,10 @@ const (
 
 // CreateOrUpdateServiceMonitors ensures the existence of ServiceMonitors for Elasticsearch cluster
 func (er *ElasticsearchRequest) CreateOrUpdateServiceMonitors() error {
+	if !er.capabilities.Has(platform.Monitoring) {
+		return nil
+	}
+
 	dpl := er.cluster
 	serviceMonitorName := fmt.Sprintf("monitor-%s-%s", dpl.Name, "cluster")
 
@@ -2,7 +2,6 @@ package k8shandler
 
 import (
 	"context"
-	"time"
 
 	"github.com/ViaQ/logerr/kverrors"
 	"github.com/go-logr/logr"
@@ -12,7 +11,6 @@ import (
 	"github.com/ViaQ/logerr/log"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -73,7 +71,7 @@ func (n *statefulSetNode) populateReference(nodeName string, node api.Elasticsea
 		Selector: &metav1.LabelSelector{
 			MatchLabels: newLabelSelector(cluster.Name, nodeName, roleMap),
 		},
-		Template: newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, node, cluster.Spec.Spec, labels, roleMap, client, logConfig),
+		Template: newPodTemplateSpec(nodeName, cluster.Name, cluster.Namespace, node, cluster.Spec.Spec, labels, roleMap, client, logConfig, cluster.Spec.Proxy),
 		UpdateStrategy: apps.StatefulSetUpdateStrategy{
 			Type: apps.RollingUpdateStatefulSetStrategyType,
 			RollingUpdate: &apps.RollingUpdateStatefulSetStrategy{
@@ -82,6 +80,7 @@ func (n *statefulSetNode) populateReference(nodeName string, node api.Elasticsea
 		},
 	}
 	statefulSet.Spec.Template.Spec.Containers[0].ReadinessProbe = nil
+	useDataOfCluster(&statefulSet.Spec.Template, cluster, nodeName)
 
 	cluster.AddOwnerRefTo(&statefulSet)
 
@@ -144,32 +143,40 @@ func (n *statefulSetNode) name() string {
 	return n.self.Name
 }
 
+// waitForNodeRejoinCluster does not block but returns ErrWaitingForCluster
+// while the pods of the node have not rejoined the cluster yet
 func (n *statefulSetNode) waitForNodeRejoinCluster() (bool, error) {
-	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
-		clusterSize, err := n.esClient.GetClusterNodeCount()
-		if err != nil {
-			n.L().Error(err, "Unable to get cluster size waiting to rejoin cluster")
-			return false, err
-		}
+	clusterSize, err := n.esClient.GetClusterNodeCount()
+	if err != nil {
+		n.L().Error(err, "Unable to get cluster size waiting to rejoin cluster")
+		return false, err
+	}
 
-		return n.replicas <= clusterSize, nil
-	})
+	if n.replicas > clusterSize {
+		return false, kverrors.Wrap(ErrWaitingForCluster, "waiting for node to rejoin the cluster",
+			"node", n.name(),
+		)
+	}
 
-	return err == nil, err
+	return true, nil
 }
 
+// waitForNodeLeaveCluster does not block but returns ErrWaitingForCluster
+// while the pods of the node are still part of the cluster
 func (n *statefulSetNode) waitForNodeLeaveCluster() (bool, error) {
-	err := wait.Poll(time.Second*1, time.Second*60, func() (done bool, err error) {
-		clusterSize, err := n.esClient.GetClusterNodeCount()
-		if err != nil {
-			n.L().Error(err, "Unable to get cluster size waiting to leave cluster")
-			return false, err
-		}
+	clusterSize, err := n.esClient.GetClusterNodeCount()
+	if err != nil {
+		n.L().Error(err, "Unable to get cluster size waiting to leave cluster")
+		return false, err
+	}
 
-		return n.replicas > clusterSize, nil
-	})
+	if n.replicas <= clusterSize {
+		return false, kverrors.Wrap(ErrWaitingForCluster, "waiting for node to leave the cluster",
+			"node", n.name(),
+		)
+	}
 
-	return err == nil, err
+	return true, nil
 }
 
 func (n *statefulSetNode) setPartition(partitions int32) error {
@@ -361,6 +368,33 @@ func (n *statefulSetNode) scale() {
 	}
 }
 
+func (n *statefulSetNode) isProxyOnlyChange() bool {
+	current := apps.StatefulSet{}
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Name: n.self.Name, Namespace: n.self.Namespace}, &current); err != nil {
+		return false
+	}
+
+	return isProxyOnlyChange(current.Spec.Template, n.self.Spec.Template)
+}
+
+func (n *statefulSetNode) storageChange() (string, string) {
+	current := apps.StatefulSet{}
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Name: n.self.Name, Namespace: n.self.Namespace}, &current); err != nil {
+		return "", ""
+	}
+
+	return podTemplateStorage(current.Spec.Template), podTemplateStorage(n.self.Spec.Template)
+}
+
+func (n *statefulSetNode) imageChange() (string, string) {
+	current := apps.StatefulSet{}
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Name: n.self.Name, Namespace: n.self.Namespace}, &current); err != nil {
+		return "", ""
+	}
+
+	return podTemplateImage(current.Spec.Template), podTemplateImage(n.self.Spec.Template)
+}
+
 func (n *statefulSetNode) isChanged() bool {
 	desiredTemplate := n.self.Spec.Template
 	currentStatefulSet := apps.StatefulSet{}
@@ -375,62 +409,67 @@ func (n *statefulSetNode) isChanged() bool {
 	return ArePodTemplateSpecDifferent(currentStatefulSet.Spec.Template, desiredTemplate)
 }
 
-func (n *statefulSetNode) progressNodeChanges() error {
-	if !n.isChanged() {
-		return nil
-	}
-	replicas, err := n.replicaCount()
-	if err != nil {
-		return kverrors.Wrap(err, "Unable to get number of replicas prior to restart for node",
-			"node", n.name(),
-		)
-	}
+func (n *statefulSetNode) isRolledOut() bool {
+	current := &apps.StatefulSet{}
 
-	if err := n.setPartition(replicas); err != nil {
-		n.L().Error(err, "unable to set partition")
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Name: n.self.Name, Namespace: n.self.Namespace}, current); err != nil {
+		return false
 	}
 
-	if err := n.executeUpdate(); err != nil {
-		return err
-	}
+	// the status is only meaningful once the controller observed the latest template
+	return current.Status.ObservedGeneration >= current.Generation &&
+		current.Status.UpdatedReplicas >= current.Status.Replicas
+}
+
+// statefulset nodes are never data nodes so there are no shards to relocate
+func (n *statefulSetNode) holdsData() bool {
+	return false
+}
 
-	ordinal, err := n.partition()
+// holdRollout sets the partition to the number of replicas so that no pod picks up a new template
+func (n *statefulSetNode) holdRollout(replicas int32) error {
+	return n.setPartition(replicas)
+}
+
+// releaseRollout lowers the partition so that the pod with the given ordinal is recreated
+func (n *statefulSetNode) releaseRollout(ordinal int32) error {
+	return n.setPartition(ordinal)
+}
+
+// releasedOrdinal is the current partition, every pod with an ordinal at or above it was released
+func (n *statefulSetNode) releasedOrdinal(replicas int32) (int32, error) {
+	partition, err := n.partition()
 	if err != nil {
-		return kverrors.Wrap(err, "unable to get node ordinal value")
+		return -1, err
 	}
 
-	// start partition at replicas and incrementally update it to 0
-	// making sure nodes rejoin between each one
-	for index := ordinal; index > 0; index-- {
+	if partition > replicas {
+		return replicas, nil
+	}
 
-		// make sure we have all nodes in the cluster first -- always
-		if _, err := n.waitForNodeRejoinCluster(); err != nil {
-			return kverrors.Wrap(err, "timed out waiting for node to rejoin cluster",
-				"node", n.name(),
-			)
-		}
+	return partition, nil
+}
 
-		// update partition to cause next pod to be updated
-		if err := n.setPartition(index - 1); err != nil {
-			n.L().Info("unable to set partition", "error", err)
-		}
+// waitForPodRollout does not block but returns ErrWaitingForCluster until
+// every released pod was recreated with the new template
+func (n *statefulSetNode) waitForPodRollout() error {
+	current := &apps.StatefulSet{}
 
-		// wait for the node to leave the cluster
-		if _, err := n.waitForNodeLeaveCluster(); err != nil {
-			return kverrors.Wrap(err, "timed out waiting for node to leave the cluster",
-				"node", n.name(),
-			)
-		}
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Name: n.self.Name, Namespace: n.self.Namespace}, current); err != nil {
+		return err
 	}
 
-	// this is here again because we need to make sure all nodes have rejoined
-	// before we move on and say we're done
-	if _, err := n.waitForNodeRejoinCluster(); err != nil {
-		return kverrors.Wrap(err, "timed out waiting for node to rejoin cluster",
+	released := *current.Spec.Replicas - *current.Spec.UpdateStrategy.RollingUpdate.Partition
+	if current.Status.ObservedGeneration < current.Generation || current.Status.UpdatedReplicas < released {
+		return kverrors.Wrap(ErrWaitingForCluster, "waiting for node to rollout",
 			"node", n.name(),
 		)
 	}
 
-	n.refreshHashes()
+	return nil
+}
+
+// the partition is left at 0 once every pod was released, nothing more to do
+func (n *statefulSetNode) completeRollout() error {
 	return nil
 }
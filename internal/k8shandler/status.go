@@ -64,6 +64,8 @@ func (er *ElasticsearchRequest) UpdateClusterStatus() error {
 	}
 
 	clusterStatus.Pods = rolePodStateMap(cluster.Namespace, cluster.Name, er.client)
+	er.updateCertificateStatus(clusterStatus)
+	er.updateOrphanedClaimStatus(clusterStatus)
 	updateStatusConditions(clusterStatus)
 	if err := er.updateNodeConditions(clusterStatus); err != nil {
 		return err
@@ -82,6 +84,14 @@ func (er *ElasticsearchRequest) UpdateClusterStatus() error {
 			cluster.Status.Pods = clusterStatus.Pods
 			cluster.Status.ShardAllocationEnabled = clusterStatus.ShardAllocationEnabled
 			cluster.Status.Nodes = clusterStatus.Nodes
+			cluster.Status.Certificates = clusterStatus.Certificates
+			cluster.Status.CertificateRedeploy = clusterStatus.CertificateRedeploy
+			cluster.Status.OrphanedClaims = clusterStatus.OrphanedClaims
+			cluster.Status.VolumeSnapshots = clusterStatus.VolumeSnapshots
+			cluster.Status.StorageMigrations = clusterStatus.StorageMigrations
+			cluster.Status.RemoteClusters = clusterStatus.RemoteClusters
+			cluster.Status.Migrations = clusterStatus.Migrations
+			cluster.Status.Upgrade = clusterStatus.Upgrade
 
 			if err := er.client.Status().Update(context.TODO(), cluster); err != nil {
 				return err
@@ -281,7 +291,7 @@ func (er *ElasticsearchRequest) pruneMissingNodes(status *api.ElasticsearchStatu
 	cluster := er.cluster
 	ll := er.L()
 
-	clusterNodes := nodes[nodeMapKey(er.cluster.GetName(), er.cluster.GetNamespace())]
+	clusterNodes := er.getNodes()
 
 	ns := status.Nodes[:0]
 	for _, nodeStatus := range status.Nodes {
@@ -835,6 +845,148 @@ func updateInvalidUUIDChangeCondition(cluster *api.Elasticsearch, value v1.Condi
 	)
 }
 
+func updateInvalidProxyAuthRolesCondition(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error {
+	var reason string
+	if value == v1.ConditionTrue {
+		reason = "Invalid Spec"
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.InvalidProxyAuthRoles,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
+// updateIntegrationsDisabledCondition lists the integrations skipped because the platform
+// doesn't serve their APIs, e.g. the console and routes on Kubernetes
+func updateIntegrationsDisabledCondition(cluster *api.Elasticsearch, disabled []string, client client.Client) error {
+	value := v1.ConditionFalse
+	var reason, message string
+	if len(disabled) > 0 {
+		value = v1.ConditionTrue
+		reason = "APIs Unavailable"
+		message = fmt.Sprintf("Integrations disabled as their APIs are not served: %s", strings.Join(disabled, ", "))
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.IntegrationsDisabled,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
+// updateStorageResizeRejectedCondition lists the claims whose storage can't be changed
+// as the spec requests, e.g. shrinking them
+func updateStorageResizeRejectedCondition(cluster *api.Elasticsearch, rejected []string, client client.Client) error {
+	value := v1.ConditionFalse
+	var reason, message string
+	if len(rejected) > 0 {
+		value = v1.ConditionTrue
+		reason = "Resize Not Supported"
+		message = strings.Join(rejected, "; ")
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.StorageResizeRejected,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
+// updateStorageChangeRequiresConfirmationCondition lists the nodes whose data is lost by
+// switching between ephemeral and persistent storage
+func updateStorageChangeRequiresConfirmationCondition(cluster *api.Elasticsearch, held []string, client client.Client) error {
+	value := v1.ConditionFalse
+	var reason, message string
+	if len(held) > 0 {
+		value = v1.ConditionTrue
+		reason = "Data Loss"
+		message = fmt.Sprintf("%s and loses its data, annotate the cluster with %s=%d to confirm",
+			strings.Join(held, "; "), StorageChangeAckAnnotation, cluster.GetGeneration())
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.StorageChangeRequiresConfirmation,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
+func updateInvalidAuditLogCondition(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error {
+	var reason string
+	if value == v1.ConditionTrue {
+		reason = "Invalid Spec"
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.InvalidAuditLog,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
+func updateInvalidStorageCondition(cluster *api.Elasticsearch, value v1.ConditionStatus, message string, client client.Client) error {
+	var reason string
+	if value == v1.ConditionTrue {
+		reason = "Invalid Spec"
+	}
+
+	return updateConditionWithRetry(
+		cluster,
+		value,
+		func(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
+			return updateESNodeCondition(status, &api.ClusterCondition{
+				Type:    api.InvalidStorage,
+				Status:  value,
+				Reason:  reason,
+				Message: message,
+			})
+		},
+		client,
+	)
+}
+
 func updateInvalidReplicationCondition(status *api.ElasticsearchStatus, value v1.ConditionStatus) bool {
 	var message string
 	var reason string
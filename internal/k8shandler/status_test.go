@@ -17,8 +17,6 @@ import (
 )
 
 func TestPruneMissingNodes(t *testing.T) {
-	nodes = map[string][]NodeTypeInterface{}
-
 	tests := []struct {
 		desc        string
 		cluster     *loggingv1.Elasticsearch
@@ -305,8 +303,7 @@ func TestPruneMissingNodes(t *testing.T) {
 		client := newFakeClient(test.pods, test.deployments, test.missingPods, test.missingDpl)
 
 		// Populate nodes in operator memory
-		key := nodeMapKey(test.cluster.Name, test.cluster.Namespace)
-		nodes[key] = populateNodes(test.cluster.Name, test.deployments, client)
+		registry.get(test.cluster.Name, test.cluster.Namespace).nodes = populateNodes(test.cluster.Name, test.deployments, client)
 
 		// Define new elasticsearch CR request
 		er := &ElasticsearchRequest{client: client, cluster: test.cluster}
@@ -0,0 +1,83 @@
+package k8shandler
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+// StorageChangeAckAnnotation confirms the nodes switching between ephemeral and persistent
+// storage may lose their data. Its value is the generation of the cluster spec it confirms,
+// so that later changes need to be confirmed again.
+const StorageChangeAckAnnotation = "logging.openshift.io/acknowledge-storage-change"
+
+const (
+	emptyDirStorage   = "emptyDir"
+	persistentStorage = "persistentVolumeClaim"
+)
+
+// podTemplateStorage returns the kind of volume the data of the node is stored on
+func podTemplateStorage(template v1.PodTemplateSpec) string {
+	for _, volume := range template.Spec.Volumes {
+		if volume.Name != "elasticsearch-storage" {
+			continue
+		}
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			return persistentStorage
+		case volume.EmptyDir != nil:
+			return emptyDirStorage
+		}
+	}
+	return ""
+}
+
+// hasStorageChange returns true if the node switches between ephemeral and persistent storage
+// with its next update. Its data doesn't survive the switch.
+func hasStorageChange(node NodeTypeInterface) bool {
+	current, desired := node.storageChange()
+	return current != "" && desired != "" && current != desired
+}
+
+func anyStorageChange(nodes []NodeTypeInterface) bool {
+	for _, node := range nodes {
+		if hasStorageChange(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStorageChangeAcknowledged returns true if the annotation confirms the current spec
+func isStorageChangeAcknowledged(cluster *api.Elasticsearch) bool {
+	return cluster.GetAnnotations()[StorageChangeAckAnnotation] == strconv.FormatInt(cluster.GetGeneration(), 10)
+}
+
+// confirmStorageChanges holds back the scheduled nodes that switch between ephemeral and
+// persistent storage until the change was acknowledged and reports them in the
+// StorageChangeRequiresConfirmation condition. Removing storage.size falls back to ephemeral
+// storage and is held back as well.
+func (er *ElasticsearchRequest) confirmStorageChanges(nodes []NodeTypeInterface) ([]NodeTypeInterface, error) {
+	cluster := er.cluster
+	acknowledged := isStorageChangeAcknowledged(cluster)
+
+	allowed := []NodeTypeInterface{}
+	held := []string{}
+	for _, node := range nodes {
+		if acknowledged || !hasStorageChange(node) {
+			allowed = append(allowed, node)
+			continue
+		}
+
+		current, desired := node.storageChange()
+		held = append(held, fmt.Sprintf("node %s switches its storage from %s to %s", node.name(), current, desired))
+	}
+
+	if err := updateStorageChangeRequiresConfirmationCondition(cluster, held, er.client); err != nil {
+		return nil, err
+	}
+	return allowed, nil
+}
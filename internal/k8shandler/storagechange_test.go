@@ -0,0 +1,183 @@
+package k8shandler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newStorageTemplate(source v1.VolumeSource) v1.PodTemplateSpec {
+	return v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{Name: "elasticsearch-config"},
+				{Name: "elasticsearch-storage", VolumeSource: source},
+			},
+		},
+	}
+}
+
+func TestPodTemplateStorage(t *testing.T) {
+	tests := []struct {
+		desc     string
+		template v1.PodTemplateSpec
+		want     string
+	}{
+		{
+			desc:     "ephemeral storage",
+			template: newStorageTemplate(v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}),
+			want:     emptyDirStorage,
+		},
+		{
+			desc:     "persistent storage",
+			template: newStorageTemplate(v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "claim"}}),
+			want:     persistentStorage,
+		},
+		{
+			desc:     "no storage volume",
+			template: v1.PodTemplateSpec{},
+			want:     "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := podTemplateStorage(test.template); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.desc, got, test.want)
+		}
+	}
+}
+
+func newStorageChangeCluster(annotations map[string]string) *loggingv1.Elasticsearch {
+	uuid := "abcd1234"
+	cluster := newTestCluster(loggingv1.ElasticsearchSpec{
+		Nodes: []loggingv1.ElasticsearchNode{
+			{
+				Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+				NodeCount: 1,
+				GenUUID:   &uuid,
+				Storage:   loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity("10Gi")},
+			},
+		},
+	})
+	cluster.Generation = 2
+	cluster.Annotations = annotations
+	return cluster
+}
+
+// newEphemeralDeployment is the deployment of the node before persistent storage was configured
+func newEphemeralDeployment() *apps.Deployment {
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-d-abcd1234-1",
+			Namespace: "openshift-logging",
+		},
+		Spec: apps.DeploymentSpec{
+			Template: newStorageTemplate(v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}),
+		},
+	}
+}
+
+func TestConfirmStorageChangesHoldsNode(t *testing.T) {
+	cluster := newStorageChangeCluster(nil)
+	er := newTestRequest(cluster, nil, newEphemeralDeployment())
+	nodes := er.GetNodeTypeInterface("abcd1234", cluster.Spec.Nodes[0])
+
+	if !hasStorageChange(nodes[0]) {
+		t.Fatalf("expected the node to switch from ephemeral to persistent storage")
+	}
+
+	allowed, err := er.confirmStorageChanges(nodes)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(allowed) != 0 {
+		t.Errorf("expected the node to be held back, got %v", allowed)
+	}
+	if !containsClusterCondition(loggingv1.StorageChangeRequiresConfirmation, v1.ConditionTrue, &cluster.Status) {
+		t.Fatalf("expected the storage change to require confirmation, got %v", cluster.Status.Conditions)
+	}
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == loggingv1.StorageChangeRequiresConfirmation && !strings.Contains(condition.Message, StorageChangeAckAnnotation+"=2") {
+			t.Errorf("expected the message to name the annotation confirming the generation, got %s", condition.Message)
+		}
+	}
+}
+
+func TestConfirmStorageChangesAcknowledged(t *testing.T) {
+	tests := []struct {
+		desc        string
+		annotations map[string]string
+		allowed     bool
+	}{
+		{
+			desc:        "acknowledged",
+			annotations: map[string]string{StorageChangeAckAnnotation: "2"},
+			allowed:     true,
+		},
+		{
+			desc:        "acknowledged for an earlier generation",
+			annotations: map[string]string{StorageChangeAckAnnotation: "1"},
+			allowed:     false,
+		},
+	}
+
+	for _, test := range tests {
+		cluster := newStorageChangeCluster(test.annotations)
+		er := newTestRequest(cluster, nil, newEphemeralDeployment())
+
+		allowed, err := er.confirmStorageChanges(er.GetNodeTypeInterface("abcd1234", cluster.Spec.Nodes[0]))
+		if err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+		if got := len(allowed) == 1; got != test.allowed {
+			t.Errorf("%s: expected the node to be allowed: %t, got %v", test.desc, test.allowed, allowed)
+		}
+		if got := containsClusterCondition(loggingv1.StorageChangeRequiresConfirmation, v1.ConditionTrue, &cluster.Status); got == test.allowed {
+			t.Errorf("%s: expected the condition to be %t", test.desc, !test.allowed)
+		}
+	}
+}
+
+func TestPerformNodeUpdateResumesStorageChange(t *testing.T) {
+	// the new template was pushed before the reconcile waiting for the node to rejoin ended,
+	// the node itself doesn't switch its storage anymore
+	cluster := newStorageChangeCluster(map[string]string{StorageChangeAckAnnotation: "2"})
+	cluster.Status.Nodes = []loggingv1.ElasticsearchNodeStatus{
+		{
+			DeploymentName: "elasticsearch-d-abcd1234-1",
+			UpgradeStatus: loggingv1.ElasticsearchNodeUpgradeStatus{
+				UnderUpgrade:       v1.ConditionTrue,
+				UpgradePhase:       loggingv1.NodeRestarting,
+				AllocationExcluded: v1.ConditionTrue,
+			},
+		},
+	}
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/state/nodes": {{StatusCode: http.StatusOK, Body: `{"nodes": {"1": {"name": "elasticsearch-d-abcd1234-1"}}}`}},
+		"_cluster/settings": {
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+			{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`},
+		},
+		"_cluster/health": {{StatusCode: http.StatusOK, Body: `{"status": "green"}`}},
+	})
+	er := newTestRequest(cluster, chatter)
+	node := er.GetNodeTypeInterface("abcd1234", cluster.Spec.Nodes[0])[0]
+
+	if err := er.PerformNodeUpdate(node); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if req, found := chatter.GetRequest("_cluster/settings"); !found || !strings.Contains(req.Body, "cluster.routing.allocation.exclude._name") {
+		t.Errorf("expected the allocation exclusion of the node to be cleared, got %v", req)
+	}
+	if _, status := getNodeStatus(node.name(), &cluster.Status); status.UpgradeStatus.AllocationExcluded != "" {
+		t.Errorf("expected the exclusion to be dropped from the node status, got %v", status.UpgradeStatus)
+	}
+}
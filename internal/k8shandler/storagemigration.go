@@ -0,0 +1,330 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+// IsStorageMigrationInProgress returns true while node groups are moved onto another storage
+// class. The reconciler uses it to check on the relocation sooner than its regular period.
+func IsStorageMigrationInProgress(cluster *api.Elasticsearch) bool {
+	return len(cluster.Status.StorageMigrations) > 0
+}
+
+func getStorageMigration(uuid string, status *api.ElasticsearchStatus) *api.ElasticsearchStorageMigration {
+	for i := range status.StorageMigrations {
+		if status.StorageMigrations[i].UUID == uuid {
+			return &status.StorageMigrations[i]
+		}
+	}
+	return nil
+}
+
+// isMigratingStorage returns true if the nodes of the node group are being replaced
+func (er *ElasticsearchRequest) isMigratingStorage(node api.ElasticsearchNode) bool {
+	return node.GenUUID != nil && getStorageMigration(*node.GenUUID, &er.cluster.Status) != nil
+}
+
+func isStorageMigrationDone(migration *api.ElasticsearchStorageMigration) bool {
+	for _, node := range migration.Nodes {
+		if node.Phase != api.NodeMigrationRetired {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeGroupInterfaces returns the nodes of a node group of the spec. A node group migrating its
+// storage runs on its old nodes not retired yet and the replacements started so far.
+func (er *ElasticsearchRequest) nodeGroupInterfaces(node api.ElasticsearchNode) []NodeTypeInterface {
+	migration := getStorageMigration(*node.GenUUID, &er.cluster.Status)
+	if migration == nil {
+		return er.GetNodeTypeInterface(*node.GenUUID, node)
+	}
+
+	roleMap := getNodeRoleMap(node)
+	nodes := []NodeTypeInterface{}
+	for _, n := range migration.Nodes {
+		if n.Phase != api.NodeMigrationRetired {
+			nodes = append(nodes, newDeploymentNode(n.Name, node, er.cluster, roleMap, er.client, er.esClient))
+		}
+		if n.Phase != api.NodeMigrationPending {
+			nodes = append(nodes, newDeploymentNode(n.Replacement, node, er.cluster, roleMap, er.client, er.esClient))
+		}
+	}
+	return nodes
+}
+
+// migratingMasterCount returns the number of master nodes running next to the nodes they replace
+func (er *ElasticsearchRequest) migratingMasterCount() int32 {
+	count := int32(0)
+	for _, node := range er.cluster.Spec.Nodes {
+		if !isMasterNode(node) || node.GenUUID == nil {
+			continue
+		}
+		if migration := getStorageMigration(*node.GenUUID, &er.cluster.Status); migration != nil {
+			for _, n := range migration.Nodes {
+				if n.Phase == api.NodeMigrationRelocating {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// needsStorageMigration returns true if a claim of the node group was provisioned with another
+// storage class than the one of the spec. Only the data nodes, which run a deployment per node,
+// can be replaced one at a time.
+func (er *ElasticsearchRequest) needsStorageMigration(node api.ElasticsearchNode) (bool, error) {
+	if !isDataNode(node) || node.GenUUID == nil || node.Storage.Size == nil || node.Storage.StorageClassName == nil {
+		return false, nil
+	}
+
+	for _, nodeName := range er.nodeNames(*node.GenUUID, node) {
+		claimName := fmt.Sprintf("%s-%s", er.cluster.Name, nodeName)
+
+		claim := &v1.PersistentVolumeClaim{}
+		if err := er.client.Get(context.TODO(), types.NamespacedName{Name: claimName, Namespace: er.cluster.Namespace}, claim); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, kverrors.Wrap(err, "failed to get PVC",
+				"claim", claimName)
+		}
+
+		if storageClassName(claim.Spec.StorageClassName) != *node.Storage.StorageClassName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// planStorageMigrations starts a migration for the node groups whose storage class changed and
+// hands the node group over to its replacement nodes once every node was replaced
+func (er *ElasticsearchRequest) planStorageMigrations() error {
+	cluster := er.cluster
+
+	for index, node := range cluster.Spec.Nodes {
+		if node.GenUUID == nil {
+			continue
+		}
+		uuid := *node.GenUUID
+
+		if migration := getStorageMigration(uuid, &cluster.Status); migration != nil {
+			if !isStorageMigrationDone(migration) {
+				continue
+			}
+			if err := er.completeStorageMigration(index, *migration); err != nil {
+				return err
+			}
+			continue
+		}
+
+		needed, err := er.needsStorageMigration(node)
+		if err != nil {
+			return err
+		}
+		if !needed {
+			continue
+		}
+
+		newUUID, err := utils.RandStringBytes(8)
+		if err != nil {
+			return kverrors.Wrap(err, "failed to generate the UUID of the replacement nodes")
+		}
+
+		migration := api.ElasticsearchStorageMigration{
+			UUID:             uuid,
+			NewUUID:          newUUID,
+			StorageClassName: *node.Storage.StorageClassName,
+		}
+		replacements := er.nodeNames(newUUID, node)
+		for i, nodeName := range er.nodeNames(uuid, node) {
+			migration.Nodes = append(migration.Nodes, api.ElasticsearchNodeMigration{
+				Name:        nodeName,
+				Replacement: replacements[i],
+				Phase:       api.NodeMigrationPending,
+			})
+		}
+
+		log.Info("Migrating node group to new storage class",
+			"cluster", cluster.Name,
+			"namespace", cluster.Namespace,
+			"uuid", uuid,
+			"storageClassName", migration.StorageClassName)
+
+		if err := er.updateStorageMigrations(func(migrations []api.ElasticsearchStorageMigration) []api.ElasticsearchStorageMigration {
+			return append(migrations, migration)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// completeStorageMigration clears the allocation exclusion of the last retired node and
+// switches the node group of the spec to the UUID of its replacement nodes
+func (er *ElasticsearchRequest) completeStorageMigration(index int, migration api.ElasticsearchStorageMigration) error {
+	restart := ClusterRestart{
+		client:           er.esClient,
+		clusterName:      er.cluster.Name,
+		clusterNamespace: er.cluster.Namespace,
+	}
+	if err := restart.setAllocationExclusion(nil); err != nil {
+		return err
+	}
+
+	if err := er.replaceUUID(index, migration.UUID, migration.NewUUID); err != nil {
+		return err
+	}
+
+	log.Info("Completed storage migration of node group",
+		"cluster", er.cluster.Name,
+		"namespace", er.cluster.Namespace,
+		"uuid", migration.NewUUID)
+
+	return er.updateStorageMigrations(func(migrations []api.ElasticsearchStorageMigration) []api.ElasticsearchStorageMigration {
+		kept := []api.ElasticsearchStorageMigration{}
+		for _, m := range migrations {
+			if m.UUID != migration.UUID {
+				kept = append(kept, m)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		return kept
+	})
+}
+
+// progressStorageMigrations replaces a single node at a time. The replacement node is started
+// first, then the shards of the old node are relocated with an allocation exclusion and the
+// old node is retired together with its claim.
+func (er *ElasticsearchRequest) progressStorageMigrations() error {
+	cluster := er.cluster
+
+	for i := range cluster.Status.StorageMigrations {
+		migration := cluster.Status.StorageMigrations[i]
+
+		for j, node := range migration.Nodes {
+			switch node.Phase {
+			case api.NodeMigrationRetired:
+				continue
+
+			case api.NodeMigrationPending:
+				log.Info("Starting replacement node", "node", node.Name, "replacement", node.Replacement)
+				return er.setNodeMigrationPhase(migration.UUID, j, api.NodeMigrationRelocating)
+
+			case api.NodeMigrationRelocating:
+				retired, err := er.relocateMigratingNode(node)
+				if err != nil || !retired {
+					return err
+				}
+				return er.setNodeMigrationPhase(migration.UUID, j, api.NodeMigrationRetired)
+			}
+		}
+	}
+
+	return nil
+}
+
+// relocateMigratingNode returns true once the shards of the node moved off it and its claim
+// was deleted. The node itself is removed along with the other nodes the spec doesn't
+// define anymore.
+func (er *ElasticsearchRequest) relocateMigratingNode(node api.ElasticsearchNodeMigration) (bool, error) {
+	joined, err := er.esClient.IsNodeInCluster(node.Replacement)
+	if err != nil {
+		return false, err
+	}
+	if !joined {
+		er.L().Info("Waiting for replacement node to join the cluster", "node", node.Name, "replacement", node.Replacement)
+		return false, nil
+	}
+
+	restart := ClusterRestart{
+		client:           er.esClient,
+		clusterName:      er.cluster.Name,
+		clusterNamespace: er.cluster.Namespace,
+	}
+	if err := restart.setAllocationExclusion([]string{node.Name}); err != nil {
+		return false, err
+	}
+
+	count, err := er.esClient.GetNodeShardCount(node.Name)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		er.L().Info("Waiting for shards to relocate off migrating node", "node", node.Name, "shards", count)
+		return false, nil
+	}
+
+	er.L().Info("Retiring migrated node", "node", node.Name)
+	claim := persistentVolumeClaim(fmt.Sprintf("%s-%s", er.cluster.Name, node.Name), er.cluster.Namespace, er.cluster.Name)
+	if err := er.client.Delete(context.TODO(), claim); err != nil && !apierrors.IsNotFound(err) {
+		return false, kverrors.Wrap(err, "failed to delete storage of migrated node", "claim", claim.Name)
+	}
+
+	return true, nil
+}
+
+func (er *ElasticsearchRequest) setNodeMigrationPhase(uuid string, index int, phase api.NodeMigrationPhase) error {
+	return er.updateStorageMigrations(func(migrations []api.ElasticsearchStorageMigration) []api.ElasticsearchStorageMigration {
+		for i := range migrations {
+			if migrations[i].UUID == uuid && index < len(migrations[i].Nodes) {
+				migrations[i].Nodes[index].Phase = phase
+			}
+		}
+		return migrations
+	})
+}
+
+// updateStorageMigrations persists the progress right away, the nodes of a migrating node group
+// are derived from it
+func (er *ElasticsearchRequest) updateStorageMigrations(update func([]api.ElasticsearchStorageMigration) []api.ElasticsearchStorageMigration) error {
+	cluster := er.cluster
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := er.client.Get(context.TODO(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, cluster); err != nil {
+			return err
+		}
+
+		cluster.Status.StorageMigrations = update(cluster.Status.StorageMigrations)
+		return er.client.Status().Update(context.TODO(), cluster)
+	})
+	return kverrors.Wrap(retryErr, "failed to update storage migrations of cluster",
+		"cluster", cluster.Name)
+}
+
+// replaceUUID switches a node group of the spec to the UUID of its replacement nodes
+func (er *ElasticsearchRequest) replaceUUID(index int, from, to string) error {
+	cluster := er.cluster
+
+	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := er.client.Get(context.TODO(), types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}, cluster); err != nil {
+			return err
+		}
+
+		if index >= len(cluster.Spec.Nodes) || cluster.Spec.Nodes[index].GenUUID == nil || *cluster.Spec.Nodes[index].GenUUID != from {
+			return nil
+		}
+
+		cluster.Spec.Nodes[index].GenUUID = &to
+		return er.client.Update(context.TODO(), cluster)
+	})
+	return kverrors.Wrap(retryErr, "failed to update the UUID of the node group",
+		"cluster", cluster.Name,
+		"uuid", from)
+}
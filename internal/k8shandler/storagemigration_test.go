@@ -0,0 +1,151 @@
+package k8shandler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newMigratingCluster(migrations ...loggingv1.ElasticsearchStorageMigration) *loggingv1.Elasticsearch {
+	uuid := "abcd1234"
+	class := "gp3"
+	cluster := newTestCluster(loggingv1.ElasticsearchSpec{
+		Nodes: []loggingv1.ElasticsearchNode{
+			{
+				Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleMaster},
+				NodeCount: 2,
+				GenUUID:   &uuid,
+				Storage:   loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity("10Gi"), StorageClassName: &class},
+			},
+		},
+	})
+	cluster.Status.StorageMigrations = migrations
+	return cluster
+}
+
+func newStorageMigration(phases ...loggingv1.NodeMigrationPhase) loggingv1.ElasticsearchStorageMigration {
+	migration := loggingv1.ElasticsearchStorageMigration{
+		UUID:             "abcd1234",
+		NewUUID:          "efgh5678",
+		StorageClassName: "gp3",
+	}
+	for i, phase := range phases {
+		suffix := []string{"-1", "-2"}[i]
+		migration.Nodes = append(migration.Nodes, loggingv1.ElasticsearchNodeMigration{
+			Name:        "elasticsearch-dm-abcd1234" + suffix,
+			Replacement: "elasticsearch-dm-efgh5678" + suffix,
+			Phase:       phase,
+		})
+	}
+	return migration
+}
+
+func TestPlanStorageMigrations(t *testing.T) {
+	cluster := newMigratingCluster()
+	er := newTestRequest(cluster, nil,
+		newClaim("elasticsearch-elasticsearch-dm-abcd1234-1", "10Gi", "gp2"),
+		newClaim("elasticsearch-elasticsearch-dm-abcd1234-2", "10Gi", "gp2"),
+	)
+
+	if err := er.planStorageMigrations(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if len(cluster.Status.StorageMigrations) != 1 {
+		t.Fatalf("expected the node group to be migrated, got %v", cluster.Status.StorageMigrations)
+	}
+	migration := cluster.Status.StorageMigrations[0]
+	if migration.UUID != "abcd1234" || migration.NewUUID == "abcd1234" || migration.StorageClassName != "gp3" {
+		t.Errorf("got migration %v", migration)
+	}
+	for i, node := range migration.Nodes {
+		if node.Phase != loggingv1.NodeMigrationPending || !strings.HasSuffix(node.Replacement, migration.NewUUID+[]string{"-1", "-2"}[i]) {
+			t.Errorf("expected node %s to be replaced by a node of the new UUID, got %v", node.Name, node)
+		}
+	}
+
+	if err := er.reconcileVolumeExpansion(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if containsClusterCondition(loggingv1.StorageResizeRejected, "True", &cluster.Status) {
+		t.Errorf("expected the change of the storage class to be migrated instead of rejected")
+	}
+}
+
+func TestNodeGroupInterfacesDuringStorageMigration(t *testing.T) {
+	cluster := newMigratingCluster(newStorageMigration(loggingv1.NodeMigrationRetired, loggingv1.NodeMigrationRelocating))
+	er := newTestRequest(cluster, nil)
+
+	names := []string{}
+	for _, node := range er.nodeGroupInterfaces(cluster.Spec.Nodes[0]) {
+		names = append(names, node.name())
+	}
+
+	want := []string{"elasticsearch-dm-efgh5678-1", "elasticsearch-dm-abcd1234-2", "elasticsearch-dm-efgh5678-2"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got nodes %v, want %v", names, want)
+	}
+	if got := er.migratingMasterCount(); got != 1 {
+		t.Errorf("expected the replacement master to be counted, got %d", got)
+	}
+}
+
+func TestProgressStorageMigrations(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/state/nodes":           {{StatusCode: http.StatusOK, Body: `{"nodes": {"id": {"name": "elasticsearch-dm-efgh5678-1"}}}`}},
+		"_cluster/settings":              {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+		"_cat/shards?h=node&format=json": {{StatusCode: http.StatusOK, Body: `[{"node": "elasticsearch-dm-efgh5678-1"}]`}},
+	})
+	cluster := newMigratingCluster(newStorageMigration(loggingv1.NodeMigrationRelocating, loggingv1.NodeMigrationPending))
+	er := newTestRequest(cluster, chatter, newClaim("elasticsearch-elasticsearch-dm-abcd1234-1", "10Gi", "gp2"))
+
+	if err := er.progressStorageMigrations(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	nodes := cluster.Status.StorageMigrations[0].Nodes
+	if nodes[0].Phase != loggingv1.NodeMigrationRetired || nodes[1].Phase != loggingv1.NodeMigrationPending {
+		t.Errorf("expected the first node to be retired, got %v", nodes)
+	}
+	if claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-abcd1234-1"); claim != nil {
+		t.Errorf("expected the claim of the retired node to be deleted")
+	}
+	request, _ := chatter.GetRequest("_cluster/settings")
+	if !strings.Contains(request.Body, `"elasticsearch-dm-abcd1234-1"`) {
+		t.Errorf("expected the node to be excluded from allocation, got %s", request.Body)
+	}
+
+	if err := er.progressStorageMigrations(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if phase := cluster.Status.StorageMigrations[0].Nodes[1].Phase; phase != loggingv1.NodeMigrationRelocating {
+		t.Errorf("expected the replacement of the next node to be started, got %s", phase)
+	}
+}
+
+func TestCompleteStorageMigration(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/settings": {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+	})
+	cluster := newMigratingCluster(newStorageMigration(loggingv1.NodeMigrationRetired, loggingv1.NodeMigrationRetired))
+	er := newTestRequest(cluster, chatter)
+
+	if err := er.planStorageMigrations(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if uuid := *cluster.Spec.Nodes[0].GenUUID; uuid != "efgh5678" {
+		t.Errorf("expected the node group to take over the UUID of its replacements, got %s", uuid)
+	}
+	if len(cluster.Status.StorageMigrations) != 0 {
+		t.Errorf("expected the migration to be removed, got %v", cluster.Status.StorageMigrations)
+	}
+	request, _ := chatter.GetRequest("_cluster/settings")
+	if !strings.Contains(request.Body, "null") {
+		t.Errorf("expected the allocation exclusion to be cleared, got %s", request.Body)
+	}
+}
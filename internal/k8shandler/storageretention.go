@@ -0,0 +1,343 @@
+package k8shandler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
+)
+
+const (
+	// storageRetentionFinalizer keeps a deleted cluster until the retention of its claims was
+	// recorded on them
+	storageRetentionFinalizer = "logging.openshift.io/storage-retention"
+
+	// ClaimDeleteAfterAnnotation is the time an orphaned claim is deleted under the
+	// DeleteAfter retention policy
+	ClaimDeleteAfterAnnotation = "logging.openshift.io/delete-after"
+)
+
+// storageRetentionPolicy returns the retention policy of the cluster, DeleteAfter without
+// a duration retains the claims
+func storageRetentionPolicy(cluster *api.Elasticsearch) api.ElasticsearchStorageRetentionPolicy {
+	retain := api.ElasticsearchStorageRetentionPolicy{Type: api.StorageRetain}
+	if cluster.Spec.Storage == nil || cluster.Spec.Storage.RetentionPolicy == nil {
+		return retain
+	}
+
+	policy := *cluster.Spec.Storage.RetentionPolicy
+	if policy.Type == api.StorageDeleteAfter && policy.After == nil {
+		return retain
+	}
+	return policy
+}
+
+func getClusterClaims(clusterName, namespace string, c client.Client) ([]v1.PersistentVolumeClaim, error) {
+	claims, err := GetPVCList(namespace, map[string]string{"logging-cluster": clusterName}, c)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to list the claims of the cluster",
+			"cluster", clusterName)
+	}
+	sort.Slice(claims.Items, func(i, j int) bool { return claims.Items[i].Name < claims.Items[j].Name })
+	return claims.Items, nil
+}
+
+// orphanedClaimNames returns the claims no node of the spec runs on. It returns false while
+// nodes wait for their UUID, they may still adopt the claims of a former cluster.
+func (er *ElasticsearchRequest) orphanedClaimNames(claims []v1.PersistentVolumeClaim) (sets.String, bool) {
+	cluster := er.cluster
+
+	// the surge node is retired on its own once the rollout completed
+	inUse := sets.NewString(fmt.Sprintf("%s-%s", cluster.Name, surgeNodeName(cluster.Name)))
+	for _, node := range cluster.Spec.Nodes {
+		if node.GenUUID == nil {
+			return nil, false
+		}
+		for _, nodeName := range er.nodeNames(*node.GenUUID, node) {
+			inUse.Insert(fmt.Sprintf("%s-%s", cluster.Name, nodeName))
+		}
+		if migration := getStorageMigration(*node.GenUUID, &cluster.Status); migration != nil {
+			for _, n := range migration.Nodes {
+				inUse.Insert(fmt.Sprintf("%s-%s", cluster.Name, n.Replacement))
+			}
+		}
+	}
+
+	orphaned := sets.NewString()
+	for _, claim := range claims {
+		if !inUse.Has(claim.Name) {
+			orphaned.Insert(claim.Name)
+		}
+	}
+	return orphaned, true
+}
+
+// recoveringUUIDs returns the node UUIDs of the cluster whose claims data recoveries still run on
+func recoveringUUIDs(clusterName, namespace string, c client.Client) (sets.String, error) {
+	recoveries := &api.ElasticsearchDataRecoveryList{}
+	if err := c.List(context.TODO(), recoveries, client.InNamespace(namespace)); err != nil {
+		return nil, kverrors.Wrap(err, "failed to list data recoveries",
+			"namespace", namespace)
+	}
+
+	uuids := sets.NewString()
+	for i := range recoveries.Items {
+		recovery := &recoveries.Items[i]
+		if dataRecoverySourceCluster(recovery) == clusterName && !isDataRecoveryDone(recovery.Status.Phase) {
+			uuids.Insert(recovery.Spec.UUIDs...)
+		}
+	}
+	return uuids, nil
+}
+
+func claimUUID(clusterName, claimName string) string {
+	claim, _ := parseRecoveryClaim(clusterName, claimName)
+	return claim.uuid
+}
+
+// claimDeleteAfter returns the time the claim is deleted or nil if it is retained
+func claimDeleteAfter(claim *v1.PersistentVolumeClaim) *metav1.Time {
+	value, ok := claim.GetAnnotations()[ClaimDeleteAfterAnnotation]
+	if !ok {
+		return nil
+	}
+	deleteAfter, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: deleteAfter.Local()}
+}
+
+func setClaimDeleteAfter(claim *v1.PersistentVolumeClaim, deleteAfter time.Time) {
+	annotations := claim.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ClaimDeleteAfterAnnotation] = deleteAfter.UTC().Format(time.RFC3339)
+	claim.SetAnnotations(annotations)
+}
+
+func deleteClaim(claim *v1.PersistentVolumeClaim, c client.Client) error {
+	log.Info("Deleting orphaned claim", "claim", claim.Name, "namespace", claim.Namespace)
+	if err := c.Delete(context.TODO(), claim); err != nil && !apierrors.IsNotFound(kverrors.Root(err)) {
+		return kverrors.Wrap(err, "failed to delete orphaned claim",
+			"claim", claim.Name)
+	}
+	return nil
+}
+
+func removeOwnerRef(obj metav1.Object, cluster *api.Elasticsearch) {
+	refs := []metav1.OwnerReference{}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != cluster.UID {
+			refs = append(refs, ref)
+		}
+	}
+	obj.SetOwnerReferences(refs)
+}
+
+// reconcileStorageRetention applies the retention policy to the claims of the cluster. Under
+// the Delete policy the claims are owned by the cluster so they are garbage collected with it,
+// under the DeleteAfter policy the finalizer records the retention on the claims once the
+// cluster is deleted. Claims data recoveries run on are kept.
+func (er *ElasticsearchRequest) reconcileStorageRetention() error {
+	cluster := er.cluster
+	policy := storageRetentionPolicy(cluster)
+
+	hasFinalizer := utils.ContainsString(cluster.GetFinalizers(), storageRetentionFinalizer)
+	if wantFinalizer := policy.Type == api.StorageDeleteAfter; hasFinalizer != wantFinalizer {
+		if wantFinalizer {
+			cluster.SetFinalizers(append(cluster.GetFinalizers(), storageRetentionFinalizer))
+		} else {
+			cluster.SetFinalizers(utils.RemoveString(cluster.GetFinalizers(), storageRetentionFinalizer))
+		}
+		if err := er.client.Update(context.TODO(), cluster); err != nil {
+			return kverrors.Wrap(err, "failed to update the storage retention finalizer",
+				"cluster", cluster.Name)
+		}
+	}
+
+	claims, err := getClusterClaims(cluster.Name, cluster.Namespace, er.client)
+	if err != nil {
+		return err
+	}
+	orphaned, ok := er.orphanedClaimNames(claims)
+	if !ok {
+		return nil
+	}
+	recovering, err := recoveringUUIDs(cluster.Name, cluster.Namespace, er.client)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range claims {
+		claim := &claims[i]
+		isOrphaned := orphaned.Has(claim.Name)
+		retained := !isOrphaned || recovering.Has(claimUUID(cluster.Name, claim.Name))
+
+		if !retained && policy.Type == api.StorageDelete {
+			if err := deleteClaim(claim, er.client); err != nil {
+				return err
+			}
+			continue
+		}
+
+		changed := false
+		_, hasDeleteAfter := claim.GetAnnotations()[ClaimDeleteAfterAnnotation]
+		switch {
+		case !retained && policy.Type == api.StorageDeleteAfter:
+			if deleteAfter := claimDeleteAfter(claim); deleteAfter == nil {
+				setClaimDeleteAfter(claim, now.Add(policy.After.Duration))
+				changed = true
+			} else if now.After(deleteAfter.Time) {
+				if err := deleteClaim(claim, er.client); err != nil {
+					return err
+				}
+				continue
+			}
+		case hasDeleteAfter:
+			// the claim is in use again or the policy changed
+			delete(claim.Annotations, ClaimDeleteAfterAnnotation)
+			changed = true
+		}
+
+		// orphaned claims left to data recoveries are not garbage collected with the cluster
+		wantOwnerRef := policy.Type == api.StorageDelete && !isOrphaned
+		if hasOwnerRef(claim, cluster) != wantOwnerRef {
+			if wantOwnerRef {
+				cluster.AddOwnerRefTo(claim)
+			} else {
+				removeOwnerRef(claim, cluster)
+			}
+			changed = true
+		}
+
+		if changed {
+			if err := er.client.Update(context.TODO(), claim); err != nil {
+				return kverrors.Wrap(err, "failed to apply the retention policy to claim",
+					"claim", claim.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// updateOrphanedClaimStatus lists the claims no node runs on, so the storage still paid for
+// shows in the status
+func (er *ElasticsearchRequest) updateOrphanedClaimStatus(status *api.ElasticsearchStatus) {
+	cluster := er.cluster
+
+	claims, err := getClusterClaims(cluster.Name, cluster.Namespace, er.client)
+	if err != nil {
+		return
+	}
+	orphaned, ok := er.orphanedClaimNames(claims)
+	if !ok {
+		return
+	}
+
+	inventory := []api.ElasticsearchOrphanedClaim{}
+	for i := range claims {
+		claim := &claims[i]
+		if !orphaned.Has(claim.Name) {
+			continue
+		}
+		entry := api.ElasticsearchOrphanedClaim{
+			Name:        claim.Name,
+			UUID:        claimUUID(cluster.Name, claim.Name),
+			Created:     claim.CreationTimestamp,
+			DeleteAfter: claimDeleteAfter(claim),
+		}
+		if size, ok := claim.Status.Capacity[v1.ResourceStorage]; ok {
+			entry.Size = &size
+		}
+		inventory = append(inventory, entry)
+	}
+
+	if len(inventory) == 0 {
+		status.OrphanedClaims = nil
+	} else {
+		status.OrphanedClaims = inventory
+	}
+}
+
+// ReleaseClusterStorage records the retention on the claims of the deleted cluster and
+// releases it, the claims are deleted by DeleteExpiredClaims once their retention passed
+func ReleaseClusterStorage(cluster *api.Elasticsearch, c client.Client) error {
+	if !utils.ContainsString(cluster.GetFinalizers(), storageRetentionFinalizer) {
+		return nil
+	}
+
+	if policy := storageRetentionPolicy(cluster); policy.Type == api.StorageDeleteAfter {
+		claims, err := getClusterClaims(cluster.Name, cluster.Namespace, c)
+		if err != nil {
+			return err
+		}
+		deleteAfter := time.Now().Add(policy.After.Duration)
+		for i := range claims {
+			claim := &claims[i]
+			// claims orphaned before keep their time
+			if _, ok := claim.GetAnnotations()[ClaimDeleteAfterAnnotation]; ok {
+				continue
+			}
+			setClaimDeleteAfter(claim, deleteAfter)
+			if err := c.Update(context.TODO(), claim); err != nil {
+				return kverrors.Wrap(err, "failed to apply the retention policy to claim",
+					"claim", claim.Name)
+			}
+		}
+	}
+
+	cluster.SetFinalizers(utils.RemoveString(cluster.GetFinalizers(), storageRetentionFinalizer))
+	if err := c.Update(context.TODO(), cluster); err != nil {
+		return kverrors.Wrap(err, "failed to remove the storage retention finalizer",
+			"cluster", cluster.Name)
+	}
+	return nil
+}
+
+// DeleteExpiredClaims deletes the claims of a deleted cluster whose retention passed. It
+// returns how long until the next claim expires or zero if none is left.
+func DeleteExpiredClaims(clusterName, namespace string, c client.Client) (time.Duration, error) {
+	claims, err := getClusterClaims(clusterName, namespace, c)
+	if err != nil {
+		return 0, err
+	}
+	recovering, err := recoveringUUIDs(clusterName, namespace, c)
+	if err != nil {
+		return 0, err
+	}
+
+	next := time.Duration(0)
+	for i := range claims {
+		claim := &claims[i]
+		deleteAfter := claimDeleteAfter(claim)
+		// data recoveries are watched, the claims are deleted once they complete
+		if deleteAfter == nil || recovering.Has(claimUUID(clusterName, claim.Name)) {
+			continue
+		}
+
+		if wait := time.Until(deleteAfter.Time); wait > 0 {
+			if next == 0 || wait < next {
+				next = wait
+			}
+			continue
+		}
+		if err := deleteClaim(claim, c); err != nil {
+			return 0, err
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,170 @@
+package k8shandler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+)
+
+func newRetentionCluster(policy *loggingv1.ElasticsearchStorageRetentionPolicy) *loggingv1.Elasticsearch {
+	uuid := "abcd1234"
+	return newTestCluster(loggingv1.ElasticsearchSpec{
+		Nodes: []loggingv1.ElasticsearchNode{
+			{
+				Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData, loggingv1.ElasticsearchRoleMaster},
+				NodeCount: 1,
+				GenUUID:   &uuid,
+				Storage:   loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity("10Gi")},
+			},
+		},
+		Storage: &loggingv1.ElasticsearchClusterStorageSpec{RetentionPolicy: policy},
+	})
+}
+
+// newRetentionRequest returns a request for the cluster with the claim of its node and an
+// orphaned claim
+func newRetentionRequest(policy *loggingv1.ElasticsearchStorageRetentionPolicy, objs ...runtime.Object) *ElasticsearchRequest {
+	objs = append(objs,
+		newClaim("elasticsearch-elasticsearch-dm-abcd1234-1", "10Gi", "gp2"),
+		newClaim("elasticsearch-elasticsearch-dm-efgh5678-1", "10Gi", "gp2"),
+	)
+	return newTestRequest(newRetentionCluster(policy), nil, objs...)
+}
+
+func getClaim(c client.Client, name string) *v1.PersistentVolumeClaim {
+	claim := &v1.PersistentVolumeClaim{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "openshift-logging"}, claim); err != nil {
+		return nil
+	}
+	return claim
+}
+
+func TestReconcileStorageRetentionDelete(t *testing.T) {
+	er := newRetentionRequest(&loggingv1.ElasticsearchStorageRetentionPolicy{Type: loggingv1.StorageDelete})
+
+	if err := er.reconcileStorageRetention(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-efgh5678-1"); claim != nil {
+		t.Errorf("expected the orphaned claim to be deleted")
+	}
+	claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-abcd1234-1")
+	if claim == nil || !hasOwnerRef(claim, er.cluster) {
+		t.Errorf("expected the claim in use to be owned by the cluster, got %v", claim)
+	}
+}
+
+func TestReconcileStorageRetentionKeepsRecoveringClaims(t *testing.T) {
+	er := newRetentionRequest(&loggingv1.ElasticsearchStorageRetentionPolicy{Type: loggingv1.StorageDelete}, newDataRecovery("efgh5678"))
+
+	if err := er.reconcileStorageRetention(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-efgh5678-1")
+	if claim == nil || hasOwnerRef(claim, er.cluster) {
+		t.Errorf("expected the claim of the data recovery to be kept and not owned, got %v", claim)
+	}
+}
+
+func TestReconcileStorageRetentionKeepsSurgeClaim(t *testing.T) {
+	er := newRetentionRequest(&loggingv1.ElasticsearchStorageRetentionPolicy{Type: loggingv1.StorageDelete}, newClaim("elasticsearch-elasticsearch-surge", "10Gi", "gp2"))
+
+	if err := er.reconcileStorageRetention(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if claim := getClaim(er.client, "elasticsearch-elasticsearch-surge"); claim == nil {
+		t.Errorf("expected the claim of the surge node to be kept")
+	}
+}
+
+func TestReconcileStorageRetentionDeleteAfter(t *testing.T) {
+	expired := newClaim("elasticsearch-elasticsearch-dm-ijkl9012-1", "10Gi", "gp2")
+	setClaimDeleteAfter(expired, time.Now().Add(-time.Hour))
+	er := newRetentionRequest(&loggingv1.ElasticsearchStorageRetentionPolicy{
+		Type:  loggingv1.StorageDeleteAfter,
+		After: &metav1.Duration{Duration: 24 * time.Hour},
+	}, expired)
+
+	if err := er.reconcileStorageRetention(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if !reflect.DeepEqual(er.cluster.Finalizers, []string{storageRetentionFinalizer}) {
+		t.Errorf("expected the finalizer to be added, got %v", er.cluster.Finalizers)
+	}
+	if claim := getClaim(er.client, expired.Name); claim != nil {
+		t.Errorf("expected the expired claim to be deleted")
+	}
+	claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-efgh5678-1")
+	if deleteAfter := claimDeleteAfter(claim); deleteAfter == nil || time.Until(deleteAfter.Time) < 23*time.Hour {
+		t.Errorf("expected the orphaned claim to be deleted in a day, got %v", deleteAfter)
+	}
+	if claim := getClaim(er.client, "elasticsearch-elasticsearch-dm-abcd1234-1"); claimDeleteAfter(claim) != nil {
+		t.Errorf("expected the claim in use to be retained")
+	}
+}
+
+func TestUpdateOrphanedClaimStatus(t *testing.T) {
+	orphaned := newClaim("elasticsearch-elasticsearch-dm-efgh5678-1", "10Gi", "gp2")
+	orphaned.Status.Capacity = v1.ResourceList{v1.ResourceStorage: *resourceQuantity("10Gi")}
+	er := newTestRequest(newRetentionCluster(nil), nil, orphaned)
+
+	status := &loggingv1.ElasticsearchStatus{}
+	er.updateOrphanedClaimStatus(status)
+
+	if len(status.OrphanedClaims) != 1 {
+		t.Fatalf("expected one orphaned claim, got %v", status.OrphanedClaims)
+	}
+	got := status.OrphanedClaims[0]
+	if got.Name != orphaned.Name || got.UUID != "efgh5678" || got.Size.Cmp(*resourceQuantity("10Gi")) != 0 || got.DeleteAfter != nil {
+		t.Errorf("got orphaned claim %v", got)
+	}
+}
+
+func TestReleaseClusterStorage(t *testing.T) {
+	er := newRetentionRequest(&loggingv1.ElasticsearchStorageRetentionPolicy{
+		Type:  loggingv1.StorageDeleteAfter,
+		After: &metav1.Duration{Duration: time.Hour},
+	})
+	cluster, c := er.cluster, er.client
+	cluster.Finalizers = []string{storageRetentionFinalizer}
+
+	if err := ReleaseClusterStorage(cluster, c); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(cluster.Finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", cluster.Finalizers)
+	}
+
+	wait, err := DeleteExpiredClaims("elasticsearch", "openshift-logging", c)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if wait <= 0 || wait > time.Hour {
+		t.Errorf("expected to wait up to an hour for the claims to expire, got %s", wait)
+	}
+
+	claim := getClaim(c, "elasticsearch-elasticsearch-dm-abcd1234-1")
+	setClaimDeleteAfter(claim, time.Now().Add(-time.Minute))
+	if err := c.Update(context.TODO(), claim); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if _, err := DeleteExpiredClaims("elasticsearch", "openshift-logging", c); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if claim := getClaim(c, "elasticsearch-elasticsearch-dm-abcd1234-1"); claim != nil {
+		t.Errorf("expected the expired claim to be deleted")
+	}
+}
@@ -0,0 +1,281 @@
+package k8shandler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	v1 "k8s.io/api/core/v1"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/constants"
+	"github.com/openshift/elasticsearch-operator/internal/k8shandler/migrations"
+	"github.com/openshift/elasticsearch-operator/internal/utils/comparators"
+)
+
+// lastMinorVersions are the minor versions rolling upgrades to the next major version start
+// from, earlier ones need a full cluster restart. Nodes below 6.0 always restart all at once.
+var lastMinorVersions = map[int]int{6: 8}
+
+// deprecatedClusterSetting is a cluster setting the next major version renames or ignores
+type deprecatedClusterSetting struct {
+	prefix  string
+	message string
+}
+
+// deprecatedClusterSettings are the deprecated cluster settings by the major version that
+// drops them
+var deprecatedClusterSettings = map[int][]deprecatedClusterSetting{
+	7: {
+		{prefix: "search.remote.", message: "is renamed to cluster.remote.* in Elasticsearch 7"},
+		{prefix: "discovery.zen.minimum_master_nodes", message: "is ignored by Elasticsearch 7"},
+	},
+}
+
+// podTemplateImage returns the image of the Elasticsearch container
+func podTemplateImage(template v1.PodTemplateSpec) string {
+	for _, container := range template.Spec.Containers {
+		if container.Name == "elasticsearch" {
+			return container.Image
+		}
+	}
+	return ""
+}
+
+// hasImageChange returns true if the node runs another image of Elasticsearch with its next
+// update
+func hasImageChange(node NodeTypeInterface) bool {
+	current, desired := node.imageChange()
+	return current != "" && desired != "" && current != desired
+}
+
+func anyImageChange(nodes []NodeTypeInterface) bool {
+	for _, node := range nodes {
+		if hasImageChange(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseMajorMinor(version string) (int, int, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return 0, 0, kverrors.New("failed to parse version", "version", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, kverrors.Wrap(err, "failed to parse version", "version", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, kverrors.Wrap(err, "failed to parse version", "version", version)
+	}
+	return major, minor, nil
+}
+
+func criticalIssue(format string, args ...interface{}) api.ElasticsearchUpgradeIssue {
+	return api.ElasticsearchUpgradeIssue{Level: api.UpgradeIssueCritical, Message: fmt.Sprintf(format, args...)}
+}
+
+func warningIssue(format string, args ...interface{}) api.ElasticsearchUpgradeIssue {
+	return api.ElasticsearchUpgradeIssue{Level: api.UpgradeIssueWarning, Message: fmt.Sprintf(format, args...)}
+}
+
+func hasCriticalIssue(issues []api.ElasticsearchUpgradeIssue) bool {
+	for _, issue := range issues {
+		if issue.Level == api.UpgradeIssueCritical {
+			return true
+		}
+	}
+	return false
+}
+
+// planUpgrade returns the strategy upgrading the nodes from the current to the target version
+// and the issues of the path between them. There is nothing to upgrade without a strategy and
+// issues.
+func planUpgrade(current, target string) (api.UpgradeStrategy, []api.ElasticsearchUpgradeIssue) {
+	switch comparators.CompareVersions(current, target) {
+	case 0:
+		return "", nil
+	case -1:
+		return "", []api.ElasticsearchUpgradeIssue{
+			criticalIssue("Downgrading from %s to %s isn't supported", current, target),
+		}
+	}
+
+	currentMajor, currentMinor, err := parseMajorMinor(current)
+	if err != nil {
+		return "", []api.ElasticsearchUpgradeIssue{criticalIssue("Unable to parse the current version %q", current)}
+	}
+	targetMajor, _, err := parseMajorMinor(target)
+	if err != nil {
+		return "", []api.ElasticsearchUpgradeIssue{criticalIssue("Unable to parse the target version %q", target)}
+	}
+
+	switch {
+	case targetMajor == currentMajor:
+		return api.UpgradeRollingRestart, nil
+	case targetMajor > currentMajor+1:
+		return "", []api.ElasticsearchUpgradeIssue{
+			criticalIssue("Upgrading from %s to %s skips major version %d, upgrade to it first", current, target, currentMajor+1),
+		}
+	}
+
+	lastMinor, ok := lastMinorVersions[currentMajor]
+	if !ok {
+		return api.UpgradeFullClusterRestart, []api.ElasticsearchUpgradeIssue{
+			warningIssue("Rolling upgrades from %s to %d aren't supported, the nodes are restarted all at once", current, targetMajor),
+		}
+	}
+	if currentMinor >= lastMinor {
+		return api.UpgradeRollingRestart, nil
+	}
+	return api.UpgradeFullClusterRestart, []api.ElasticsearchUpgradeIssue{
+		warningIssue("Rolling upgrades to %d start from %d.%d, the nodes are restarted all at once", targetMajor, currentMajor, lastMinor),
+	}
+}
+
+// checkUpgrade returns the issues of the cluster blocking or affecting the upgrade to the
+// target version, like the deprecation checks of the upgrade assistant do
+func (er *ElasticsearchRequest) checkUpgrade(current, target string) []api.ElasticsearchUpgradeIssue {
+	issues := []api.ElasticsearchUpgradeIssue{}
+
+	health, err := er.esClient.GetClusterHealthStatus()
+	if err != nil {
+		issues = append(issues, criticalIssue("Unable to get the cluster health: %s", kverrors.Message(err)))
+	} else if health != "green" {
+		issues = append(issues, criticalIssue("The cluster health is %s, the upgrade waits for it to be green", health))
+	}
+
+	currentMajor, _, _ := parseMajorMinor(current)
+	targetMajor, _, _ := parseMajorMinor(target)
+	if targetMajor == currentMajor {
+		return issues
+	}
+
+	incompatible, err := migrations.FindIncompatibleIndices(er.esClient, target)
+	if err != nil {
+		issues = append(issues, criticalIssue("Unable to get the versions of the indices: %s", kverrors.Message(err)))
+	}
+	indices := make([]string, 0, len(incompatible))
+	for index := range incompatible {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	for _, index := range indices {
+		if strings.HasPrefix(index, ".") {
+			issues = append(issues, criticalIssue("Index %s was created with %s, reindex or delete it before the upgrade", index, incompatible[index]))
+			continue
+		}
+		issues = append(issues, criticalIssue("Index %s was created with %s, the migrations reindex it", index, incompatible[index]))
+	}
+
+	settings, err := er.esClient.GetClusterSettings()
+	if err != nil {
+		issues = append(issues, criticalIssue("Unable to get the cluster settings: %s", kverrors.Message(err)))
+	}
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, deprecated := range deprecatedClusterSettings[targetMajor] {
+			if strings.HasPrefix(name, deprecated.prefix) {
+				issues = append(issues, warningIssue("Cluster setting %s %s", name, deprecated.message))
+			}
+		}
+	}
+
+	return issues
+}
+
+// validateUpgrade plans the upgrade of the scheduled nodes running another image to the
+// packaged version of Elasticsearch and reports it in the status. The nodes are held back
+// while a critical issue blocks the upgrade. The checks aren't repeated once the nodes
+// started upgrading, a node restarting would fail them.
+func (er *ElasticsearchRequest) validateUpgrade(nodes []NodeTypeInterface) ([]NodeTypeInterface, error) {
+	if !anyImageChange(nodes) {
+		return nodes, nil
+	}
+
+	target := constants.PackagedElasticsearchVersion()
+	if upgrade := er.cluster.Status.Upgrade; upgrade != nil && upgrade.Phase == api.UpgradeInProgress && upgrade.TargetVersion == target {
+		return nodes, nil
+	}
+
+	current, err := er.esClient.GetLowestClusterVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, issues := planUpgrade(current, target)
+	if strategy == "" && len(issues) == 0 {
+		// a new build of the same version
+		return nodes, nil
+	}
+	if strategy != "" {
+		issues = append(issues, er.checkUpgrade(current, target)...)
+	}
+
+	upgrade := &api.ElasticsearchUpgradeStatus{
+		CurrentVersion: current,
+		TargetVersion:  target,
+		Strategy:       strategy,
+		Phase:          api.UpgradeInProgress,
+		Issues:         issues,
+		Message:        fmt.Sprintf("Upgrading from %s to %s", current, target),
+	}
+	if hasCriticalIssue(issues) {
+		upgrade.Phase = api.UpgradeBlocked
+		upgrade.Message = "The nodes keep their version until the critical issues are resolved"
+	}
+	er.cluster.Status.Upgrade = upgrade
+
+	if upgrade.Phase != api.UpgradeBlocked {
+		log.Info("Upgrading Elasticsearch", "cluster", er.cluster.Name, "from", current, "to", target, "strategy", strategy)
+		return nodes, nil
+	}
+
+	allowed := []NodeTypeInterface{}
+	for _, node := range nodes {
+		if !hasImageChange(node) {
+			allowed = append(allowed, node)
+		}
+	}
+	return allowed, nil
+}
+
+// upgradeStrategy returns the planned strategy while the scheduled nodes upgrade, empty
+// otherwise
+func (er *ElasticsearchRequest) upgradeStrategy(nodes []NodeTypeInterface) api.UpgradeStrategy {
+	upgrade := er.cluster.Status.Upgrade
+	if upgrade == nil || upgrade.Phase != api.UpgradeInProgress || !anyImageChange(nodes) {
+		return ""
+	}
+	return upgrade.Strategy
+}
+
+// completeUpgrade marks the upgrade completed once every node runs the target version
+func (er *ElasticsearchRequest) completeUpgrade() error {
+	upgrade := er.cluster.Status.Upgrade
+	if upgrade == nil || upgrade.Phase != api.UpgradeInProgress {
+		return nil
+	}
+
+	version, err := er.esClient.GetLowestClusterVersion()
+	if err != nil {
+		return err
+	}
+	if comparators.CompareVersions(version, upgrade.TargetVersion) != 0 {
+		return nil
+	}
+
+	upgrade.Phase = api.UpgradeCompleted
+	upgrade.Message = fmt.Sprintf("Every node runs %s", version)
+	return nil
+}
@@ -0,0 +1,175 @@
+package k8shandler
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	tests := []struct {
+		desc     string
+		current  string
+		target   string
+		strategy loggingv1.UpgradeStrategy
+		levels   []loggingv1.UpgradeIssueLevel
+	}{
+		{
+			desc:    "same version",
+			current: "6.8.1",
+			target:  "6.8.1",
+		},
+		{
+			desc:     "minor version",
+			current:  "6.2.4",
+			target:   "6.8.1",
+			strategy: loggingv1.UpgradeRollingRestart,
+		},
+		{
+			desc:     "next major version from the last minor version",
+			current:  "6.8.1",
+			target:   "7.10.2",
+			strategy: loggingv1.UpgradeRollingRestart,
+		},
+		{
+			desc:     "next major version from an earlier minor version",
+			current:  "6.5.4",
+			target:   "7.10.2",
+			strategy: loggingv1.UpgradeFullClusterRestart,
+			levels:   []loggingv1.UpgradeIssueLevel{loggingv1.UpgradeIssueWarning},
+		},
+		{
+			desc:     "next major version from 5.x",
+			current:  "5.6.16",
+			target:   "6.8.1",
+			strategy: loggingv1.UpgradeFullClusterRestart,
+			levels:   []loggingv1.UpgradeIssueLevel{loggingv1.UpgradeIssueWarning},
+		},
+		{
+			desc:    "skipping a major version",
+			current: "5.6.16",
+			target:  "7.10.2",
+			levels:  []loggingv1.UpgradeIssueLevel{loggingv1.UpgradeIssueCritical},
+		},
+		{
+			desc:    "downgrade",
+			current: "6.8.1",
+			target:  "6.2.4",
+			levels:  []loggingv1.UpgradeIssueLevel{loggingv1.UpgradeIssueCritical},
+		},
+	}
+
+	for _, test := range tests {
+		strategy, issues := planUpgrade(test.current, test.target)
+		if strategy != test.strategy {
+			t.Errorf("%s: got strategy %q, want %q", test.desc, strategy, test.strategy)
+		}
+		var levels []loggingv1.UpgradeIssueLevel
+		for _, issue := range issues {
+			levels = append(levels, issue.Level)
+		}
+		if !reflect.DeepEqual(levels, test.levels) {
+			t.Errorf("%s: got issues %v, want levels %v", test.desc, issues, test.levels)
+		}
+	}
+}
+
+// newOldImageDeployment is the deployment of the node running an older image of Elasticsearch
+func newOldImageDeployment() *apps.Deployment {
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "elasticsearch-d-abcd1234-1",
+			Namespace: "openshift-logging",
+		},
+		Spec: apps.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{Name: "elasticsearch", Image: "origin-logging-elasticsearch5"}},
+				},
+			},
+		},
+	}
+}
+
+func newUpgradeChatter(health, versions string) *testhelpers.FakeElasticsearchChatter {
+	return testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/stats/nodes/_all":                            {{StatusCode: http.StatusOK, Body: `{"nodes": {"versions": ["5.6.16"]}}`}},
+		"_cluster/health":                                      {{StatusCode: http.StatusOK, Body: `{"status": "` + health + `"}`}},
+		"*/_settings/index.version.created?flat_settings=true": {{StatusCode: http.StatusOK, Body: versions}},
+		"_cluster/settings?flat_settings=true":                 {{StatusCode: http.StatusOK, Body: `{"persistent": {}, "transient": {}}`}},
+	})
+}
+
+func TestValidateUpgrade(t *testing.T) {
+	cluster := newStorageChangeCluster(nil)
+	er := newTestRequest(cluster, newUpgradeChatter("green", `{"app-000001": {"settings": {"index.version.created": "5061699"}}}`), newOldImageDeployment())
+	nodes := er.GetNodeTypeInterface("abcd1234", cluster.Spec.Nodes[0])
+
+	if !hasImageChange(nodes[0]) {
+		t.Fatalf("expected the node to change its image")
+	}
+
+	allowed, err := er.validateUpgrade(nodes)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(allowed) != 1 {
+		t.Errorf("expected the node to upgrade, got %v", allowed)
+	}
+
+	upgrade := cluster.Status.Upgrade
+	if upgrade == nil || upgrade.Phase != loggingv1.UpgradeInProgress || upgrade.Strategy != loggingv1.UpgradeFullClusterRestart {
+		t.Fatalf("expected a full cluster restart, got %v", upgrade)
+	}
+	if upgrade.CurrentVersion != "5.6.16" || upgrade.TargetVersion != "6.8.1" {
+		t.Errorf("expected an upgrade from 5.6.16 to 6.8.1, got %s to %s", upgrade.CurrentVersion, upgrade.TargetVersion)
+	}
+	if strategy := er.upgradeStrategy(nodes); strategy != loggingv1.UpgradeFullClusterRestart {
+		t.Errorf("expected the nodes to use the planned strategy, got %q", strategy)
+	}
+}
+
+func TestValidateUpgradeBlocked(t *testing.T) {
+	tests := []struct {
+		desc     string
+		health   string
+		versions string
+	}{
+		{
+			desc:     "cluster not green",
+			health:   "yellow",
+			versions: `{}`,
+		},
+		{
+			desc:     "incompatible index",
+			health:   "green",
+			versions: `{"app-000001": {"settings": {"index.version.created": "2040699"}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		cluster := newStorageChangeCluster(nil)
+		er := newTestRequest(cluster, newUpgradeChatter(test.health, test.versions), newOldImageDeployment())
+		nodes := er.GetNodeTypeInterface("abcd1234", cluster.Spec.Nodes[0])
+
+		allowed, err := er.validateUpgrade(nodes)
+		if err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+		if len(allowed) != 0 {
+			t.Errorf("%s: expected the node to be held back, got %v", test.desc, allowed)
+		}
+
+		upgrade := cluster.Status.Upgrade
+		if upgrade == nil || upgrade.Phase != loggingv1.UpgradeBlocked || !hasCriticalIssue(upgrade.Issues) {
+			t.Errorf("%s: expected the upgrade to be blocked, got %v", test.desc, upgrade)
+		}
+	}
+}
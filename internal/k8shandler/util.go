@@ -254,6 +254,39 @@ func (er *ElasticsearchRequest) isValidConf() error {
 		}
 	}
 
+	if err := validateProxyAuthRoles(dpl); err != nil {
+		if err := updateInvalidProxyAuthRolesCondition(dpl, v1.ConditionTrue, err.Error(), er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set proxy auth roles status")
+		}
+		return kverrors.Wrap(err, "invalid proxy auth roles")
+	} else {
+		if err := updateInvalidProxyAuthRolesCondition(dpl, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set proxy auth roles status")
+		}
+	}
+
+	if err := validateAuditLog(dpl); err != nil {
+		if err := updateInvalidAuditLogCondition(dpl, v1.ConditionTrue, err.Error(), er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set audit log status")
+		}
+		return kverrors.Wrap(err, "invalid audit log")
+	} else {
+		if err := updateInvalidAuditLogCondition(dpl, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set audit log status")
+		}
+	}
+
+	if err := validateStorageDataSources(dpl); err != nil {
+		if err := updateInvalidStorageCondition(dpl, v1.ConditionTrue, err.Error(), er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set storage status")
+		}
+		return kverrors.Wrap(err, "invalid storage")
+	} else {
+		if err := updateInvalidStorageCondition(dpl, v1.ConditionFalse, "", er.client); err != nil {
+			return kverrors.Wrap(err, "failed to set storage status")
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,377 @@
+package k8shandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ViaQ/logerr/kverrors"
+	"github.com/ViaQ/logerr/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
+)
+
+const (
+	// volumeSnapshotSetLabel is the name of the set a volume snapshot belongs to
+	volumeSnapshotSetLabel = "elasticsearch.openshift.io/volume-snapshot-set"
+
+	// volumeSnapshotSetLayout names the sets after the time they were started
+	volumeSnapshotSetLayout = "20060102-150405"
+
+	// volumeSnapshotTimeout is how long shard allocation stays limited to primaries while
+	// waiting for the storage system to take the snapshots of a set
+	volumeSnapshotTimeout = 10 * time.Minute
+)
+
+var volumeSnapshotGVK = schema.GroupVersionKind{
+	Group:   "snapshot.storage.k8s.io",
+	Version: "v1beta1",
+	Kind:    "VolumeSnapshot",
+}
+
+// IsVolumeSnapshotInProgress returns true while a set of volume snapshots is taken. The
+// reconciler uses it to check on the snapshots sooner than its regular period.
+func IsVolumeSnapshotInProgress(cluster *api.Elasticsearch) bool {
+	return currentVolumeSnapshotSet(&cluster.Status) != nil
+}
+
+func currentVolumeSnapshotSet(status *api.ElasticsearchStatus) *api.ElasticsearchVolumeSnapshotSet {
+	if n := len(status.VolumeSnapshots); n > 0 && status.VolumeSnapshots[n-1].Phase == api.VolumeSnapshotSetInProgress {
+		return &status.VolumeSnapshots[n-1]
+	}
+	return nil
+}
+
+// isVolumeSnapshotSetTaken returns true once the storage system took every snapshot of the
+// set, the snapshots may still be uploaded before they are ready to use
+func isVolumeSnapshotSetTaken(set *api.ElasticsearchVolumeSnapshotSet) bool {
+	for _, snapshot := range set.Snapshots {
+		if snapshot.CreationTime == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// takingVolumeSnapshots returns true while shard allocation is limited to primaries for the
+// snapshots of the set in progress
+func (er *ElasticsearchRequest) takingVolumeSnapshots() bool {
+	set := currentVolumeSnapshotSet(&er.cluster.Status)
+	return set != nil && !isVolumeSnapshotSetTaken(set)
+}
+
+func isVolumeSnapshotDue(policy *api.ElasticsearchVolumeSnapshotPolicy, status *api.ElasticsearchStatus, now time.Time) bool {
+	if policy.Interval.Duration <= 0 {
+		return false
+	}
+
+	n := len(status.VolumeSnapshots)
+	return n == 0 || !now.Before(status.VolumeSnapshots[n-1].Started.Add(policy.Interval.Duration))
+}
+
+// validateStorageDataSources ensures only node groups of a single node are provisioned from a
+// data source, every claim of a group would be restored from the same snapshot otherwise
+func validateStorageDataSources(dpl *api.Elasticsearch) error {
+	for _, node := range dpl.Spec.Nodes {
+		if node.Storage.DataSource != nil && node.NodeCount > 1 {
+			return kverrors.New("storage data source requires a node count of one",
+				"data_source", node.Storage.DataSource.Name,
+				"node_count", node.NodeCount)
+		}
+	}
+	return nil
+}
+
+// dataClaimNames returns the claims of the data nodes of the spec, nodes on ephemeral
+// storage have none
+func (er *ElasticsearchRequest) dataClaimNames() []string {
+	cluster := er.cluster
+
+	names := []string{}
+	for _, node := range cluster.Spec.Nodes {
+		if !isDataNode(node) || node.GenUUID == nil || node.Storage.Size == nil {
+			continue
+		}
+		for _, nodeName := range er.nodeNames(*node.GenUUID, node) {
+			names = append(names, fmt.Sprintf("%s-%s", cluster.Name, nodeName))
+		}
+	}
+	return names
+}
+
+// volumeSnapshotRestart only runs the steps the restarts use to quiesce the cluster
+func (er *ElasticsearchRequest) volumeSnapshotRestart() ClusterRestart {
+	return ClusterRestart{
+		client:           er.esClient,
+		clusterName:      er.cluster.Name,
+		clusterNamespace: er.cluster.Namespace,
+	}
+}
+
+// reconcileVolumeSnapshots progresses the set of volume snapshots in progress, deletes the
+// sets beyond the ones the policy keeps and starts a new set once the interval passed
+func (er *ElasticsearchRequest) reconcileVolumeSnapshots() error {
+	cluster := er.cluster
+	policy := cluster.Spec.VolumeSnapshots
+
+	if !er.capabilities.Has(platform.VolumeSnapshots) {
+		if policy != nil {
+			log.Info("Volume snapshots are not served by the platform, skipping them",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace)
+		}
+		return nil
+	}
+
+	if set := currentVolumeSnapshotSet(&cluster.Status); set != nil {
+		if err := er.progressVolumeSnapshotSet(set); err != nil {
+			return err
+		}
+	}
+
+	if policy == nil {
+		return nil
+	}
+
+	if err := er.pruneVolumeSnapshotSets(policy); err != nil {
+		return err
+	}
+
+	if currentVolumeSnapshotSet(&cluster.Status) != nil || !isVolumeSnapshotDue(policy, &cluster.Status, time.Now()) {
+		return nil
+	}
+
+	// leave the cluster to the restarts, updates and storage migrations
+	if IsRestartInProgress(cluster) || len(er.getScheduledUpgradeNodes()) > 0 || IsStorageMigrationInProgress(cluster) {
+		return nil
+	}
+
+	return er.startVolumeSnapshotSet(policy)
+}
+
+// startVolumeSnapshotSet prepares the cluster like a full cluster restart does and requests
+// a snapshot of every data claim, shard allocation is restored once they were all taken
+func (er *ElasticsearchRequest) startVolumeSnapshotSet(policy *api.ElasticsearchVolumeSnapshotPolicy) error {
+	cluster := er.cluster
+
+	claimNames := er.dataClaimNames()
+	if len(claimNames) == 0 {
+		return nil
+	}
+
+	restart := er.volumeSnapshotRestart()
+	if err := restart.ensureClusterHealthValid(); err != nil {
+		if errors.Is(err, ErrWaitingForCluster) {
+			log.Info("Waiting for the cluster to settle before taking volume snapshots",
+				"cluster", cluster.Name,
+				"namespace", cluster.Namespace)
+			return nil
+		}
+		return err
+	}
+
+	if err := restart.requiredSetPrimariesShardsAndFlush(); err != nil {
+		if allocErr := restart.setAllShards(); allocErr != nil {
+			log.Error(allocErr, "unable to restore shard allocation")
+		}
+		return err
+	}
+
+	started := metav1.Now()
+	set := api.ElasticsearchVolumeSnapshotSet{
+		Name:    started.UTC().Format(volumeSnapshotSetLayout),
+		Phase:   api.VolumeSnapshotSetInProgress,
+		Started: started,
+	}
+
+	for _, claimName := range claimNames {
+		snapshot := newVolumeSnapshot(fmt.Sprintf("%s-%s", claimName, set.Name), claimName, set.Name, cluster, policy.VolumeSnapshotClassName)
+		if err := er.client.Create(context.TODO(), snapshot); err != nil && !apierrors.IsAlreadyExists(kverrors.Root(err)) {
+			set.Phase = api.VolumeSnapshotSetFailed
+			set.Message = fmt.Sprintf("failed to create snapshot of claim %s: %s", claimName, err)
+			break
+		}
+
+		set.Snapshots = append(set.Snapshots, api.ElasticsearchVolumeSnapshot{
+			Name:      snapshot.GetName(),
+			ClaimName: claimName,
+		})
+	}
+
+	log.Info("Started volume snapshots",
+		"cluster", cluster.Name,
+		"namespace", cluster.Namespace,
+		"set", set.Name,
+		"phase", set.Phase)
+
+	cluster.Status.VolumeSnapshots = append(cluster.Status.VolumeSnapshots, set)
+
+	if set.Phase == api.VolumeSnapshotSetFailed {
+		return er.failVolumeSnapshotSet(&cluster.Status.VolumeSnapshots[len(cluster.Status.VolumeSnapshots)-1], set.Message)
+	}
+	return nil
+}
+
+// progressVolumeSnapshotSet records the progress the storage system reports on the snapshots
+// of the set. Shard allocation is restored once every snapshot was taken.
+func (er *ElasticsearchRequest) progressVolumeSnapshotSet(set *api.ElasticsearchVolumeSnapshotSet) error {
+	wasTaken := isVolumeSnapshotSetTaken(set)
+
+	ready := true
+	for i := range set.Snapshots {
+		snapshot := &set.Snapshots[i]
+
+		current, err := er.getVolumeSnapshot(snapshot.Name)
+		if err != nil {
+			if apierrors.IsNotFound(kverrors.Root(err)) {
+				return er.failVolumeSnapshotSet(set, fmt.Sprintf("snapshot %s was deleted", snapshot.Name))
+			}
+			return err
+		}
+
+		if message, found, _ := unstructured.NestedString(current.Object, "status", "error", "message"); found {
+			return er.failVolumeSnapshotSet(set, fmt.Sprintf("snapshot %s failed: %s", snapshot.Name, message))
+		}
+
+		if created, found, _ := unstructured.NestedString(current.Object, "status", "creationTime"); found {
+			if t, err := time.Parse(time.RFC3339, created); err == nil {
+				snapshot.CreationTime = &metav1.Time{Time: t}
+			}
+		}
+
+		snapshot.ReadyToUse, _, _ = unstructured.NestedBool(current.Object, "status", "readyToUse")
+		ready = ready && snapshot.ReadyToUse
+	}
+
+	taken := isVolumeSnapshotSetTaken(set)
+	if !taken && time.Since(set.Started.Time) > volumeSnapshotTimeout {
+		return er.failVolumeSnapshotSet(set, fmt.Sprintf("the snapshots were not taken within %s", volumeSnapshotTimeout))
+	}
+
+	if taken && !wasTaken {
+		if err := er.volumeSnapshotRestart().setAllShards(); err != nil {
+			return err
+		}
+	}
+
+	if ready {
+		set.Phase = api.VolumeSnapshotSetCompleted
+		log.Info("Completed volume snapshots",
+			"cluster", er.cluster.Name,
+			"namespace", er.cluster.Namespace,
+			"set", set.Name)
+	}
+	return nil
+}
+
+// failVolumeSnapshotSet deletes the snapshots of the set, they are not consistent with each
+// other anymore, and restores shard allocation
+func (er *ElasticsearchRequest) failVolumeSnapshotSet(set *api.ElasticsearchVolumeSnapshotSet, message string) error {
+	log.Info("Volume snapshots failed",
+		"cluster", er.cluster.Name,
+		"namespace", er.cluster.Namespace,
+		"set", set.Name,
+		"reason", message)
+
+	set.Phase = api.VolumeSnapshotSetFailed
+	set.Message = message
+
+	if err := er.deleteVolumeSnapshots(set); err != nil {
+		return err
+	}
+	return er.volumeSnapshotRestart().setAllShards()
+}
+
+// pruneVolumeSnapshotSets deletes the completed sets beyond the number the policy keeps and
+// the failed sets followed by a completed one
+func (er *ElasticsearchRequest) pruneVolumeSnapshotSets(policy *api.ElasticsearchVolumeSnapshotPolicy) error {
+	status := &er.cluster.Status
+
+	kept := []api.ElasticsearchVolumeSnapshotSet{}
+	completed := int32(0)
+	for i := len(status.VolumeSnapshots) - 1; i >= 0; i-- {
+		set := status.VolumeSnapshots[i]
+
+		prune := false
+		switch set.Phase {
+		case api.VolumeSnapshotSetCompleted:
+			completed++
+			prune = policy.Keep != nil && completed > *policy.Keep
+		case api.VolumeSnapshotSetFailed:
+			prune = completed > 0
+		}
+
+		if !prune {
+			kept = append([]api.ElasticsearchVolumeSnapshotSet{set}, kept...)
+			continue
+		}
+
+		if err := er.deleteVolumeSnapshots(&set); err != nil {
+			return err
+		}
+	}
+
+	if len(kept) == 0 {
+		kept = nil
+	}
+	status.VolumeSnapshots = kept
+	return nil
+}
+
+func newVolumeSnapshot(name, claimName, setName string, cluster *api.Elasticsearch, className *string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(cluster.Namespace)
+	// the snapshots are backups and outlive the cluster, they aren't owned by it
+	snapshot.SetLabels(map[string]string{
+		"logging-cluster":      cluster.Name,
+		volumeSnapshotSetLabel: setName,
+	})
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": claimName,
+		},
+	}
+	if className != nil {
+		spec["volumeSnapshotClassName"] = *className
+	}
+	snapshot.Object["spec"] = spec
+
+	return snapshot
+}
+
+func (er *ElasticsearchRequest) getVolumeSnapshot(name string) (*unstructured.Unstructured, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+
+	key := types.NamespacedName{Name: name, Namespace: er.cluster.Namespace}
+	if err := er.client.Get(context.TODO(), key, snapshot); err != nil {
+		return nil, kverrors.Wrap(err, "failed to get volume snapshot",
+			"snapshot", name)
+	}
+	return snapshot, nil
+}
+
+func (er *ElasticsearchRequest) deleteVolumeSnapshots(set *api.ElasticsearchVolumeSnapshotSet) error {
+	for _, snapshot := range set.Snapshots {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(volumeSnapshotGVK)
+		obj.SetName(snapshot.Name)
+		obj.SetNamespace(er.cluster.Namespace)
+
+		if err := er.client.Delete(context.TODO(), obj); err != nil && !apierrors.IsNotFound(err) {
+			return kverrors.Wrap(err, "failed to delete volume snapshot",
+				"snapshot", snapshot.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package k8shandler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
+	testhelpers "github.com/openshift/elasticsearch-operator/test/helpers"
+)
+
+func newSnapshotCluster(sets ...loggingv1.ElasticsearchVolumeSnapshotSet) *loggingv1.Elasticsearch {
+	uuid := "abcd1234"
+	keep := int32(1)
+	className := "csi-snapclass"
+	cluster := newTestCluster(loggingv1.ElasticsearchSpec{
+		Nodes: []loggingv1.ElasticsearchNode{
+			{
+				Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleMaster},
+				NodeCount: 1,
+				GenUUID:   &uuid,
+				Storage:   loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity("1Gi")},
+			},
+			{
+				Roles:     []loggingv1.ElasticsearchNodeRole{loggingv1.ElasticsearchRoleData},
+				NodeCount: 2,
+				GenUUID:   &uuid,
+				Storage:   loggingv1.ElasticsearchStorageSpec{Size: resourceQuantity("10Gi")},
+			},
+		},
+		VolumeSnapshots: &loggingv1.ElasticsearchVolumeSnapshotPolicy{
+			Interval:                metav1.Duration{Duration: 24 * time.Hour},
+			VolumeSnapshotClassName: &className,
+			Keep:                    &keep,
+		},
+	})
+	cluster.Status.VolumeSnapshots = sets
+	return cluster
+}
+
+func newFakeVolumeSnapshot(name string, status map[string]interface{}) *unstructured.Unstructured {
+	snapshot := newVolumeSnapshot(name, "claim", "set", newSnapshotCluster(), nil)
+	if status != nil {
+		snapshot.Object["status"] = status
+	}
+	return snapshot
+}
+
+func TestReconcileVolumeSnapshotsStartsSet(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/health":   {{StatusCode: http.StatusOK, Body: `{"status": "green"}`}},
+		"_cluster/settings": {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+		"_flush/synced":     {{StatusCode: http.StatusOK, Body: `{"_shards": {"total": 10, "successful": 10, "failed": 0}}`}},
+	})
+	cluster := newSnapshotCluster()
+	er := newTestRequest(cluster, chatter)
+
+	if err := er.reconcileVolumeSnapshots(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if len(cluster.Status.VolumeSnapshots) != 1 {
+		t.Fatalf("expected a set of snapshots to be started, got %v", cluster.Status.VolumeSnapshots)
+	}
+	set := cluster.Status.VolumeSnapshots[0]
+	if set.Phase != loggingv1.VolumeSnapshotSetInProgress || len(set.Snapshots) != 2 {
+		t.Fatalf("expected the data claims to be snapshotted, got %v", set)
+	}
+	if !er.takingVolumeSnapshots() {
+		t.Errorf("expected shard allocation to be held until the snapshots were taken")
+	}
+
+	request, _ := chatter.GetRequest("_cluster/settings")
+	if !strings.Contains(request.Body, string(loggingv1.ShardAllocationPrimaries)) {
+		t.Errorf("expected shard allocation to be limited to primaries, got %s", request.Body)
+	}
+
+	snapshot, err := er.getVolumeSnapshot(set.Snapshots[1].Name)
+	if err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	claimName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	if claimName != "elasticsearch-elasticsearch-d-abcd1234-2" || className != "csi-snapclass" {
+		t.Errorf("got snapshot of claim %q with class %q", claimName, className)
+	}
+	if snapshot.GetLabels()[volumeSnapshotSetLabel] != set.Name {
+		t.Errorf("expected the snapshot to be labeled with its set, got %v", snapshot.GetLabels())
+	}
+}
+
+func TestReconcileVolumeSnapshotsCompletesSet(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/settings": {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+	})
+	taken := map[string]interface{}{"creationTime": "2020-10-01T10:00:00Z", "readyToUse": true}
+	cluster := newSnapshotCluster(
+		loggingv1.ElasticsearchVolumeSnapshotSet{
+			Name:      "old",
+			Phase:     loggingv1.VolumeSnapshotSetCompleted,
+			Started:   metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+			Snapshots: []loggingv1.ElasticsearchVolumeSnapshot{{Name: "snapshot-old"}},
+		},
+		loggingv1.ElasticsearchVolumeSnapshotSet{
+			Name:      "new",
+			Phase:     loggingv1.VolumeSnapshotSetInProgress,
+			Started:   metav1.Now(),
+			Snapshots: []loggingv1.ElasticsearchVolumeSnapshot{{Name: "snapshot-1"}, {Name: "snapshot-2"}},
+		},
+	)
+	er := newTestRequest(cluster, chatter,
+		newFakeVolumeSnapshot("snapshot-old", taken),
+		newFakeVolumeSnapshot("snapshot-1", taken),
+		newFakeVolumeSnapshot("snapshot-2", taken),
+	)
+
+	if err := er.reconcileVolumeSnapshots(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	if len(cluster.Status.VolumeSnapshots) != 1 {
+		t.Fatalf("expected only the newest set to be kept, got %v", cluster.Status.VolumeSnapshots)
+	}
+	set := cluster.Status.VolumeSnapshots[0]
+	if set.Name != "new" || set.Phase != loggingv1.VolumeSnapshotSetCompleted || set.Snapshots[0].CreationTime == nil {
+		t.Errorf("expected the set to be completed, got %v", set)
+	}
+	if _, err := er.getVolumeSnapshot("snapshot-old"); err == nil {
+		t.Errorf("expected the snapshots of the pruned set to be deleted")
+	}
+
+	request, _ := chatter.GetRequest("_cluster/settings")
+	if !strings.Contains(request.Body, string(loggingv1.ShardAllocationAll)) {
+		t.Errorf("expected shard allocation to be restored, got %s", request.Body)
+	}
+}
+
+func TestReconcileVolumeSnapshotsFailsSet(t *testing.T) {
+	chatter := testhelpers.NewFakeElasticsearchChatter(map[string]testhelpers.FakeElasticsearchResponses{
+		"_cluster/settings": {{StatusCode: http.StatusOK, Body: `{"acknowledged": true}`}},
+	})
+	cluster := newSnapshotCluster(loggingv1.ElasticsearchVolumeSnapshotSet{
+		Name:      "new",
+		Phase:     loggingv1.VolumeSnapshotSetInProgress,
+		Started:   metav1.Now(),
+		Snapshots: []loggingv1.ElasticsearchVolumeSnapshot{{Name: "snapshot-1"}, {Name: "snapshot-2"}},
+	})
+	er := newTestRequest(cluster, chatter,
+		newFakeVolumeSnapshot("snapshot-1", map[string]interface{}{"creationTime": "2020-10-01T10:00:00Z"}),
+		newFakeVolumeSnapshot("snapshot-2", map[string]interface{}{"error": map[string]interface{}{"message": "quota exceeded"}}),
+	)
+
+	if err := er.reconcileVolumeSnapshots(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+
+	set := cluster.Status.VolumeSnapshots[0]
+	if set.Phase != loggingv1.VolumeSnapshotSetFailed || !strings.Contains(set.Message, "quota exceeded") {
+		t.Errorf("expected the set to fail, got %v", set)
+	}
+	if _, err := er.getVolumeSnapshot("snapshot-1"); err == nil {
+		t.Errorf("expected the snapshots of the failed set to be deleted")
+	}
+	if _, found := chatter.GetRequest("_cluster/settings"); !found {
+		t.Errorf("expected shard allocation to be restored")
+	}
+}
+
+func TestReconcileVolumeSnapshotsWithoutSnapshotAPI(t *testing.T) {
+	cluster := newSnapshotCluster()
+	er := newTestRequest(cluster, nil)
+	er.capabilities = platform.Capabilities{}.Without(platform.VolumeSnapshots)
+
+	if err := er.reconcileVolumeSnapshots(); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	if len(cluster.Status.VolumeSnapshots) != 0 {
+		t.Errorf("expected no snapshots without the snapshot API, got %v", cluster.Status.VolumeSnapshots)
+	}
+}
+
+func TestNewPersistentVolumeClaimSpecDataSource(t *testing.T) {
+	apiGroup := "snapshot.storage.k8s.io"
+	source := &v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeSnapshot", Name: "snapshot-1"}
+
+	spec := newPersistentVolumeClaimSpec(loggingv1.ElasticsearchStorageSpec{
+		Size:       resourceQuantity("10Gi"),
+		DataSource: source,
+	})
+	if spec.DataSource != source {
+		t.Errorf("expected the claim to be restored from the snapshot, got %v", spec.DataSource)
+	}
+
+	c := fake.NewFakeClient()
+	if err := createOrUpdatePersistentVolumeClaim(spec, "claim", "openshift-logging", "elasticsearch", c); err != nil {
+		t.Fatalf("got err: %s", err)
+	}
+	claim := &v1.PersistentVolumeClaim{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "claim", Namespace: "openshift-logging"}, claim); err != nil || claim.Spec.DataSource == nil {
+		t.Errorf("expected the claim to be created with the data source, got %v", claim.Spec.DataSource)
+	}
+}
+
+func TestValidateStorageDataSources(t *testing.T) {
+	source := &v1.TypedLocalObjectReference{Kind: "VolumeSnapshot", Name: "snapshot-1"}
+	tests := []struct {
+		desc      string
+		nodeCount int32
+		source    *v1.TypedLocalObjectReference
+		valid     bool
+	}{
+		{desc: "single node restored from a snapshot", nodeCount: 1, source: source, valid: true},
+		{desc: "multiple nodes restored from the same snapshot", nodeCount: 3, source: source},
+		{desc: "multiple nodes without data source", nodeCount: 3, valid: true},
+	}
+
+	for _, test := range tests {
+		dpl := &loggingv1.Elasticsearch{
+			Spec: loggingv1.ElasticsearchSpec{
+				Nodes: []loggingv1.ElasticsearchNode{
+					{NodeCount: test.nodeCount, Storage: loggingv1.ElasticsearchStorageSpec{DataSource: test.source}},
+				},
+			},
+		}
+		if err := validateStorageDataSources(dpl); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got err: %v", test.desc, test.valid, err)
+		}
+	}
+}
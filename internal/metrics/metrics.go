@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -26,9 +28,16 @@ var (
 			Help: "Number of Elasticsearch cluster that are in Managed state or Unmanaged state.",
 		}, []string{"state"})
 
+	certificateExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eo_elasticsearch_cr_certificate_expiry_timestamp_seconds",
+			Help: "Time in seconds since the epoch after which a certificate of the Elasticsearch cluster is no longer valid.",
+		}, []string{"namespace", "cluster", "certificate"})
+
 	metricList = []prometheus.Collector{
 		restartCounter,
 		esClusterManagementState,
+		certificateExpiry,
 	}
 )
 
@@ -80,4 +89,22 @@ func SetEsClusterManagementStateUnmanaged() {
 	esClusterManagementState.With(prometheus.Labels{
 		"state": UnmanagedState,
 	}).Set(1)
-}
\ No newline at end of file
+}
+
+//Sets the metric value to the time after which the certificate stored under the key is no longer valid.
+func SetCertificateExpiry(namespace, cluster, key string, notAfter time.Time) {
+	certificateExpiry.With(prometheus.Labels{
+		"namespace":   namespace,
+		"cluster":     cluster,
+		"certificate": key,
+	}).Set(float64(notAfter.Unix()))
+}
+
+//Removes the metric of the certificate stored under the key.
+func DeleteCertificateExpiry(namespace, cluster, key string) {
+	certificateExpiry.Delete(prometheus.Labels{
+		"namespace":   namespace,
+		"cluster":     cluster,
+		"certificate": key,
+	})
+}
@@ -0,0 +1,124 @@
+package platform
+
+import (
+	"sort"
+
+	"github.com/ViaQ/logerr/kverrors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Integration is an optional API of the platform the operator integrates with
+type Integration string
+
+const (
+	// Routes expose Kibana and Elasticsearch through the OpenShift router
+	Routes Integration = "Routes"
+	// Console adds links and dashboards to the OpenShift console
+	Console Integration = "Console"
+	// ClusterProxy configures Kibana with the cluster-wide proxy of OpenShift
+	ClusterProxy Integration = "ClusterProxy"
+	// Monitoring creates ServiceMonitors and PrometheusRules for the Prometheus operator
+	Monitoring Integration = "Monitoring"
+	// VolumeSnapshots takes snapshots of the claims with the CSI snapshot API
+	VolumeSnapshots Integration = "VolumeSnapshots"
+)
+
+// integrationResources are the resources an integration needs, per group version
+var integrationResources = map[Integration]map[string][]string{
+	Routes: {
+		"route.openshift.io/v1": {"routes"},
+	},
+	Console: {
+		"console.openshift.io/v1": {"consolelinks", "consoleexternalloglinks"},
+	},
+	ClusterProxy: {
+		"config.openshift.io/v1": {"proxies"},
+	},
+	Monitoring: {
+		"monitoring.coreos.com/v1": {"servicemonitors", "prometheusrules"},
+	},
+	VolumeSnapshots: {
+		"snapshot.storage.k8s.io/v1beta1": {"volumesnapshots"},
+	},
+}
+
+// Capabilities are the integrations available on the platform. The zero value has every
+// integration available, as on OpenShift.
+type Capabilities struct {
+	missing map[Integration]bool
+}
+
+// Has returns true if the APIs of the integration are served
+func (c Capabilities) Has(integration Integration) bool {
+	return !c.missing[integration]
+}
+
+// Disabled returns the integrations skipped because their APIs are not served, sorted by name
+func (c Capabilities) Disabled() []string {
+	disabled := []string{}
+	for integration, missing := range c.missing {
+		if missing {
+			disabled = append(disabled, string(integration))
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
+}
+
+// Without returns the capabilities with the integrations removed
+func (c Capabilities) Without(integrations ...Integration) Capabilities {
+	missing := map[Integration]bool{}
+	for integration, m := range c.missing {
+		missing[integration] = m
+	}
+	for _, integration := range integrations {
+		missing[integration] = true
+	}
+	return Capabilities{missing: missing}
+}
+
+// ResourceDiscoverer lists the resources served for a group version, it is implemented by
+// the discovery client
+type ResourceDiscoverer interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// Detect queries the discovery API for the integrations available on the platform
+func Detect(discoverer ResourceDiscoverer) (Capabilities, error) {
+	capabilities := Capabilities{missing: map[Integration]bool{}}
+
+	for integration, groupVersions := range integrationResources {
+		for groupVersion, resources := range groupVersions {
+			served, err := servedResources(discoverer, groupVersion)
+			if err != nil {
+				return capabilities, kverrors.Wrap(err, "failed to discover resources",
+					"groupVersion", groupVersion)
+			}
+			for _, resource := range resources {
+				if !served[resource] {
+					capabilities.missing[integration] = true
+				}
+			}
+		}
+	}
+
+	return capabilities, nil
+}
+
+func servedResources(discoverer ResourceDiscoverer, groupVersion string) (map[string]bool, error) {
+	served := map[string]bool{}
+
+	list, err := discoverer.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return served, nil
+		}
+		return nil, err
+	}
+
+	for _, resource := range list.APIResources {
+		served[resource.Name] = true
+	}
+	return served, nil
+}
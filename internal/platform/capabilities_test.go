@@ -0,0 +1,98 @@
+package platform
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type fakeDiscoverer map[string][]string
+
+func (f fakeDiscoverer) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if groupVersion == "error/v1" {
+		return nil, errors.New("connection refused")
+	}
+
+	resources, ok := f[groupVersion]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: groupVersion}, "")
+	}
+
+	list := &metav1.APIResourceList{GroupVersion: groupVersion}
+	for _, name := range resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: name})
+	}
+	return list, nil
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		desc       string
+		discoverer fakeDiscoverer
+		disabled   []string
+	}{
+		{
+			desc: "openshift",
+			discoverer: fakeDiscoverer{
+				"route.openshift.io/v1":           {"routes", "routes/custom-host"},
+				"console.openshift.io/v1":         {"consolelinks", "consoleexternalloglinks"},
+				"config.openshift.io/v1":          {"proxies"},
+				"monitoring.coreos.com/v1":        {"servicemonitors", "prometheusrules"},
+				"snapshot.storage.k8s.io/v1beta1": {"volumesnapshots", "volumesnapshotcontents"},
+			},
+			disabled: []string{},
+		},
+		{
+			desc: "kubernetes with the prometheus operator",
+			discoverer: fakeDiscoverer{
+				"monitoring.coreos.com/v1": {"servicemonitors", "prometheusrules"},
+			},
+			disabled: []string{"ClusterProxy", "Console", "Routes", "VolumeSnapshots"},
+		},
+		{
+			desc: "partially served",
+			discoverer: fakeDiscoverer{
+				"route.openshift.io/v1":    {"routes"},
+				"console.openshift.io/v1":  {"consolelinks"},
+				"config.openshift.io/v1":   {"proxies"},
+				"monitoring.coreos.com/v1": {"servicemonitors"},
+			},
+			disabled: []string{"Console", "Monitoring", "VolumeSnapshots"},
+		},
+	}
+
+	for _, test := range tests {
+		capabilities, err := Detect(test.discoverer)
+		if err != nil {
+			t.Fatalf("%s: got err: %s", test.desc, err)
+		}
+		if got := capabilities.Disabled(); !reflect.DeepEqual(got, test.disabled) {
+			t.Errorf("%s: got disabled %v, want %v", test.desc, got, test.disabled)
+		}
+	}
+}
+
+func TestDetectFailure(t *testing.T) {
+	integrationResources[Integration("Failing")] = map[string][]string{"error/v1": {"failing"}}
+	defer delete(integrationResources, Integration("Failing"))
+
+	if _, err := Detect(fakeDiscoverer{}); err == nil {
+		t.Errorf("expected discovery errors other than not found to fail")
+	}
+}
+
+func TestCapabilitiesZeroValue(t *testing.T) {
+	var capabilities Capabilities
+	if !capabilities.Has(Routes) || len(capabilities.Disabled()) != 0 {
+		t.Errorf("expected every integration to be available")
+	}
+
+	without := capabilities.Without(Console)
+	if without.Has(Console) || !capabilities.Has(Console) {
+		t.Errorf("expected only the copy to be without the console")
+	}
+}
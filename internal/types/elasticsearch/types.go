@@ -96,10 +96,11 @@ type IndexSettings struct {
 }
 
 type IndexingSettings struct {
-	Format  int32                 `json:"format,omitempty"`
-	Blocks  *IndexBlocksSettings  `json:"blocks,omitempty"`
-	Mapper  *IndexMapperSettings  `json:"mapper,omitempty"`
-	Mapping *IndexMappingSettings `json:"mapping,omitempty"`
+	Format             int32                 `json:"format,omitempty"`
+	AutoExpandReplicas string                `json:"auto_expand_replicas,omitempty"`
+	Blocks             *IndexBlocksSettings  `json:"blocks,omitempty"`
+	Mapper             *IndexMapperSettings  `json:"mapper,omitempty"`
+	Mapping            *IndexMappingSettings `json:"mapping,omitempty"`
 }
 
 type IndexBlocksSettings struct {
@@ -115,9 +116,9 @@ type IndexMappingSettings struct {
 }
 
 type ReIndex struct {
-	Source IndexRef      `json:"source"`
-	Dest   IndexRef      `json:"dest"`
-	Script ReIndexScript `json:"script"`
+	Source IndexRef       `json:"source"`
+	Dest   IndexRef       `json:"dest"`
+	Script *ReIndexScript `json:"script,omitempty"`
 }
 
 type ReIndexScript struct {
@@ -125,26 +126,78 @@ type ReIndexScript struct {
 	Lang   string `json:"lang"`
 }
 
+// IndexRef names an index, the source of a reindex may list several indices and patterns
+// separated by commas
 type IndexRef struct {
 	Index string `json:"index"`
 }
 
+// RemoteReIndex copies an index of a remote cluster, the host must be listed in the
+// reindex.remote.whitelist setting of the cluster running the reindex
+type RemoteReIndex struct {
+	Source RemoteIndexRef `json:"source"`
+	Dest   IndexRef       `json:"dest"`
+}
+
+type RemoteIndexRef struct {
+	Remote RemoteHost `json:"remote"`
+	Index  string     `json:"index"`
+}
+
+type RemoteHost struct {
+	Host string `json:"host"`
+}
+
+// ReIndexTask is a reindex running in the background as returned by the tasks API
+type ReIndexTask struct {
+	Completed bool                   `json:"completed"`
+	Task      ReIndexTaskInfo        `json:"task"`
+	Error     map[string]interface{} `json:"error,omitempty"`
+	Response  *ReIndexTaskResponse   `json:"response,omitempty"`
+}
+
+type ReIndexTaskInfo struct {
+	Status ReIndexTaskStatus `json:"status"`
+}
+
+type ReIndexTaskStatus struct {
+	Total   int64 `json:"total"`
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+type ReIndexTaskResponse struct {
+	Failures []map[string]interface{} `json:"failures,omitempty"`
+}
+
 type AliasActions struct {
 	Actions []AliasAction `json:"actions"`
 }
 
 type AliasAction struct {
 	Add         *AddAliasAction    `json:"add,omitempty"`
+	Remove      *RemoveAliasAction `json:"remove,omitempty"`
 	RemoveIndex *RemoveAliasAction `json:"remove_index,omitempty"`
 }
 
 type AddAliasAction struct {
 	Index string `json:"index"`
 	Alias string `json:"alias"`
+	AliasDefinition
 }
 
+// AliasDefinition is the filter, routing and write index of an alias
+type AliasDefinition struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool                  `json:"is_write_index,omitempty"`
+}
+
+// RemoveAliasAction removes the alias from the index, remove_index deletes the index itself
 type RemoveAliasAction struct {
 	Index string `json:"index"`
+	Alias string `json:"alias,omitempty"`
 }
 
 type CatIndicesResponses []CatIndicesResponse
@@ -162,6 +215,15 @@ type CatIndicesResponse struct {
 	PrimaryStoreSize string `json:"pri.store.size,omitempty"`
 }
 
+type CatShardsResponses []CatShardsResponse
+
+type CatShardsResponse struct {
+	Index string `json:"index,omitempty"`
+	Shard string `json:"shard,omitempty"`
+	State string `json:"state,omitempty"`
+	Node  string `json:"node,omitempty"`
+}
+
 type MasterNodeAndNodeStateResponse struct {
 	ClusterName string                       `json:"cluster_name,omitempty"`
 	MasterNode  string                       `json:"master_node,omitempty"`
@@ -187,3 +249,32 @@ type StatsNode struct {
 	Versions []string       `json:"versions,omitempty"`
 	Count    map[string]int `json:"count,omitempty"`
 }
+
+// SecurityRole is a role of the opendistro security plugin, indices map the
+// index patterns to the document types and their permissions
+type SecurityRole struct {
+	Cluster []string                       `json:"cluster"`
+	Indices map[string]map[string][]string `json:"indices,omitempty"`
+}
+
+// SecurityRoleMapping maps users and backend roles to a role of the opendistro security plugin
+type SecurityRoleMapping struct {
+	Users        []string `json:"users,omitempty"`
+	BackendRoles []string `json:"backendroles,omitempty"`
+}
+
+// RemoteClusterSettings are the persistent settings of a remote cluster, nil fields remove them
+type RemoteClusterSettings struct {
+	Seeds           []string `json:"seeds"`
+	SkipUnavailable *bool    `json:"skip_unavailable"`
+}
+
+// RemoteInfoResponse maps the remote clusters to the connections of the node answering
+type RemoteInfoResponse map[string]RemoteClusterInfo
+
+type RemoteClusterInfo struct {
+	Seeds             []string `json:"seeds,omitempty"`
+	Connected         bool     `json:"connected"`
+	NumNodesConnected int32    `json:"num_nodes_connected"`
+	SkipUnavailable   bool     `json:"skip_unavailable"`
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ParseWatchNamespaces splits the comma separated list of namespaces the operator watches.
+// An empty list means that all namespaces are watched.
+func ParseWatchNamespaces(watchNamespace string) []string {
+	namespaces := []string{}
+	for _, ns := range strings.Split(watchNamespace, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "" || ContainsString(namespaces, ns) {
+			continue
+		}
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces
+}
+
+// NewMultiNamespaceClient creates a client for a manager whose cache only knows the given
+// namespaces. Reads of cluster scoped objects and of objects in other namespaces, e.g. the
+// dashboards in openshift-config-managed, go to the API server directly since the multi
+// namespace cache refuses them.
+func NewMultiNamespaceClient(namespaces []string) func(cache.Cache, *rest.Config, client.Options) (client.Client, error) {
+	return func(c cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+		direct, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &client.DelegatingClient{
+			Reader: &namespacedReader{
+				namespaces: namespaces,
+				cache:      c,
+				direct:     direct,
+				scheme:     options.Scheme,
+				mapper:     options.Mapper,
+			},
+			Writer:       direct,
+			StatusClient: direct,
+		}, nil
+	}
+}
+
+type namespacedReader struct {
+	namespaces []string
+	cache      client.Reader
+	direct     client.Reader
+	scheme     *runtime.Scheme
+	mapper     meta.RESTMapper
+}
+
+func (r *namespacedReader) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if !r.isCached(key.Namespace, obj) {
+		return r.direct.Get(ctx, key, obj)
+	}
+	return r.cache.Get(ctx, key, obj)
+}
+
+func (r *namespacedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+
+	if !r.isCached(listOpts.Namespace, list) {
+		return r.direct.List(ctx, list, opts...)
+	}
+	return r.cache.List(ctx, list, opts...)
+}
+
+// isCached is true for namespaced objects in one of the watched namespaces. Listing namespaced
+// objects across all namespaces is served by the cache as it spans every watched namespace.
+func (r *namespacedReader) isCached(namespace string, obj runtime.Object) bool {
+	// like the default client unstructured objects are never cached
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return false
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return false
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil || mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return false
+	}
+
+	return namespace == corev1.NamespaceAll || Contains(r.namespaces, namespace)
+}
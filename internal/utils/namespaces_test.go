@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		watchNamespace string
+		want           []string
+	}{
+		{watchNamespace: "", want: []string{}},
+		{watchNamespace: "openshift-logging", want: []string{"openshift-logging"}},
+		{watchNamespace: "tenant-a, tenant-b,,tenant-a", want: []string{"tenant-a", "tenant-b"}},
+	}
+
+	for _, test := range tests {
+		if got := ParseWatchNamespaces(test.watchNamespace); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.watchNamespace, got, test.want)
+		}
+	}
+}
+
+func TestNamespacedReaderRoutesReads(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+	cached := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "tenant-a"}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "openshift-config-managed"}}
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "role"}}
+
+	r := &namespacedReader{
+		namespaces: []string{"tenant-a", "tenant-b"},
+		cache:      fake.NewFakeClient(cached),
+		direct:     fake.NewFakeClient(other, role),
+		scheme:     scheme.Scheme,
+		mapper:     mapper,
+	}
+
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "cached", Namespace: "tenant-a"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected configmap in watched namespace to be read from the cache: %s", err)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "other", Namespace: "openshift-config-managed"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected configmap in other namespace to be read directly: %s", err)
+	}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: "role"}, &rbacv1.ClusterRole{}); err != nil {
+		t.Errorf("expected cluster scoped object to be read directly: %s", err)
+	}
+}
@@ -150,7 +150,12 @@ func GetWorkingDirFilePath(toFile string) string {
 }
 
 func WriteToWorkingDirFile(toFile string, value []byte) error {
-	if err := ioutil.WriteFile(GetWorkingDirFilePath(toFile), value, 0o644); err != nil {
+	fp := GetWorkingDirFilePath(toFile)
+	if err := os.MkdirAll(path.Dir(fp), 0o755); err != nil {
+		return kverrors.Wrap(err, "Unable to create working dir")
+	}
+
+	if err := ioutil.WriteFile(fp, value, 0o644); err != nil {
 		return kverrors.Wrap(err, "Unable to write to working dir")
 	}
 
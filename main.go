@@ -6,12 +6,16 @@ import (
 	"github.com/openshift/elasticsearch-operator/internal/metrics"
 	"os"
 	"runtime"
+	"strconv"
 
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	// "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
@@ -23,6 +27,8 @@ import (
 	"github.com/ViaQ/logerr/log"
 	loggingv1 "github.com/openshift/elasticsearch-operator/apis/logging/v1"
 	controllers "github.com/openshift/elasticsearch-operator/controllers/logging"
+	"github.com/openshift/elasticsearch-operator/internal/platform"
+	"github.com/openshift/elasticsearch-operator/internal/utils"
 	"github.com/openshift/elasticsearch-operator/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -70,34 +76,92 @@ func main() {
 	}
 
 	ll := log.WithValues("namespace", namespace)
+	namespaces := utils.ParseWatchNamespaces(namespace)
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	maxConcurrentReconciles, err := getMaxConcurrentReconciles()
+	if err != nil {
+		log.Error(err, "Failed to get max concurrent reconciles")
+		os.Exit(1)
+	}
+
+	options := ctrl.Options{
 		Scheme:             scheme,
-		Namespace:          namespace,
 		MetricsBindAddress: fmt.Sprintf(":%d", metricsPort),
 		Port:               9443,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "d471c3b1.openshift.io",
-	})
+	}
+
+	switch len(namespaces) {
+	case 0:
+		ll.Info("Watching all namespaces")
+	case 1:
+		options.Namespace = namespaces[0]
+	default:
+		ll.Info("Watching multiple namespaces", "namespaces", namespaces)
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		options.NewClient = utils.NewMultiNamespaceClient(namespaces)
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	mgr, err := ctrl.NewManager(cfg, options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	capabilities, err := detectCapabilities(cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to detect the capabilities of the platform")
+		os.Exit(1)
+	}
+	if disabled := capabilities.Disabled(); len(disabled) > 0 {
+		ll.Info("Disabling integrations as their APIs are not served", "integrations", disabled)
+	}
+
 	if err = (&controllers.ElasticsearchReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Elasticsearch"),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("Elasticsearch"),
+		Scheme:                  mgr.GetScheme(),
+		APIReader:               mgr.GetAPIReader(),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		Capabilities:            capabilities,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Elasticsearch")
 		os.Exit(1)
 	}
 	if err = (&controllers.KibanaReconciler{
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("Kibana"),
+		Scheme:       mgr.GetScheme(),
+		APIReader:    mgr.GetAPIReader(),
+		Capabilities: capabilities,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Kibana")
+		os.Exit(1)
+	}
+	if err = (&controllers.ElasticsearchRoleMappingReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Kibana"),
+		Log:    ctrl.Log.WithName("controllers").WithName("ElasticsearchRoleMapping"),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Kibana")
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchRoleMapping")
+		os.Exit(1)
+	}
+	if err = (&controllers.ElasticsearchDataRecoveryReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ElasticsearchDataRecovery"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchDataRecovery")
+		os.Exit(1)
+	}
+	if err = (&controllers.ElasticsearchReindexReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ElasticsearchReindex"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticsearchReindex")
 		os.Exit(1)
 	}
 	if err = (&controllers.SecretReconciler{
@@ -122,7 +186,8 @@ func main() {
 	}
 }
 
-// getWatchNamespace get the namespace name of the scoped operator
+// getWatchNamespace get the namespace name of the scoped operator. It is a comma separated list
+// of namespaces in multi namespace mode and empty to watch all namespaces
 // - https://sdk.operatorframework.io/docs/building-operators/golang/operator-scope/#configuring-namespace-scoped-operators
 func getWatchNamespace() (string, error) {
 	watchNamespaceEnvVar := "WATCH_NAMESPACE"
@@ -132,3 +197,28 @@ func getWatchNamespace() (string, error) {
 	}
 	return ns, nil
 }
+
+// detectCapabilities queries the discovery API for the optional APIs of the platform, e.g. routes
+// and the console are only served by OpenShift
+func detectCapabilities(cfg *rest.Config) (platform.Capabilities, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return platform.Capabilities{}, err
+	}
+	return platform.Detect(dc)
+}
+
+// getMaxConcurrentReconciles returns the number of Elasticsearch clusters reconciled in parallel
+func getMaxConcurrentReconciles() (int, error) {
+	maxConcurrentReconcilesEnvVar := "MAX_CONCURRENT_RECONCILES"
+	val, found := os.LookupEnv(maxConcurrentReconcilesEnvVar)
+	if !found || val == "" {
+		return 1, nil
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive number, got %q", maxConcurrentReconcilesEnvVar, val)
+	}
+	return n, nil
+}